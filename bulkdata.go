@@ -0,0 +1,177 @@
+package dicom
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/suyashkumar/dicom/pkg/dicomio"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	"github.com/suyashkumar/dicom/pkg/vrraw"
+)
+
+// ErrorUnresolvableBulkData indicates that a BulkDataReference could not be
+// resolved, because it has no source to read from and its URI is not a file
+// URI.
+var ErrorUnresolvableBulkData = errors.New("unable to resolve BulkDataReference without a source or file URI")
+
+// BulkDataReference refers to the value of an element in the input instead of
+// holding it in memory, similar to the BulkDataURI in the DICOM JSON Model
+// (see PS3.18 F.2.6). Elements larger than the BulkDataThreshold Option are
+// parsed into a BulkDataReference, which can be resolved back into the
+// value's bytes later on.
+type BulkDataReference struct {
+	// URI identifies the input that the value is in. It is set from the
+	// BulkDataSourceURI Option, and is a file URI when using ParseFile.
+	URI string `json:"uri,omitempty"`
+	// Offset is the byte offset of the value in the input.
+	Offset int64 `json:"offset"`
+	// Length is the length of the value in bytes.
+	Length int64 `json:"length"`
+	// VR is the raw VR of the element.
+	VR string `json:"vr"`
+	// ByteOrder is the byte order of the words in the referenced bytes (for
+	// VRs other than OB and UN), which is that of the transfer syntax of the
+	// input. If nil, it is taken to be little endian.
+	ByteOrder binary.ByteOrder `json:"-"`
+
+	// src provides random access to the input when it is available.
+	src io.ReaderAt
+}
+
+// BulkDataURI returns a URI that identifies the referenced bytes, formed by
+// adding the offset and length to URI as query parameters. It returns an empty
+// string if URI is not set.
+func (b BulkDataReference) BulkDataURI() string {
+	if b.URI == "" {
+		return ""
+	}
+	sep := "?"
+	if strings.Contains(b.URI, "?") {
+		sep = "&"
+	}
+	return fmt.Sprintf("%s%soffset=%d&length=%d", b.URI, sep, b.Offset, b.Length)
+}
+
+// Resolve reads the referenced bytes from the input the reference was parsed
+// from, if it is still available, or else from the file identified by a file
+// URI. The bytes are returned exactly as they are encoded in the input, so
+// any words are in ByteOrder. Write converts them to the byte order of the
+// transfer syntax being written.
+func (b BulkDataReference) Resolve() ([]byte, error) {
+	if b.src != nil {
+		return b.ResolveFrom(b.src)
+	}
+	u, err := url.Parse(b.URI)
+	if err != nil || u.Scheme != "file" {
+		return nil, ErrorUnresolvableBulkData
+	}
+	f, err := os.Open(filepath.FromSlash(u.Path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return b.ResolveFrom(f)
+}
+
+// ResolveFrom reads the referenced bytes from r, which must provide random
+// access to the same data the reference was parsed from.
+func (b BulkDataReference) ResolveFrom(r io.ReaderAt) ([]byte, error) {
+	data := make([]byte, b.Length)
+	if _, err := r.ReadAt(data, b.Offset); err != nil {
+		return nil, fmt.Errorf("unable to read bulk data at offset %d: %w", b.Offset, err)
+	}
+	return data, nil
+}
+
+// bulkDataValue represents an element value that was not loaded into memory.
+type bulkDataValue struct {
+	BulkDataReference
+}
+
+func (b *bulkDataValue) isElementValue()       {}
+func (b *bulkDataValue) ValueType() ValueType  { return BulkData }
+func (b *bulkDataValue) GetValue() interface{} { return b.BulkDataReference }
+func (b *bulkDataValue) String() string {
+	return fmt.Sprintf("BulkData(offset=%d, length=%d)", b.Offset, b.Length)
+}
+func (b *bulkDataValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.BulkDataReference)
+}
+
+// MustGetBulkDataReference attempts to get a BulkDataReference value out of
+// the provided Value, and will panic if it is unable to do so.
+func MustGetBulkDataReference(v Value) BulkDataReference {
+	if v.ValueType() != BulkData {
+		log.Panicf("MustGetBulkDataReference expected ValueType of BulkData, got: %v", v.ValueType())
+	}
+	return v.GetValue().(BulkDataReference)
+}
+
+// BulkDataThreshold returns an Option that avoids loading OB, OW, OD, OF, OL,
+// OV and UN values longer than threshold bytes into memory. These values are
+// instead parsed into a BulkDataReference, which can be resolved later on.
+// PixelData is not affected (see LazyPixelData). A threshold of 0 disables
+// this. Values of a Deflated Explicit VR Little Endian Dataset are always
+// loaded, with a WarningIgnoredOption Warning, as their offsets in the input
+// are not known.
+func BulkDataThreshold(threshold int64) Option {
+	return func(o *Options) {
+		o.BulkDataThreshold = threshold
+	}
+}
+
+// BulkDataSourceURI returns an Option that sets the URI recorded in each
+// BulkDataReference, identifying the input being parsed. ParseFile sets this
+// to a file URI by default.
+func BulkDataSourceURI(uri string) Option {
+	return func(o *Options) {
+		o.BulkDataSourceURI = uri
+	}
+}
+
+// isBulkData reports if the value of an element should be parsed into a
+// BulkDataReference.
+func isBulkData(t tag.Tag, vr string, vl uint32, opts *parseState) bool {
+	if opts.BulkDataThreshold <= 0 || vl == tag.VLUndefinedLength || int64(vl) <= opts.BulkDataThreshold || t == tag.PixelData {
+		return false
+	}
+	switch vr {
+	case vrraw.OtherByte, vrraw.OtherWord, vrraw.OtherDouble, vrraw.OtherFloat,
+		vrraw.OtherLong, vrraw.OtherVeryLong, vrraw.Unknown:
+		return true
+	}
+	return false
+}
+
+// readBulkDataReference skips over a value of length vl, returning a
+// BulkDataReference to it.
+func readBulkDataReference(r dicomio.Reader, vr string, vl uint32, opts *parseState) (Value, error) {
+	ref := BulkDataReference{
+		URI:       opts.BulkDataSourceURI,
+		Offset:    opts.sourceOffset + r.BytesRead(),
+		Length:    int64(vl),
+		VR:        vr,
+		ByteOrder: r.ByteOrder(),
+		src:       opts.source,
+	}
+	if err := r.Skip(int64(vl)); err != nil {
+		return nil, err
+	}
+	return &bulkDataValue{BulkDataReference: ref}, nil
+}
+
+// fileURI returns a file URI for the file at path.
+func fileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
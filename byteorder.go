@@ -0,0 +1,44 @@
+package dicom
+
+import (
+	"encoding/binary"
+
+	"github.com/suyashkumar/dicom/pkg/vrraw"
+)
+
+// otherWordSize returns the size in bytes of the words that make up a value
+// with the given "Other" VR (OB, OW, OF, OL, OV or OD), or 0 if vr is not one
+// of these VRs. OB values are a stream of bytes, so have a word size of 1. OF
+// and OD values are only held as Bytes when they are BulkData.
+func otherWordSize(vr string) int {
+	switch vr {
+	case vrraw.OtherByte:
+		return 1
+	case vrraw.OtherWord:
+		return 2
+	case vrraw.OtherLong, vrraw.OtherFloat:
+		return 4
+	case vrraw.OtherVeryLong, vrraw.OtherDouble:
+		return 8
+	}
+	return 0
+}
+
+// isBigEndian reports if bo is a big endian byte order.
+func isBigEndian(bo binary.ByteOrder) bool {
+	return bo != nil && bo.Uint16([]byte{0x00, 0x01}) == 1
+}
+
+// convertWords converts data, a stream of words of the given size in bytes,
+// from byte order from to byte order to in place.
+func convertWords(data []byte, size int, from, to binary.ByteOrder) {
+	if size < 2 || isBigEndian(from) == isBigEndian(to) {
+		return
+	}
+	for i := 0; i+size <= len(data); i += size {
+		word := data[i : i+size]
+		for j, k := 0, size-1; j < k; j, k = j+1, k-1 {
+			word[j], word[k] = word[k], word[j]
+		}
+	}
+}
@@ -3,18 +3,24 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"image"
 	"image/jpeg"
 	"image/png"
 	"io"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/suyashkumar/dicom"
 	"github.com/suyashkumar/dicom/pkg/frame"
+	"github.com/suyashkumar/dicom/pkg/pixel"
 	"github.com/suyashkumar/dicom/pkg/tag"
+	"github.com/suyashkumar/dicom/pkg/uid"
 )
 
 // GitVersion is the current version of dicomutil, will be replaced in release step with current git commit hash or tag.
@@ -25,6 +31,7 @@ var (
 	filepath            = flag.String("path", "", "path")
 	extractImagesStream = flag.Bool("extract-images-stream", false, "Extract images using frame streaming capability")
 	printJSON           = flag.Bool("json", false, "Print dataset as JSON")
+	window              = flag.String("window", "", "VOI window used for grayscale images: center,width or auto (defaults to the dataset's window)")
 )
 
 // FrameBufferSize represents the size of the *Frame buffered channel for streaming calls
@@ -38,6 +45,13 @@ func main() {
 		os.Exit(0)
 	}
 
+	if flag.Arg(0) == "transcode" {
+		if err := transcode(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if len(*filepath) > 0 {
 
 		f, err := os.Open(*filepath)
@@ -53,6 +67,11 @@ func main() {
 			return
 		}
 
+		renderOpts, err := parseWindow(*window)
+		if err != nil {
+			log.Fatalf("invalid -window: %v", err)
+		}
+
 		var ds *dicom.Dataset
 		if *extractImagesStream {
 			ds = parseWithStreaming(f, info.Size())
@@ -79,14 +98,15 @@ func main() {
 			// In non-streaming frame mode, we need to find all PixelData elements and generate images.
 			for _, elem := range ds.Elements {
 				if elem.Tag == tag.PixelData && !*extractImagesStream {
-					writePixelDataElement(elem, "")
+					writePixelDataElement(ds, elem, "", renderOpts)
 				}
 				// TODO: remove image icon hack after implementing flat iterator
 				if elem.Tag == tag.IconImageSequence {
 					for _, item := range elem.Value.GetValue().([]*dicom.SequenceItemValue) {
-						for _, subElem := range item.GetValue().([]*dicom.Element) {
+						icon := &dicom.Dataset{Elements: item.GetValue().([]*dicom.Element)}
+						for _, subElem := range icon.Elements {
 							if subElem.Tag == tag.PixelData {
-								writePixelDataElement(subElem, "_icon")
+								writePixelDataElement(icon, subElem, "_icon", renderOpts)
 							}
 						}
 					}
@@ -122,20 +142,76 @@ func writeStreamingFrames(frameChan chan *frame.Frame, doneWG *sync.WaitGroup) {
 	for fr := range frameChan {
 		count++
 		wg.Add(1)
-		go generateImage(fr, count, "", &wg)
+		go generateImage(fr, count, "", nil, nil, &wg)
 	}
 	wg.Wait()
 	doneWG.Done()
 }
 
-func generateImage(fr *frame.Frame, frameIndex int, frameSuffix string, wg *sync.WaitGroup) {
-	i, err := fr.GetImage()
+// parseWindow returns the pixel.RenderOptions for the -window flag value s.
+func parseWindow(s string) ([]pixel.RenderOption, error) {
+	switch s {
+	case "":
+		return nil, nil
+	case "auto":
+		return []pixel.RenderOption{pixel.WithAutoWindow()}, nil
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("want center,width or auto, got %q", s)
+	}
+	center, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return nil, err
+	}
+	width, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
 	if err != nil {
-		log.Fatal("Error while getting image")
+		return nil, err
+	}
+	w := pixel.Window{Center: center, Width: width}
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	return []pixel.RenderOption{pixel.WithWindow(w)}, nil
+}
+
+// frameImage returns the image for fr, and whether it holds lossless pixel
+// data. Native grayscale frames (including losslessly compressed frames that
+// can be decoded to native frames) are rendered for display using p (if not
+// nil), and other frames are returned as is.
+func frameImage(fr *frame.Frame, frameIndex int, p *pixel.Pipeline, renderOpts []pixel.RenderOption) (image.Image, bool, error) {
+	if fr.Encapsulated && lossyJPEG(fr.EncapsulatedData.TransferSyntaxUID) {
+		img, err := fr.GetImage()
+		return img, false, err
+	}
+	native, err := fr.GetNativeFrame()
+	if err != nil {
+		img, err := fr.GetImage()
+		return img, false, err
+	}
+	if p != nil {
+		img, err := p.Render(native, frameIndex, renderOpts...)
+		if !errors.Is(err, pixel.ErrorUnsupportedFormat) {
+			return img, true, err
+		}
+	}
+	img, err := native.GetImage()
+	return img, true, err
+}
+
+// lossyJPEG returns whether transferSyntaxUID is a lossy JPEG transfer syntax.
+func lossyJPEG(transferSyntaxUID string) bool {
+	return transferSyntaxUID == uid.JPEGBaseline8Bit || transferSyntaxUID == uid.JPEGExtended12Bit
+}
+
+func generateImage(fr *frame.Frame, frameIndex int, frameSuffix string, p *pixel.Pipeline, renderOpts []pixel.RenderOption, wg *sync.WaitGroup) {
+	i, lossless, err := frameImage(fr, frameIndex, p, renderOpts)
+	if err != nil {
+		log.Fatalf("Error while getting image: %v", err)
 	}
 
 	ext := ".jpg"
-	if !fr.IsEncapsulated() {
+	if lossless {
 		ext = ".png"
 	}
 
@@ -146,9 +222,8 @@ func generateImage(fr *frame.Frame, frameIndex int, frameSuffix string, wg *sync
 		return
 	}
 
-	if !fr.IsEncapsulated() {
-		// Native (non-encapsulated) frames are written as PNGs to exactly
-		// preserve the pixel values.
+	if lossless {
+		// Native (and losslessly decoded) frames are written as lossless PNGs.
 		err := png.Encode(f, i)
 		if err != nil {
 			log.Println(err)
@@ -172,9 +247,14 @@ func generateImage(fr *frame.Frame, frameIndex int, frameSuffix string, wg *sync
 	}
 }
 
-func writePixelDataElement(e *dicom.Element, suffix string) {
+func writePixelDataElement(ds *dicom.Dataset, e *dicom.Element, suffix string, renderOpts []pixel.RenderOption) {
+	p, err := pixel.NewPipeline(ds)
+	if err != nil {
+		log.Printf("Writing raw pixel values, unable to render images: %v\n", err)
+		p = nil
+	}
 	imageInfo := e.Value.GetValue().(dicom.PixelDataInfo)
 	for idx, f := range imageInfo.Frames {
-		generateImage(&f, idx, suffix, nil)
+		generateImage(&f, idx, suffix, p, renderOpts, nil)
 	}
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+// transferSyntaxNames are the short names accepted by the transcode command in
+// place of transfer syntax UIDs.
+var transferSyntaxNames = map[string]string{
+	"implicit":    uid.ImplicitVRLittleEndian,
+	"explicit":    uid.ExplicitVRLittleEndian,
+	"bigendian":   uid.ExplicitVRBigEndian,
+	"deflated":    uid.DeflatedExplicitVRLittleEndian,
+	"rle":         uid.RLELossless,
+	"jpegls":      uid.JPEGLSLossless,
+	"jpegls-near": uid.JPEGLSNearLossless,
+}
+
+// transcode runs the transcode command with the command line arguments args,
+// writing the DICOM at -path converted to the transfer syntax -ts to -out.
+func transcode(args []string) error {
+	fs := flag.NewFlagSet("transcode", flag.ExitOnError)
+	in := fs.String("path", "", "path of the DICOM to transcode")
+	out := fs.String("out", "", "path to write the transcoded DICOM to")
+	ts := fs.String("ts", "explicit", "transfer syntax UID to transcode to, or one of: implicit, explicit, bigendian, deflated, rle, jpegls, jpegls-near")
+	lossy := fs.Bool("lossy", false, "allow lossy compression of pixel data")
+	near := fs.Int("near", 2, "maximum sample error of jpegls-near compression, with -lossy")
+	fs.Parse(args)
+	if *in == "" || *out == "" {
+		return fmt.Errorf("both -path and -out are required")
+	}
+	transferSyntaxUID := *ts
+	if u, ok := transferSyntaxNames[transferSyntaxUID]; ok {
+		transferSyntaxUID = u
+	}
+
+	ds, err := dicom.ParseFile(*in)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", *in, err)
+	}
+	var opts []dicom.TranscodeOption
+	if *lossy {
+		opts = append(opts, dicom.AllowLossy(), dicom.NearLossless(*near))
+	}
+	transcoded, err := dicom.Transcode(ds, transferSyntaxUID, opts...)
+	if err != nil {
+		return fmt.Errorf("error transcoding %s: %w", *in, err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	// Keep the VRs of the parsed elements, even if they differ from the
+	// dictionary.
+	if err := dicom.Write(w, transcoded, dicom.SkipVRVerification()); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing %s: %w", *out, err)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/suyashkumar/dicom/pkg/codec"
 	"github.com/suyashkumar/dicom/pkg/tag"
 	"github.com/suyashkumar/dicom/pkg/uid"
 )
@@ -46,7 +47,35 @@ func (d *Dataset) transferSyntax() (binary.ByteOrder, bool, error) {
 		return nil, false, fmt.Errorf("failed to retrieve TransferSyntaxUID. Unable to cast elem.Value to []string")
 	}
 	transferSyntaxUID := value[0]
-	return uid.ParseTransferSyntaxUID(transferSyntaxUID)
+	return parseTransferSyntaxUID(transferSyntaxUID)
+}
+
+// parseTransferSyntaxUID is like uid.ParseTransferSyntaxUID, but also accepts
+// transfer syntaxes that are not in the uid dictionary (e.g. newer ones) if a
+// codec.Codec is registered for them. Such transfer syntaxes are encapsulated,
+// and so explicit VR little endian.
+func parseTransferSyntaxUID(transferSyntaxUID string) (binary.ByteOrder, bool, error) {
+	bo, implicit, err := uid.ParseTransferSyntaxUID(transferSyntaxUID)
+	if err != nil {
+		if _, ok := codec.Lookup(transferSyntaxUID); ok {
+			return binary.LittleEndian, false, nil
+		}
+	}
+	return bo, implicit, err
+}
+
+// transferSyntaxUID returns the TransferSyntaxUID of this Dataset, or an empty
+// string if it is not present.
+func (d *Dataset) transferSyntaxUID() string {
+	elem, err := d.FindElementByTag(tag.TransferSyntaxUID)
+	if err != nil {
+		return ""
+	}
+	value, ok := elem.Value.GetValue().([]string)
+	if !ok || len(value) != 1 {
+		return ""
+	}
+	return value[0]
 }
 
 // FindElementByTagNested searches through the dataset and returns a pointer to the matching element.
@@ -173,7 +202,7 @@ func (d *Dataset) String() string {
 	for elem := range d.flatIteratorWithLevel() {
 		tabs := buildTabs(elem.l)
 		var tagName string
-		if tagInfo, err := tag.Find(elem.e.Tag); err == nil {
+		if tagInfo, err := findTagInfo(&Dataset{Elements: elem.siblings}, elem.e.Tag); err == nil {
 			tagName = tagInfo.Name
 		}
 
@@ -193,6 +222,9 @@ type elementWithLevel struct {
 	e *Element
 	// l represents the nesting level of the Element
 	l uint
+	// siblings are the Elements at the same level as e (including e), which
+	// hold the private creators for any private Elements.
+	siblings []*Element
 }
 
 func (d *Dataset) flatIteratorWithLevel() <-chan *elementWithLevel {
@@ -207,13 +239,13 @@ func (d *Dataset) flatIteratorWithLevel() <-chan *elementWithLevel {
 func flatElementsIteratorWithLevel(elems []*Element, level uint, eWithLevelChan chan<- *elementWithLevel) {
 	for _, elem := range elems {
 		if elem.Value.ValueType() == Sequences {
-			eWithLevelChan <- &elementWithLevel{elem, level}
+			eWithLevelChan <- &elementWithLevel{elem, level, elems}
 			for _, seqItem := range elem.Value.GetValue().([]*SequenceItemValue) {
 				flatElementsIteratorWithLevel(seqItem.elements, level+1, eWithLevelChan)
 			}
 			continue
 		}
-		eWithLevelChan <- &elementWithLevel{elem, level}
+		eWithLevelChan <- &elementWithLevel{elem, level, elems}
 	}
 }
 
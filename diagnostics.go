@@ -0,0 +1,131 @@
+package dicom
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+// WarningCategory classifies the kind of problem a Warning describes.
+type WarningCategory int
+
+const (
+	// WarningMissingTransferSyntax indicates that the metadata did not include
+	// a TransferSyntaxUID, so the Dataset was parsed as implicit VR little
+	// endian.
+	WarningMissingTransferSyntax WarningCategory = iota
+	// WarningUnknownTransferSyntax indicates that the TransferSyntaxUID in the
+	// metadata could not be parsed.
+	WarningUnknownTransferSyntax
+	// WarningMalformedElement indicates that a malformed element was skipped
+	// while parsing in Lenient mode.
+	WarningMalformedElement
+	// WarningUnexpectedTag indicates that an unexpected tag was found, for
+	// example a non-Item element inside encapsulated PixelData.
+	WarningUnexpectedTag
+	// WarningInvalidDelimiter indicates that an Item or delimitation item had
+	// an unexpected value length.
+	WarningInvalidDelimiter
+	// WarningInvalidOffsetTable indicates that the Basic or Extended Offset
+	// Table of encapsulated PixelData was ignored because it did not match
+	// the fragments in the PixelData.
+	WarningInvalidOffsetTable
+	// WarningInvalidPaletteLUT indicates that the palette color lookup table
+	// of PALETTE COLOR PixelData was ignored because it was invalid.
+	WarningInvalidPaletteLUT
+	// WarningIgnoredOption indicates that an Option could not be applied to
+	// the input, for example LazyPixelData for a deflated Dataset.
+	WarningIgnoredOption
+)
+
+func (c WarningCategory) String() string {
+	switch c {
+	case WarningMissingTransferSyntax:
+		return "MissingTransferSyntax"
+	case WarningUnknownTransferSyntax:
+		return "UnknownTransferSyntax"
+	case WarningMalformedElement:
+		return "MalformedElement"
+	case WarningUnexpectedTag:
+		return "UnexpectedTag"
+	case WarningInvalidDelimiter:
+		return "InvalidDelimiter"
+	case WarningInvalidOffsetTable:
+		return "InvalidOffsetTable"
+	case WarningInvalidPaletteLUT:
+		return "InvalidPaletteLUT"
+	case WarningIgnoredOption:
+		return "IgnoredOption"
+	default:
+		return fmt.Sprintf("WarningCategory(%d)", int(c))
+	}
+}
+
+// TagPath is the location of an element within a Dataset, described by the
+// tags of the elements leading up to and including it (starting at the top
+// level Dataset, through any enclosing Sequence and Item elements).
+type TagPath []tag.Tag
+
+// String returns the TagPath in the form "(0008,1115) > (fffe,e000) > (0008,1150)".
+func (p TagPath) String() string {
+	parts := make([]string, len(p))
+	for i, t := range p {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, " > ")
+}
+
+// Warning describes a non-fatal problem encountered while parsing a DICOM.
+// Warnings can be retrieved from a Parser using Parser.Warnings, or handled as
+// they happen by using the WarningHandler or WarningLogger Options.
+type Warning struct {
+	// Category classifies the problem.
+	Category WarningCategory
+	// Path is the location of the element being parsed when the problem was
+	// found. It is empty for problems that are not specific to an element.
+	Path TagPath
+	// Offset is the byte offset in the input at which the problem was found.
+	Offset int64
+	// Message is a human readable description of the problem.
+	Message string
+}
+
+func (w Warning) String() string {
+	if len(w.Path) == 0 {
+		return fmt.Sprintf("%s at offset %d: %s", w.Category, w.Offset, w.Message)
+	}
+	return fmt.Sprintf("%s at offset %d in %s: %s", w.Category, w.Offset, w.Path, w.Message)
+}
+
+// WarningHandler returns an Option that sets a function to be called with each
+// Warning as it is found during parsing.
+func WarningHandler(h func(Warning)) Option {
+	return func(o *Options) {
+		o.WarningHandler = h
+	}
+}
+
+// WarningLogger returns an Option that logs each Warning found during parsing
+// to the provided logger.
+func WarningLogger(l *log.Logger) Option {
+	return WarningHandler(func(w Warning) {
+		l.Printf("WARN: %s", w)
+	})
+}
+
+// warn records a Warning in the state of the parse, and passes it to the
+// WarningHandler if one is set.
+func warn(opts *parseState, category WarningCategory, offset int64, format string, args ...interface{}) {
+	w := Warning{
+		Category: category,
+		Path:     append(TagPath(nil), opts.tagPath...),
+		Offset:   offset,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	opts.warnings = append(opts.warnings, w)
+	if opts.WarningHandler != nil {
+		opts.WarningHandler(w)
+	}
+}
@@ -22,6 +22,10 @@ type Element struct {
 	RawValueRepresentation string     `json:"rawVR"`
 	ValueLength            uint32     `json:"valueLength"`
 	Value                  Value      `json:"value"`
+	// ReinterpretedFromUN indicates that the element was encoded with VR UN,
+	// and was parsed using the VR in RawValueRepresentation instead (see the
+	// ReinterpretUN Option).
+	ReinterpretedFromUN bool `json:"reinterpretedFromUN,omitempty"`
 }
 
 func (e *Element) String() string {
@@ -84,7 +88,7 @@ type Value interface {
 // (ErrorUnexpectedDataType).
 //
 // Acceptable types: []int, []string, []byte, []float64, PixelDataInfo,
-// [][]*Element (represents a sequence, which contains several
+// BulkDataReference, [][]*Element (represents a sequence, which contains several
 // items which each contain several elements).
 func NewValue(data interface{}) (Value, error) {
 	switch v := data.(type) {
@@ -98,6 +102,12 @@ func NewValue(data interface{}) (Value, error) {
 		return &pixelDataValue{PixelDataInfo: v}, nil
 	case []float64:
 		return &floatsValue{value: v}, nil
+	case []int64:
+		return &int64sValue{value: v}, nil
+	case []uint64:
+		return &uint64sValue{value: v}, nil
+	case BulkDataReference:
+		return &bulkDataValue{BulkDataReference: v}, nil
 	case [][]*Element:
 		items := data.([][]*Element)
 		sequenceItems := make([]*SequenceItemValue, 0, len(items))
@@ -173,7 +183,9 @@ type ValueType int
 const (
 	// Strings represents an underlying value of []string
 	Strings ValueType = iota
-	// Bytes represents an underlying value of []byte
+	// Bytes represents an underlying value of []byte. The words in OW, OL and
+	// OV values are always held in little endian byte order, regardless of the
+	// transfer syntax.
 	Bytes
 	// Ints represents an underlying value of []int
 	Ints
@@ -185,6 +197,12 @@ const (
 	Sequences
 	// Floats represents an underlying value of []float64
 	Floats
+	// BulkData represents an underlying value of BulkDataReference
+	BulkData
+	// Int64s represents an underlying value of []int64
+	Int64s
+	// Uint64s represents an underlying value of []uint64
+	Uint64s
 )
 
 // Begin definitions of Values:
@@ -249,6 +267,36 @@ func (s *floatsValue) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.value)
 }
 
+// int64sValue represents a value of []int64.
+type int64sValue struct {
+	value []int64
+}
+
+func (s *int64sValue) isElementValue()       {}
+func (s *int64sValue) ValueType() ValueType  { return Int64s }
+func (s *int64sValue) GetValue() interface{} { return s.value }
+func (s *int64sValue) String() string {
+	return fmt.Sprintf("%v", s.value)
+}
+func (s *int64sValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.value)
+}
+
+// uint64sValue represents a value of []uint64.
+type uint64sValue struct {
+	value []uint64
+}
+
+func (s *uint64sValue) isElementValue()       {}
+func (s *uint64sValue) ValueType() ValueType  { return Uint64s }
+func (s *uint64sValue) GetValue() interface{} { return s.value }
+func (s *uint64sValue) String() string {
+	return fmt.Sprintf("%v", s.value)
+}
+func (s *uint64sValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.value)
+}
+
 // SequenceItemValue is a Value that represents a single Sequence Item. Learn
 // more about Sequences at
 // http://dicom.nema.org/medical/dicom/current/output/chtml/part05/sect_7.5.html.
@@ -295,10 +343,56 @@ func (s *sequencesValue) MarshalJSON() ([]byte, error) {
 }
 
 // PixelDataInfo is a representation of DICOM PixelData.
+//
+// When PixelData is parsed with the LazyPixelData option, Frames is empty and
+// the frames are instead read from the input on demand using Frame.
 type PixelDataInfo struct {
 	Frames         []frame.Frame
 	IsEncapsulated bool `json:"isEncapsulated"`
 	Offsets        []uint32
+
+	// lazy locates the frames in the input when PixelData was parsed with the
+	// LazyPixelData option.
+	lazy *lazyFrames
+}
+
+// NumFrames returns the number of frames in the PixelData, including frames
+// that have not been read from the input yet.
+func (p PixelDataInfo) NumFrames() int {
+	if p.lazy != nil {
+		return p.lazy.numFrames()
+	}
+	return len(p.Frames)
+}
+
+// Frame returns the frame at index i. If the PixelData was parsed with the
+// LazyPixelData option, the frame is read from the input every time Frame is
+// called.
+func (p PixelDataInfo) Frame(i int) (*frame.Frame, error) {
+	if i < 0 || i >= p.NumFrames() {
+		return nil, fmt.Errorf("%w: %d, PixelData has %d frames", ErrorFrameIndexOutOfRange, i, p.NumFrames())
+	}
+	if p.lazy != nil {
+		return p.lazy.readFrame(i)
+	}
+	return &p.Frames[i], nil
+}
+
+// allFrames returns all frames in the PixelData, reading them from the input
+// if needed.
+func (p PixelDataInfo) allFrames() ([]frame.Frame, error) {
+	if p.lazy == nil {
+		return p.Frames, nil
+	}
+	frames := make([]frame.Frame, p.NumFrames())
+	for i := range frames {
+		f, err := p.lazy.readFrame(i)
+		if err != nil {
+			return nil, err
+		}
+		frames[i] = *f
+	}
+	return frames, nil
 }
 
 // pixelDataValue represents DICOM PixelData
@@ -336,6 +430,32 @@ func MustGetStrings(v Value) []string {
 	return v.GetValue().([]string)
 }
 
+// firstInt returns the first int in an Ints Value, or an error if v is not an
+// Ints Value or is empty.
+func firstInt(v Value) (int, error) {
+	if v.ValueType() != Ints {
+		return 0, fmt.Errorf("expected ValueType of Ints, got: %v", v.ValueType())
+	}
+	ints := v.GetValue().([]int)
+	if len(ints) == 0 {
+		return 0, errors.New("expected at least one int, got none")
+	}
+	return ints[0], nil
+}
+
+// firstString returns the first string in a Strings Value, or an error if v is
+// not a Strings Value or is empty.
+func firstString(v Value) (string, error) {
+	if v.ValueType() != Strings {
+		return "", fmt.Errorf("expected ValueType of Strings, got: %v", v.ValueType())
+	}
+	strs := v.GetValue().([]string)
+	if len(strs) == 0 {
+		return "", errors.New("expected at least one string, got none")
+	}
+	return strs[0], nil
+}
+
 // MustGetBytes attempts to get a Bytes value out of the provided Value, and
 // will panic if it is unable to do so.
 func MustGetBytes(v Value) []byte {
@@ -354,6 +474,24 @@ func MustGetFloats(v Value) []float64 {
 	return v.GetValue().([]float64)
 }
 
+// MustGetInt64s attempts to get an Int64s value out of the provided Value, and
+// will panic if it is unable to do so.
+func MustGetInt64s(v Value) []int64 {
+	if v.ValueType() != Int64s {
+		log.Panicf("MustGetInt64s expected ValueType of Int64s, got: %v", v.ValueType())
+	}
+	return v.GetValue().([]int64)
+}
+
+// MustGetUint64s attempts to get a Uint64s value out of the provided Value,
+// and will panic if it is unable to do so.
+func MustGetUint64s(v Value) []uint64 {
+	if v.ValueType() != Uint64s {
+		log.Panicf("MustGetUint64s expected ValueType of Uint64s, got: %v", v.ValueType())
+	}
+	return v.GetValue().([]uint64)
+}
+
 // MustGetPixelDataInfo attempts to get a PixelDataInfo value out of the
 // provided Value, and will panic if it is unable to do so.
 func MustGetPixelDataInfo(v Value) PixelDataInfo {
@@ -368,9 +506,14 @@ func MustGetPixelDataInfo(v Value) PixelDataInfo {
 var allValues = []interface{}{
 	floatsValue{},
 	intsValue{},
+	int64sValue{},
+	uint64sValue{},
 	stringsValue{},
 	pixelDataValue{},
+	PixelDataInfo{},
 	sequencesValue{},
 	bytesValue{},
 	SequenceItemValue{},
+	bulkDataValue{},
+	BulkDataReference{},
 }
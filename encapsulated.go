@@ -0,0 +1,334 @@
+package dicom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/suyashkumar/dicom/pkg/dicomio"
+	"github.com/suyashkumar/dicom/pkg/frame"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+var (
+	// jpegStartMarkers are the markers that begin a JPEG (SOI) or JPEG 2000
+	// (SOC) codestream.
+	jpegStartMarkers = [][]byte{{0xFF, 0xD8}, {0xFF, 0x4F}}
+	// jpegEndMarker is the marker that ends a JPEG (EOI) or JPEG 2000 (EOC)
+	// codestream.
+	jpegEndMarker = []byte{0xFF, 0xD9}
+)
+
+// fragment is a single Item of encapsulated PixelData (after the Basic Offset
+// Table). A frame is made up of one or more fragments.
+type fragment struct {
+	// itemOffset is the offset of the fragment's Item from the start of the
+	// first fragment's Item, which is what offset tables refer to.
+	itemOffset int64
+	// data holds the fragment's value, unless it is being read lazily.
+	data []byte
+	// loc is the location of the fragment's value in the input, if it is
+	// being read lazily.
+	loc byteRange
+	// head and tail hold the first and last few bytes of the fragment's value,
+	// used to detect frame boundaries from JPEG markers.
+	head []byte
+	tail []byte
+}
+
+// startsCodestream reports if the fragment starts with a JPEG or JPEG 2000
+// start marker.
+func (f *fragment) startsCodestream() bool {
+	for _, m := range jpegStartMarkers {
+		if bytes.HasPrefix(f.head, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsCodestream reports if the fragment ends with a JPEG or JPEG 2000 end
+// marker, allowing for a trailing padding byte.
+func (f *fragment) endsCodestream() bool {
+	return bytes.HasSuffix(bytes.TrimSuffix(f.tail, []byte{0}), jpegEndMarker)
+}
+
+// readEncapsulatedPixelData reads undefined length (encapsulated) PixelData.
+// Fragments are grouped into frames using the Extended Offset Table (if
+// present in d) or the Basic Offset Table, and otherwise based on
+// NumberOfFrames or the JPEG markers at the start and end of each fragment.
+// See PS3.5 A.4. In Lenient mode, a fragment that cannot be read is recorded
+// as an ElementError, and the fragments read before it are kept.
+func readEncapsulatedPixelData(r dicomio.Reader, d *Dataset, opts *parseState) (Value, error) {
+	var image PixelDataInfo
+	image.IsEncapsulated = true
+
+	// The first Item in PixelData is the Basic Offset Table.
+	botStart := r.BytesRead()
+	bot, _, err := readRawItem(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(bot)%4 != 0 {
+		warn(opts, WarningInvalidOffsetTable, botStart, "Basic Offset Table length %d is not a multiple of 4, ignoring it", len(bot))
+	} else {
+		for i := 0; i < len(bot); i += 4 {
+			image.Offsets = append(image.Offsets, r.ByteOrder().Uint32(bot[i:]))
+		}
+	}
+
+	lazy := opts.LazyPixelData && opts.source != nil
+	var fragments []fragment
+	var size int64
+	firstItem := r.BytesRead()
+	for !r.IsLimitExhausted() {
+		if err := opts.ctxErr(); err != nil {
+			return nil, err
+		}
+		start := r.BytesRead()
+		f := fragment{itemOffset: start - firstItem}
+		vl, hasValue, endOfItems, err := readRawItemHeader(r, opts)
+		if err == nil && endOfItems {
+			break
+		}
+		if err == nil && !hasValue {
+			continue
+		}
+		if err == nil {
+			size += int64(vl)
+			if opts.MaxElementSize > 0 && size > opts.MaxElementSize {
+				return nil, fmt.Errorf("%w: encapsulated PixelData is over %d bytes", ErrorElementTooLarge, opts.MaxElementSize)
+			}
+			if lazy {
+				err = readFragmentLazily(r, vl, &f, opts)
+			} else if err = checkValueLength(r, vl, opts); err == nil {
+				if err = opts.allocate(int64(vl)); err != nil {
+					return nil, err
+				}
+				f.data, err = dicomio.ReadFull(r, int64(vl))
+				f.head, f.tail = f.data, f.data
+				if len(f.data) > 3 {
+					f.tail = f.data[len(f.data)-3:]
+				}
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("unable to read fragment %d of encapsulated PixelData: %w", len(fragments), err)
+			if !opts.Lenient || isLimitError(err) {
+				return nil, err
+			}
+			// Keep the fragments read so far.
+			recordElementError(&ElementError{Tag: tag.PixelData, Offset: start, Err: err}, opts)
+			break
+		}
+		fragments = append(fragments, f)
+	}
+
+	starts := frameStarts(fragments, d, image.Offsets, botStart, opts)
+	info := encapsulatedFrameInfo(d, opts.transferSyntaxUID)
+	if lazy {
+		l := &lazyFrames{src: opts.source, encapsulated: true, encapsulatedInfo: info}
+		for i, start := range starts {
+			var locs []byteRange
+			for _, f := range fragments[start:frameEnd(starts, i, len(fragments))] {
+				locs = append(locs, f.loc)
+			}
+			l.frames = append(l.frames, locs)
+		}
+		image.lazy = l
+		return &pixelDataValue{PixelDataInfo: image}, nil
+	}
+
+	for i, start := range starts {
+		frameFragments := fragments[start:frameEnd(starts, i, len(fragments))]
+		data := frameFragments[0].data
+		if len(frameFragments) > 1 {
+			data = nil
+			for _, f := range frameFragments {
+				data = append(data, f.data...)
+			}
+		}
+
+		f := frame.Frame{Encapsulated: true, EncapsulatedData: info}
+		f.EncapsulatedData.Data = data
+
+		if err := opts.sendFrame(&f); err != nil {
+			return nil, err
+		}
+
+		image.Frames = append(image.Frames, f)
+	}
+
+	return &pixelDataValue{PixelDataInfo: image}, nil
+}
+
+// encapsulatedFrameInfo returns an EncapsulatedFrame (without Data) describing
+// the frames of encapsulated PixelData, based on the transfer syntax and the
+// already parsed pixel information in d (if any).
+func encapsulatedFrameInfo(d *Dataset, transferSyntaxUID string) frame.EncapsulatedFrame {
+	info := frame.EncapsulatedFrame{TransferSyntaxUID: transferSyntaxUID}
+	if d == nil {
+		return info
+	}
+	for _, f := range []struct {
+		t   tag.Tag
+		dst *int
+	}{
+		{tag.Rows, &info.Rows},
+		{tag.Columns, &info.Cols},
+		{tag.SamplesPerPixel, &info.SamplesPerPixel},
+		{tag.BitsAllocated, &info.BitsAllocated},
+		{tag.BitsStored, &info.BitsStored},
+		{tag.PixelRepresentation, &info.PixelRepresentation},
+	} {
+		if e, err := d.FindElementByTag(f.t); err == nil {
+			*f.dst, _ = firstInt(e.Value)
+		}
+	}
+	if pi, err := d.FindElementByTag(tag.PhotometricInterpretation); err == nil {
+		if s, err := firstString(pi.Value); err == nil {
+			info.PhotometricInterpretation = strings.TrimSpace(s)
+		}
+	}
+	return info
+}
+
+// readFragmentLazily records the location of the fragment value of length vl
+// in the input, and skips over it (keeping only its first and last few bytes).
+func readFragmentLazily(r dicomio.Reader, vl uint32, f *fragment, opts *parseState) error {
+	f.loc = byteRange{offset: opts.sourceOffset + r.BytesRead(), length: int64(vl)}
+	if vl < 5 {
+		f.head = make([]byte, vl)
+		_, err := io.ReadFull(r, f.head)
+		f.tail = f.head
+		return err
+	}
+	f.head = make([]byte, 2)
+	if _, err := io.ReadFull(r, f.head); err != nil {
+		return err
+	}
+	if err := r.Skip(int64(vl) - 5); err != nil {
+		return err
+	}
+	f.tail = make([]byte, 3)
+	_, err := io.ReadFull(r, f.tail)
+	return err
+}
+
+// frameStarts returns the index of the first fragment of each frame.
+func frameStarts(fragments []fragment, d *Dataset, bot []uint32, botStart int64, opts *parseState) []int {
+	if len(fragments) == 0 {
+		return nil
+	}
+
+	if d != nil {
+		if eot, err := d.FindElementByTag(tag.ExtendedOffsetTable); err == nil {
+			offsets, err := parseExtendedOffsetTable(eot.Value)
+			if err == nil {
+				var starts []int
+				if starts, err = fragmentIndices(fragments, offsets); err == nil {
+					return starts
+				}
+			}
+			warn(opts, WarningInvalidOffsetTable, botStart, "ignoring Extended Offset Table: %v", err)
+		}
+	}
+
+	if len(bot) > 0 {
+		offsets := make([]uint64, len(bot))
+		for i, o := range bot {
+			offsets[i] = uint64(o)
+		}
+		starts, err := fragmentIndices(fragments, offsets)
+		if err == nil {
+			return starts
+		}
+		warn(opts, WarningInvalidOffsetTable, botStart, "ignoring Basic Offset Table: %v", err)
+	}
+
+	if d != nil {
+		if nof, err := d.FindElementByTag(tag.NumberOfFrames); err == nil {
+			if nofStr, err := firstString(nof.Value); err == nil {
+				if n, err := strconv.Atoi(nofStr); err == nil && n == 1 {
+					return []int{0}
+				}
+			}
+		}
+	}
+
+	// Without an offset table, fall back to the JPEG markers to find the
+	// fragments that start a new frame. If the fragments are not JPEG
+	// codestreams, assume each fragment is its own frame.
+	starts := []int{0}
+	for i := 1; i < len(fragments); i++ {
+		if !fragments[0].startsCodestream() ||
+			(fragments[i-1].endsCodestream() && fragments[i].startsCodestream()) {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// fragmentIndices returns the index of the fragment that each offset (from an
+// offset table) points to.
+func fragmentIndices(fragments []fragment, offsets []uint64) ([]int, error) {
+	starts := make([]int, 0, len(offsets))
+	i := 0
+	for _, o := range offsets {
+		for i < len(fragments) && uint64(fragments[i].itemOffset) < o {
+			i++
+		}
+		if i == len(fragments) || uint64(fragments[i].itemOffset) != o {
+			return nil, fmt.Errorf("offset %d does not point to the start of a fragment", o)
+		}
+		if len(starts) > 0 && starts[len(starts)-1] == i {
+			return nil, fmt.Errorf("offset %d is repeated", o)
+		}
+		starts = append(starts, i)
+	}
+	if starts[0] != 0 {
+		return nil, fmt.Errorf("first offset is %d, want 0", offsets[0])
+	}
+	return starts, nil
+}
+
+// frameEnd returns the index after the last fragment of frame i.
+func frameEnd(starts []int, i int, numFragments int) int {
+	if i+1 < len(starts) {
+		return starts[i+1]
+	}
+	return numFragments
+}
+
+// parseExtendedOffsetTable returns the 64-bit offsets in the value of an
+// ExtendedOffsetTable element. OV values are held in little endian byte order.
+func parseExtendedOffsetTable(v Value) ([]uint64, error) {
+	if v.ValueType() != Bytes {
+		return nil, fmt.Errorf("unexpected ValueType %v", v.ValueType())
+	}
+	data := MustGetBytes(v)
+	if len(data) == 0 || len(data)%8 != 0 {
+		return nil, fmt.Errorf("invalid length %d", len(data))
+	}
+	offsets := make([]uint64, len(data)/8)
+	for i := range offsets {
+		offsets[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return offsets, nil
+}
+
+// basicOffsetTable returns the Basic Offset Table for encapsulated frames that
+// are each written as a single fragment.
+func basicOffsetTable(frames []frame.Frame) []uint32 {
+	offsets := make([]uint32, len(frames))
+	var offset uint32
+	for i, f := range frames {
+		offsets[i] = offset
+		// Each fragment has an 8 byte Item header.
+		offset += 8 + uint32(len(f.EncapsulatedData.Data))
+	}
+	return offsets
+}
@@ -0,0 +1,50 @@
+//go:build go1.18
+// +build go1.18
+
+package dicom_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/suyashkumar/dicom"
+)
+
+// FuzzParse checks that Parse never panics or allocates without bound, no
+// matter the input. The seed corpus is made up of the start of each DICOM in
+// testdata/ (plus any inputs in testdata/fuzz/FuzzParse).
+func FuzzParse(f *testing.F) {
+	files, err := filepath.Glob("./testdata/*.dcm")
+	if err != nil {
+		f.Fatalf("unable to list testdata/: %v", err)
+	}
+	for _, path := range files {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			f.Fatalf("unable to read %s: %v", path, err)
+		}
+		// Keep seeds small so that fuzzing mutates the interesting parts.
+		if len(data) > 16384 {
+			data = data[:16384]
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = dicom.Parse(bytes.NewReader(data),
+			dicom.Limit(int64(len(data))),
+			dicom.MaxElementSize(1<<20),
+			dicom.MaxTotalAllocation(64<<20),
+			dicom.MaxSequenceDepth(32),
+			dicom.MaxElements(100000),
+		)
+		_, _ = dicom.Parse(bytes.NewReader(data),
+			dicom.Limit(int64(len(data))),
+			dicom.MaxTotalAllocation(64<<20),
+			dicom.MaxSequenceDepth(32),
+			dicom.Lenient(),
+		)
+	})
+}
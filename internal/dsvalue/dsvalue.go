@@ -0,0 +1,42 @@
+// Package dsvalue reads single values of the elements of a dicom.Dataset, for
+// the packages that interpret Datasets (such as pkg/pixel and pkg/overlay).
+package dsvalue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+// Int returns the value of the integer (US, SS or IS) element with tag t in
+// ds, or def if ds has no such element.
+func Int(ds *dicom.Dataset, t tag.Tag, def int) (int, error) {
+	e, err := ds.FindElementByTag(t)
+	if err != nil {
+		return def, nil
+	}
+	switch e.Value.ValueType() {
+	case dicom.Ints:
+		if v := dicom.MustGetInts(e.Value); len(v) > 0 {
+			return v[0], nil
+		}
+	case dicom.Strings:
+		if v := dicom.MustGetStrings(e.Value); len(v) > 0 {
+			return strconv.Atoi(strings.TrimSpace(v[0]))
+		}
+	}
+	return 0, fmt.Errorf("invalid value for %v: %v", t, e.Value)
+}
+
+// String returns the value of the string element with tag t in ds, or "" if
+// ds has no such element.
+func String(ds *dicom.Dataset, t tag.Tag) string {
+	e, err := ds.FindElementByTag(t)
+	if err != nil || e.Value.ValueType() != dicom.Strings {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(dicom.MustGetStrings(e.Value), "\\"))
+}
@@ -0,0 +1,67 @@
+package dsvalue_test
+
+import (
+	"testing"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/internal/dsvalue"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+func mustNewElement(t *testing.T, tg tag.Tag, data interface{}) *dicom.Element {
+	t.Helper()
+	elem, err := dicom.NewElement(tg, data)
+	if err != nil {
+		t.Fatalf("dicom.NewElement(%v) unexpected error: %v", tg, err)
+	}
+	return elem
+}
+
+func TestInt(t *testing.T) {
+	ds := &dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.Rows, []int{512}),
+		mustNewElement(t, tag.NumberOfFramesInOverlay, []string{" 3 "}),
+		mustNewElement(t, tag.PatientName, []string{"Bob"}),
+	}}
+	cases := []struct {
+		name    string
+		tag     tag.Tag
+		want    int
+		wantErr bool
+	}{
+		{name: "US", tag: tag.Rows, want: 512},
+		{name: "IS", tag: tag.NumberOfFramesInOverlay, want: 3},
+		{name: "missing", tag: tag.Columns, want: 7},
+		{name: "not an integer", tag: tag.PatientName, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := dsvalue.Int(ds, tc.tag, 7)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Int(%v) unexpected error: %v", tc.tag, err)
+			}
+			if !tc.wantErr && got != tc.want {
+				t.Errorf("Int(%v) = %d, want %d", tc.tag, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	ds := &dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.ImageType, []string{"ORIGINAL", "PRIMARY "}),
+		mustNewElement(t, tag.Rows, []int{512}),
+	}}
+	for _, tc := range []struct {
+		tag  tag.Tag
+		want string
+	}{
+		{tag: tag.ImageType, want: `ORIGINAL\PRIMARY`},
+		{tag: tag.Rows, want: ""},
+		{tag: tag.PatientName, want: ""},
+	} {
+		if got := dsvalue.String(ds, tc.tag); got != tc.want {
+			t.Errorf("String(%v) = %q, want %q", tc.tag, got, tc.want)
+		}
+	}
+}
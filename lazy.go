@@ -0,0 +1,128 @@
+package dicom
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/suyashkumar/dicom/pkg/frame"
+)
+
+// ErrorFrameIndexOutOfRange indicates that a frame was requested from a
+// PixelDataInfo at an index that does not exist.
+var ErrorFrameIndexOutOfRange = errors.New("frame index out of range")
+
+// byteRange is a contiguous range of bytes in the input.
+type byteRange struct {
+	offset int64
+	length int64
+}
+
+// lazyFrames records the location of each frame of PixelData in the input, so
+// that frames can be read on demand instead of being held in memory.
+type lazyFrames struct {
+	src io.ReaderAt
+	// frames holds the location of each frame in src. A frame is made up of
+	// one or more fragments, which are concatenated when the frame is read.
+	frames [][]byteRange
+	// encapsulated indicates if the frames are encapsulated (compressed) and
+	// described by encapsulatedInfo, or are NativeData frames described by
+	// native.
+	encapsulated     bool
+	encapsulatedInfo frame.EncapsulatedFrame
+	native           nativeFrameInfo
+}
+
+func (l *lazyFrames) numFrames() int {
+	return len(l.frames)
+}
+
+// readFrame reads frame i from the input.
+func (l *lazyFrames) readFrame(i int) (*frame.Frame, error) {
+	var size int64
+	for _, fr := range l.frames[i] {
+		size += fr.length
+	}
+	data := make([]byte, size)
+	pos := int64(0)
+	for _, fr := range l.frames[i] {
+		if _, err := l.src.ReadAt(data[pos:pos+fr.length], fr.offset); err != nil {
+			return nil, fmt.Errorf("unable to read frame %d at offset %d: %w", i, fr.offset, err)
+		}
+		pos += fr.length
+	}
+
+	if l.encapsulated {
+		f := &frame.Frame{Encapsulated: true, EncapsulatedData: l.encapsulatedInfo}
+		f.EncapsulatedData.Data = data
+		return f, nil
+	}
+	f, err := decodeNativeFrame(data, l.native)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// randomAccessSource returns an io.ReaderAt over the data that will be read
+// from in (or nil if random access to in is not possible), along with the
+// offset in the io.ReaderAt at which in is currently positioned.
+func randomAccessSource(in io.Reader) (io.ReaderAt, int64) {
+	var offset int64
+	if s, ok := in.(io.Seeker); ok {
+		pos, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, 0
+		}
+		offset = pos
+	}
+	switch v := in.(type) {
+	case io.ReaderAt:
+		return v, offset
+	case io.ReadSeeker:
+		return &readSeekerAt{rs: v}, offset
+	}
+	return nil, offset
+}
+
+// readSeekerAt adapts an io.ReadSeeker into an io.ReaderAt. The position of
+// the io.ReadSeeker is restored after each read, so that it can continue to be
+// read from sequentially by the Parser.
+type readSeekerAt struct {
+	mu sync.Mutex
+	rs io.ReadSeeker
+}
+
+func (r *readSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pos, err := r.rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.rs.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(r.rs, p)
+	if _, serr := r.rs.Seek(pos, io.SeekStart); serr != nil && err == nil {
+		err = serr
+	}
+	return n, err
+}
+
+// fileReaderAt is an io.ReaderAt that opens the file at the given path for
+// each read, so that frames can be read lazily after ParseFile has returned
+// and closed the file.
+type fileReaderAt string
+
+func (f fileReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return file.ReadAt(p, off)
+}
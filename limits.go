@@ -0,0 +1,167 @@
+package dicom
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/suyashkumar/dicom/pkg/dicomio"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+var (
+	// ErrorElementTooLarge indicates that the value of an element is larger
+	// than allowed by the MaxElementSize Option.
+	ErrorElementTooLarge = errors.New("element value is larger than the maximum element size")
+	// ErrorAllocationLimitExceeded indicates that parsing would allocate more
+	// memory than allowed by the MaxTotalAllocation Option.
+	ErrorAllocationLimitExceeded = errors.New("parsing exceeds the maximum total allocation")
+	// ErrorSequenceTooDeep indicates that Sequences are nested deeper than
+	// allowed by the MaxSequenceDepth Option.
+	ErrorSequenceTooDeep = errors.New("sequences are nested deeper than the maximum sequence depth")
+	// ErrorTooManyElements indicates that the DICOM has more elements than
+	// allowed by the MaxElements Option.
+	ErrorTooManyElements = errors.New("more elements than the maximum number of elements")
+)
+
+// MaxElementSize returns an Option that limits the size in bytes of the value
+// of any single element. Parsing an element with a larger value fails with
+// ErrorElementTooLarge. A limit of 0 means no limit.
+func MaxElementSize(n int64) Option {
+	return func(o *Options) {
+		o.MaxElementSize = n
+	}
+}
+
+// MaxTotalAllocation returns an Option that limits the total number of bytes
+// allocated to hold parsed element values (approximately). Parsing fails with
+// ErrorAllocationLimitExceeded once the limit is reached. A limit of 0 means no
+// limit.
+func MaxTotalAllocation(n int64) Option {
+	return func(o *Options) {
+		o.MaxTotalAllocation = n
+	}
+}
+
+// MaxSequenceDepth returns an Option that limits how deeply Sequences may be
+// nested. Parsing fails with ErrorSequenceTooDeep if Sequences are nested more
+// than n deep. A limit of 0 means no limit.
+func MaxSequenceDepth(n int) Option {
+	return func(o *Options) {
+		o.MaxSequenceDepth = n
+	}
+}
+
+// MaxElements returns an Option that limits the number of elements parsed,
+// including elements nested in Sequences and the Items and delimiters that make
+// up Sequences. Parsing fails with ErrorTooManyElements once the limit is
+// exceeded. A limit of 0 means no limit.
+func MaxElements(n int) Option {
+	return func(o *Options) {
+		o.MaxElements = n
+	}
+}
+
+// isLimitError reports if err is due to one of the resource limit Options.
+func isLimitError(err error) bool {
+	return errors.Is(err, ErrorElementTooLarge) || errors.Is(err, ErrorAllocationLimitExceeded) ||
+		errors.Is(err, ErrorSequenceTooDeep) || errors.Is(err, ErrorTooManyElements)
+}
+
+// allocate records that n more bytes will be allocated to hold parsed values,
+// and returns an error if that exceeds MaxTotalAllocation.
+func (o *parseState) allocate(n int64) error {
+	o.allocated += n
+	if o.MaxTotalAllocation > 0 && o.allocated > o.MaxTotalAllocation {
+		return fmt.Errorf("%w: %d bytes, maximum is %d", ErrorAllocationLimitExceeded, o.allocated, o.MaxTotalAllocation)
+	}
+	return nil
+}
+
+// countElement records that another element is being parsed, and returns an
+// error if that exceeds MaxElements.
+func (o *parseState) countElement() error {
+	o.numElements++
+	if o.MaxElements > 0 && o.numElements > o.MaxElements {
+		return fmt.Errorf("%w: maximum is %d", ErrorTooManyElements, o.MaxElements)
+	}
+	return nil
+}
+
+// enterSequence records that a Sequence is being parsed, and returns an error
+// if that nests Sequences deeper than MaxSequenceDepth. leaveSequence must be
+// called once the Sequence has been parsed.
+func (o *parseState) enterSequence() error {
+	o.sequenceDepth++
+	if o.MaxSequenceDepth > 0 && o.sequenceDepth > o.MaxSequenceDepth {
+		return fmt.Errorf("%w: maximum is %d", ErrorSequenceTooDeep, o.MaxSequenceDepth)
+	}
+	return nil
+}
+
+func (o *parseState) leaveSequence() {
+	o.sequenceDepth--
+}
+
+// checkValueLength returns an error if a value of length vl is larger than
+// MaxElementSize, or cannot possibly be read from r. This is checked before
+// allocating memory for the value, so that a bogus VL cannot cause large
+// allocations.
+func checkValueLength(r dicomio.Reader, vl uint32, opts *parseState) error {
+	if vl == tag.VLUndefinedLength {
+		return nil
+	}
+	if opts.MaxElementSize > 0 && int64(vl) > opts.MaxElementSize {
+		return fmt.Errorf("%w: value length %d, maximum is %d", ErrorElementTooLarge, vl, opts.MaxElementSize)
+	}
+	if left := r.BytesLeftUntilLimit(); int64(vl) > left {
+		return fmt.Errorf("%w: value length %d is longer than the %d bytes left", io.ErrUnexpectedEOF, vl, left)
+	}
+	return nil
+}
+
+// checkNativeFrames returns an error if nFrames frames laid out as described
+// by info cannot possibly be read from the bytesLeft bytes that are left, so
+// that bogus dimensions cannot cause large allocations.
+func checkNativeFrames(info nativeFrameInfo, nFrames int, bytesLeft int64) error {
+	if info.rows < 0 || info.cols < 0 || info.samplesPerPixel < 0 || info.bitsAllocated < 0 || nFrames < 0 {
+		return fmt.Errorf("invalid native PixelData dimensions: %d frames of %dx%d pixels with %d samples of %d bits",
+			nFrames, info.rows, info.cols, info.samplesPerPixel, info.bitsAllocated)
+	}
+	if nFrames == 0 || bytesLeft > math.MaxInt64/8 {
+		return nil
+	}
+	// Multiply out the bits per frame step by step to avoid overflow, counting
+	// at least one bit per frame as each frame is allocated even if it is
+	// empty.
+	maxBitsPerFrame := bytesLeft * 8 / int64(nFrames)
+	bitsPerFrame := int64(1)
+	for _, n := range []int{info.rows, info.cols, info.encodedSamplesPerPixel(), info.bitsAllocated} {
+		if n == 0 {
+			bitsPerFrame = 1
+			break
+		}
+		if bitsPerFrame > maxBitsPerFrame/int64(n) {
+			bitsPerFrame = maxBitsPerFrame + 1
+			break
+		}
+		bitsPerFrame *= int64(n)
+	}
+	if bitsPerFrame > maxBitsPerFrame {
+		return fmt.Errorf("%w: %d frames of %dx%d pixels with %d samples of %d bits do not fit in the %d bytes left",
+			io.ErrUnexpectedEOF, nFrames, info.rows, info.cols, info.samplesPerPixel, info.bitsAllocated, bytesLeft)
+	}
+	return nil
+}
+
+// preallocation returns the capacity to allocate ahead of reading n values of
+// size bytes each from r. If the length of the data underlying r is unknown (as
+// for deflated input), n may be far more than can actually be read, so at most
+// dicomio.MaxPreallocation bytes are allocated up front.
+func preallocation(r dicomio.Reader, n int64, size int64) int {
+	if !r.HasKnownLength() && n*size > dicomio.MaxPreallocation {
+		return int(dicomio.MaxPreallocation / size)
+	}
+	return int(n)
+}
@@ -124,6 +124,36 @@ func (mr *MockReaderMockRecorder) ReadInt32() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadInt32", reflect.TypeOf((*MockReader)(nil).ReadInt32))
 }
 
+// ReadUInt64 mocks base method
+func (m *MockReader) ReadUInt64() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadUInt64")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadUInt64 indicates an expected call of ReadUInt64
+func (mr *MockReaderMockRecorder) ReadUInt64() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadUInt64", reflect.TypeOf((*MockReader)(nil).ReadUInt64))
+}
+
+// ReadInt64 mocks base method
+func (m *MockReader) ReadInt64() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadInt64")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadInt64 indicates an expected call of ReadInt64
+func (mr *MockReaderMockRecorder) ReadInt64() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadInt64", reflect.TypeOf((*MockReader)(nil).ReadInt64))
+}
+
 // ReadFloat32 mocks base method
 func (m *MockReader) ReadFloat32() (float32, error) {
 	m.ctrl.T.Helper()
@@ -252,6 +282,34 @@ func (mr *MockReaderMockRecorder) BytesLeftUntilLimit() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BytesLeftUntilLimit", reflect.TypeOf((*MockReader)(nil).BytesLeftUntilLimit))
 }
 
+// BytesRead mocks base method
+func (m *MockReader) BytesRead() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BytesRead")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// BytesRead indicates an expected call of BytesRead
+func (mr *MockReaderMockRecorder) BytesRead() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BytesRead", reflect.TypeOf((*MockReader)(nil).BytesRead))
+}
+
+// HasKnownLength mocks base method
+func (m *MockReader) HasKnownLength() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasKnownLength")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasKnownLength indicates an expected call of HasKnownLength
+func (mr *MockReaderMockRecorder) HasKnownLength() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasKnownLength", reflect.TypeOf((*MockReader)(nil).HasKnownLength))
+}
+
 // SetTransferSyntax mocks base method
 func (m *MockReader) SetTransferSyntax(bo binary.ByteOrder, implicit bool) {
 	m.ctrl.T.Helper()
@@ -14,6 +14,17 @@ type Options struct {
 	Limit                 int64
 	IncludeTags           []tag.Tag
 	FrameChannel          chan *frame.Frame
+	Lenient               bool
+	WarningHandler        func(Warning)
+	LazyPixelData         bool
+	BulkDataThreshold     int64
+	BulkDataSourceURI     string
+	MaxElementSize        int64
+	MaxTotalAllocation    int64
+	MaxSequenceDepth      int
+	MaxElements           int
+	ReinterpretUN         bool
+	CompactNativeFrames   bool
 }
 
 type Option func(*Options)
@@ -65,3 +76,53 @@ func IncludeTags(tags ...tag.Tag) Option {
 		o.IncludeTags = tags
 	}
 }
+
+// Lenient returns an Option that makes parsing tolerate malformed elements.
+// Instead of aborting, each malformed element is recorded as an ElementError
+// and skipped, and parsing resumes at the end of the element's value (if its
+// length is known) or at the next plausible element boundary.
+func Lenient() Option {
+	return func(o *Options) {
+		o.Lenient = true
+	}
+}
+
+// LazyPixelData returns an Option that avoids loading PixelData frames into
+// memory. Instead, the location of each frame in the input is recorded and
+// frames are read on demand using PixelDataInfo.Frame. This requires an input
+// that implements io.ReaderAt or io.ReadSeeker (like an *os.File, or any input
+// when using ParseFile), otherwise frames are loaded as usual. Frames of a
+// Deflated Explicit VR Little Endian Dataset are always loaded, with a
+// WarningIgnoredOption Warning. Lazily read frames are not sent to the
+// FrameChannel.
+//
+// An io.ReadSeeker input must not be read from elsewhere while frames are
+// being read from it.
+func LazyPixelData() Option {
+	return func(o *Options) {
+		o.LazyPixelData = true
+	}
+}
+
+// CompactNativeFrames returns an Option that holds the samples of NativeData
+// frames read in their Samples Buffer, leaving their [][]int Data nil. By
+// default, samples are held in Data for compatibility, which takes roughly ten
+// times the memory of Samples. Use NativeFrame.At or NativeFrame.Ints to access
+// the samples of frames read with this Option.
+func CompactNativeFrames() Option {
+	return func(o *Options) {
+		o.CompactNativeFrames = true
+	}
+}
+
+// ReinterpretUN returns an Option that parses the value of elements with VR UN
+// using the VR of their tag in the dictionary (see tag.Find), as happens when
+// converting from an implicit VR transfer syntax without knowing every VR. UN
+// elements with an undefined length are parsed as Sequences. The value of a
+// reinterpreted element is parsed as Implicit VR Little Endian, as described
+// in PS3.5 6.2.2, and the Element's ReinterpretedFromUN field is set.
+func ReinterpretUN() Option {
+	return func(o *Options) {
+		o.ReinterpretUN = true
+	}
+}
@@ -22,14 +22,16 @@ package dicom
 
 import (
 	"bufio"
+	"compress/flate"
+	"context"
 	"encoding/binary"
 	"errors"
 	"io"
-	"log"
 	"os"
 
 	"github.com/suyashkumar/dicom/pkg/charset"
 	"github.com/suyashkumar/dicom/pkg/dicomio"
+	"github.com/suyashkumar/dicom/pkg/frame"
 	"github.com/suyashkumar/dicom/pkg/tag"
 	"github.com/suyashkumar/dicom/pkg/uid"
 )
@@ -53,13 +55,33 @@ var (
 
 // Parse parses the entire DICOM at the input io.Reader into a Dataset of DICOM Elements. Use this if you are
 // looking to parse the DICOM all at once, instead of element-by-element.
+//
+// When parsing with the Lenient option, Parse returns the Dataset parsed so far
+// along with an ElementErrors error if any malformed elements were skipped.
 func Parse(in io.Reader, opts ...Option) (Dataset, error) {
-	p, err := NewParser(in, opts...)
+	return ParseContext(context.Background(), in, opts...)
+}
+
+// ParseContext is like Parse, but stops parsing and returns the context's error
+// (along with the Dataset parsed so far) if ctx is cancelled. Cancellation is
+// checked between elements and between frames of PixelData. The FrameChannel,
+// if provided, is closed when ParseContext returns.
+func ParseContext(ctx context.Context, in io.Reader, opts ...Option) (Dataset, error) {
+	return parse(ctx, in, nil, opts...)
+}
+
+// parse parses the entire DICOM at in, as described by ParseContext. source,
+// if not nil, provides random access to in for lazily read frames and bulk
+// data.
+func parse(ctx context.Context, in io.Reader, source io.ReaderAt, opts ...Option) (Dataset, error) {
+	p, err := newParser(ctx, in, source, opts...)
 	if err != nil {
 		return Dataset{}, err
 	}
+	// Close the frameChannel if needed
+	defer p.closeFrameChannel()
 
-	if p.options.ParseDataset {
+	if p.state.ParseDataset {
 		for !p.reader.IsLimitExhausted() {
 			_, err := p.Next()
 			if err != nil {
@@ -68,9 +90,8 @@ func Parse(in io.Reader, opts ...Option) (Dataset, error) {
 		}
 	}
 
-	// Close the frameChannel if needed
-	if p.options.FrameChannel != nil {
-		close(p.options.FrameChannel)
+	if errs := p.ElementErrors(); len(errs) > 0 {
+		return p.dataset, errs
 	}
 
 	return p.dataset, nil
@@ -79,6 +100,12 @@ func Parse(in io.Reader, opts ...Option) (Dataset, error) {
 // ParseFile parses the entire DICOM at the given filepath. See dicom.Parse as
 // well for a more generic io.Reader based API.
 func ParseFile(filepath string, opts ...Option) (Dataset, error) {
+	return ParseFileContext(context.Background(), filepath, opts...)
+}
+
+// ParseFileContext is like ParseFile, but stops parsing if ctx is cancelled.
+// See ParseContext for details.
+func ParseFileContext(ctx context.Context, filepath string, opts ...Option) (Dataset, error) {
 	f, err := os.Open(filepath)
 	if err != nil {
 		return Dataset{}, err
@@ -92,9 +119,10 @@ func ParseFile(filepath string, opts ...Option) (Dataset, error) {
 
 	// default to file size as limit but retain the ability for the
 	// caller to override
-	opts = append([]Option{Limit(info.Size())}, opts...)
-
-	return Parse(f, opts...)
+	opts = append([]Option{Limit(info.Size()), BulkDataSourceURI(fileURI(filepath))}, opts...)
+	// f is closed when ParseFile returns, so lazily read frames and bulk data
+	// must reopen the file.
+	return parse(ctx, f, fileReaderAt(filepath), opts...)
 }
 
 // Parser is a struct that allows a user to parse Elements from a DICOM element-by-element using Next(), which may be
@@ -104,7 +132,70 @@ type Parser struct {
 	reader   dicomio.Reader
 	dataset  Dataset
 	metadata Dataset
-	options  *Options
+	state    *parseState
+	// frameChannelClosed indicates if the FrameChannel has been closed.
+	frameChannelClosed bool
+}
+
+// parseState holds the Options of a single parse, along with the state tracked
+// while parsing. Each parse works on its own copy of the Options, so that an
+// *Options value can be reused.
+type parseState struct {
+	Options
+
+	// elementErrors holds the malformed elements skipped so far while parsing
+	// in Lenient mode.
+	elementErrors []*ElementError
+	// warnings holds the Warnings found so far while parsing.
+	warnings []Warning
+	// tagPath is the path to the element currently being parsed.
+	tagPath TagPath
+	// source provides random access to the input (if possible), and
+	// sourceOffset is the offset in source at which the input starts.
+	source       io.ReaderAt
+	sourceOffset int64
+	// ctx is the context parsing is done under, if any.
+	ctx context.Context
+	// transferSyntaxUID is the transfer syntax of the dataset being parsed,
+	// recorded in the EncapsulatedFrames read.
+	transferSyntaxUID string
+	// allocated, sequenceDepth and numElements track resource usage so far
+	// while parsing, for the resource limit Options.
+	allocated     int64
+	sequenceDepth int
+	numElements   int
+}
+
+// ctxErr returns the error of the context parsing is done under, which is
+// non-nil once the context is cancelled.
+func (s *parseState) ctxErr() error {
+	if s.ctx == nil {
+		return nil
+	}
+	return s.ctx.Err()
+}
+
+// sendFrame sends f to the FrameChannel, if set. It returns an error if the
+// context parsing is done under is cancelled before f can be sent.
+func (s *parseState) sendFrame(f *frame.Frame) error {
+	if s.FrameChannel == nil {
+		return nil
+	}
+	if s.ctx == nil {
+		s.FrameChannel <- f
+		return nil
+	}
+	// Check for cancellation first, as select picks at random if f could also
+	// be sent.
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case s.FrameChannel <- f:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
 }
 
 // NewParser returns a new Parser that points to the provided io.Reader, with bytesToRead bytes left to read. NewParser
@@ -113,20 +204,37 @@ type Parser struct {
 // frameChannel is an optional channel (can be nil) upon which DICOM image frames will be sent as they are parsed (if
 // provided).
 func NewParser(in io.Reader, opts ...Option) (*Parser, error) {
-	options := NewOptions(opts...)
+	return NewParserContext(context.Background(), in, opts...)
+}
 
-	reader, err := dicomio.NewReader(bufio.NewReader(in), binary.LittleEndian, options.Limit)
-	if err != nil {
-		return nil, err
+// NewParserContext is like NewParser, but the returned Parser stops parsing and
+// returns the context's error from Next once ctx is cancelled. If NewParserContext
+// fails, the FrameChannel (if provided) is closed.
+func NewParserContext(ctx context.Context, in io.Reader, opts ...Option) (*Parser, error) {
+	return newParser(ctx, in, nil, opts...)
+}
+
+// newParser returns a new Parser, as described by NewParserContext. source, if
+// not nil, provides random access to in.
+func newParser(ctx context.Context, in io.Reader, source io.ReaderAt, opts ...Option) (*Parser, error) {
+	state := &parseState{Options: *NewOptions(opts...), ctx: ctx, source: source}
+	if state.source == nil {
+		state.source, state.sourceOffset = randomAccessSource(in)
 	}
 
 	p := Parser{
-		reader:  reader,
-		options: options,
+		state: state,
+	}
+	var err error
+	p.reader, err = dicomio.NewReader(bufio.NewReader(&contextReader{ctx: ctx, r: in}), binary.LittleEndian, state.Limit)
+	if err != nil {
+		p.closeFrameChannel()
+		return nil, err
 	}
 
 	elems, err := p.readHeader()
 	if err != nil {
+		p.closeFrameChannel()
 		return nil, err
 	}
 
@@ -139,51 +247,89 @@ func NewParser(in io.Reader, opts ...Option) (*Parser, error) {
 	var bo binary.ByteOrder = binary.LittleEndian
 	implicit := true
 
+	var tsUID string
 	ts, err := p.dataset.FindElementByTag(tag.TransferSyntaxUID)
+	if err == nil {
+		tsUID, err = firstString(ts.Value)
+	}
 	if err != nil {
-		log.Println("WARN: could not find transfer syntax uid in metadata, proceeding with little endian implicit")
+		warn(p.state, WarningMissingTransferSyntax, p.reader.BytesRead(), "could not find transfer syntax uid in metadata, proceeding with little endian implicit")
 	} else {
-		bo, implicit, err = uid.ParseTransferSyntaxUID(MustGetStrings(ts.Value)[0])
+		bo, implicit, err = parseTransferSyntaxUID(tsUID)
 		if err != nil {
 			// TODO(suyashkumar): should we attempt to parse with LittleEndian
 			// Implicit here?
-			log.Println("WARN: could not parse transfer syntax uid in metadata")
+			warn(p.state, WarningUnknownTransferSyntax, p.reader.BytesRead(), "could not parse transfer syntax uid %q in metadata: %v", tsUID, err)
+			if bo == nil {
+				bo = binary.LittleEndian
+			}
+		}
+		if tsUID == uid.DeflatedExplicitVRLittleEndian {
+			// Everything after the metadata is compressed with raw DEFLATE
+			// (see PS3.5 A.5). The inflated length is not known ahead of time,
+			// so the end of the inflated stream becomes the limit.
+			p.reader, err = dicomio.NewReader(bufio.NewReader(flate.NewReader(p.reader)), bo, dicomio.LimitUnknown)
+			if err != nil {
+				p.closeFrameChannel()
+				return nil, err
+			}
+			// Offsets in the inflated stream do not correspond to offsets in
+			// the input, so frames and bulk data cannot be read lazily.
+			if p.state.LazyPixelData && p.state.source != nil {
+				warn(p.state, WarningIgnoredOption, p.reader.BytesRead(), "LazyPixelData is ignored for deflated input")
+			}
+			if p.state.BulkDataThreshold > 0 {
+				warn(p.state, WarningIgnoredOption, p.reader.BytesRead(), "BulkDataThreshold is ignored for deflated input")
+			}
+			p.state.source = nil
+			p.state.BulkDataThreshold = 0
 		}
 	}
 	p.reader.SetTransferSyntax(bo, implicit)
+	p.state.transferSyntaxUID = tsUID
 
 	return &p, nil
 }
 
 // Next parses and returns the next top-level element from the DICOM this Parser points to.
 func (p *Parser) Next() (*Element, error) {
+	if err := p.state.ctxErr(); err != nil {
+		p.closeFrameChannel()
+		return nil, err
+	}
 	if p.reader.IsLimitExhausted() {
 		// Close the frameChannel if needed
-		if p.options.FrameChannel != nil {
-			close(p.options.FrameChannel)
-		}
+		p.closeFrameChannel()
 		return nil, ErrorEndOfDICOM
 	}
 
-	elem, err := readElement(p.reader, &p.dataset, p.options)
+	start := p.reader.BytesRead()
+	elem, err := readElement(p.reader, &p.dataset, p.state)
 	if err != nil {
-		// TODO: tolerate some kinds of errors and continue parsing
+		if p.state.ctxErr() != nil {
+			p.closeFrameChannel()
+		}
 		return nil, err
 	}
 
 	if elem == nil {
+		// The element was either skipped or, in Lenient mode, malformed.
 		return nil, nil
 	}
 
-	if elem.Tag == tag.SpecificCharacterSet {
+	if elem.Tag == tag.SpecificCharacterSet && elem.Value.ValueType() == Strings {
 		encodingNames := MustGetStrings(elem.Value)
 		cs, err := charset.ParseSpecificCharacterSet(encodingNames)
 		if err != nil {
-			// unable to parse character set, hard error
-			// TODO: add option continue, even if unable to parse
-			return nil, err
+			if !p.state.Lenient {
+				// unable to parse character set, hard error
+				return nil, err
+			}
+			// Continue with the default character set.
+			recordElementError(&ElementError{Tag: elem.Tag, Offset: start, Err: err}, p.state)
+		} else {
+			p.reader.SetCodingSystem(cs)
 		}
-		p.reader.SetCodingSystem(cs)
 	}
 
 	p.dataset.Elements = append(p.dataset.Elements, elem)
@@ -197,6 +343,39 @@ func (p *Parser) Metadata() Dataset {
 	return p.metadata
 }
 
+// Warnings returns the Warnings found so far while parsing.
+func (p *Parser) Warnings() []Warning {
+	return p.state.warnings
+}
+
+// ElementErrors returns the malformed elements that have been skipped so far
+// when parsing with the Lenient option.
+func (p *Parser) ElementErrors() ElementErrors {
+	return ElementErrors(p.state.elementErrors)
+}
+
+// closeFrameChannel closes the FrameChannel, if provided and not already closed.
+func (p *Parser) closeFrameChannel() {
+	if p.state.FrameChannel != nil && !p.frameChannelClosed {
+		close(p.state.FrameChannel)
+		p.frameChannelClosed = true
+	}
+}
+
+// contextReader is an io.Reader that stops reading from r once ctx is
+// cancelled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
 // readHeader reads the DICOM magic header and group two metadata elements.
 func (p *Parser) readHeader() ([]*Element, error) {
 	// Check to see if magic word is at byte offset 128. If not, this is a
@@ -218,16 +397,19 @@ func (p *Parser) readHeader() ([]*Element, error) {
 
 	// Must read metadata as LittleEndian explicit VR
 	// Read the length of the metadata elements: (0002,0000) MetaElementGroupLength
-	maybeMetaLen, err := readElement(p.reader, nil, p.options, true)
+	maybeMetaLen, err := readElement(p.reader, nil, p.state, true)
 	if err != nil {
 		return nil, err
 	}
 
-	if maybeMetaLen.Tag != tag.FileMetaInformationGroupLength || maybeMetaLen.Value.ValueType() != Ints {
+	if maybeMetaLen == nil || maybeMetaLen.Tag != tag.FileMetaInformationGroupLength {
 		return nil, ErrorMetaElementGroupLength
 	}
 
-	metaLen := maybeMetaLen.Value.GetValue().([]int)[0]
+	metaLen, err := firstInt(maybeMetaLen.Value)
+	if err != nil {
+		return nil, ErrorMetaElementGroupLength
+	}
 
 	metaElems := []*Element{maybeMetaLen} // TODO: maybe set capacity to a reasonable initial size
 
@@ -238,11 +420,14 @@ func (p *Parser) readHeader() ([]*Element, error) {
 	}
 	defer p.reader.PopLimit()
 	for !p.reader.IsLimitExhausted() {
-		elem, err := readElement(p.reader, nil, p.options, true)
+		elem, err := readElement(p.reader, nil, p.state, true)
 		if err != nil {
-			// TODO: see if we can skip over malformed elements somehow
 			return nil, err
 		}
+		if elem == nil {
+			// A malformed element was skipped in Lenient mode.
+			continue
+		}
 		// log.Printf("Metadata Element: %s\n", elem)
 		metaElems = append(metaElems, elem)
 	}
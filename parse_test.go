@@ -2,16 +2,27 @@ package dicom_test
 
 import (
 	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image/jpeg"
+	"io"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/suyashkumar/dicom/pkg/tag"
+	"github.com/suyashkumar/dicom/pkg/uid"
 
+	"github.com/suyashkumar/dicom/pkg/codec"
 	"github.com/suyashkumar/dicom/pkg/frame"
 
 	"github.com/suyashkumar/dicom"
@@ -165,3 +176,1252 @@ func Test_includeTags(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestParse_deflated(t *testing.T) {
+	raw, err := ioutil.ReadFile("./testdata/1.dcm")
+	if err != nil {
+		t.Fatalf("unable to read testdata/1.dcm: %v", err)
+	}
+	// The value of (0002,0000) MetaElementGroupLength starts at byte 140.
+	metaEnd := 144 + int(binary.LittleEndian.Uint32(raw[140:144]))
+	deflated := bytes.NewBuffer(deflate(t, raw[metaEnd:]))
+
+	want, err := dicom.Parse(bytes.NewReader(raw), dicom.Limit(int64(len(raw))))
+	if err != nil {
+		t.Fatalf("dicom.Parse(1.dcm) unexpected error: %v", err)
+	}
+	var warnings []dicom.WarningCategory
+	got, err := dicom.Parse(bytes.NewReader(deflated.Bytes()), dicom.Limit(int64(deflated.Len())),
+		dicom.LazyPixelData(), dicom.BulkDataThreshold(16),
+		dicom.WarningHandler(func(w dicom.Warning) { warnings = append(warnings, w.Category) }))
+	if err != nil {
+		t.Fatalf("dicom.Parse(deflated 1.dcm) unexpected error: %v", err)
+	}
+
+	// Frames and bulk data are loaded, as offsets in the inflated stream are
+	// not offsets in the input.
+	if diff := cmp.Diff(datasetJSON(t, want), datasetJSON(t, got)); diff != "" {
+		t.Errorf("dicom.Parse(deflated 1.dcm) unexpected diff from 1.dcm: %s", diff)
+	}
+	wantWarnings := []dicom.WarningCategory{dicom.WarningIgnoredOption, dicom.WarningIgnoredOption}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Errorf("dicom.Parse(deflated 1.dcm) unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+// deflate returns a DICOM with the Deflated Explicit VR Little Endian transfer
+// syntax, holding the explicit VR little endian encoded dataset.
+func deflate(t *testing.T, dataset []byte) []byte {
+	t.Helper()
+	meta := bytes.Buffer{}
+	writeExplicitElement(t, &meta, tag.TransferSyntaxUID, "UI", []byte(uid.DeflatedExplicitVRLittleEndian))
+	deflated := bytes.Buffer{}
+	deflated.Write(make([]byte, 128))
+	deflated.WriteString("DICM")
+	writeExplicitElement(t, &deflated, tag.FileMetaInformationGroupLength, "UL", []byte{byte(meta.Len()), 0, 0, 0})
+	deflated.Write(meta.Bytes())
+	fw, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	if err != nil {
+		t.Fatalf("deflate: unable to create flate.Writer: %v", err)
+	}
+	if _, err := fw.Write(dataset); err != nil {
+		t.Fatalf("deflate: unable to deflate dataset: %v", err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatalf("deflate: unable to deflate dataset: %v", err)
+	}
+	return deflated.Bytes()
+}
+
+// writeExplicitElement writes a little endian explicit VR element with a 16 bit
+// VL to buf.
+func writeExplicitElement(t *testing.T, buf *bytes.Buffer, tg tag.Tag, vr string, value []byte) {
+	t.Helper()
+	if len(value)%2 != 0 {
+		value = append(value, 0)
+	}
+	for _, v := range []interface{}{tg.Group, tg.Element, []byte(vr), uint16(len(value)), value} {
+		if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("writeExplicitElement: unable to write element: %v", err)
+		}
+	}
+}
+
+// datasetJSON returns the JSON representation of all non-metadata elements in
+// ds.
+func datasetJSON(t *testing.T, ds dicom.Dataset) string {
+	t.Helper()
+	var elems []*dicom.Element
+	for _, e := range ds.Elements {
+		if e.Tag.Group != tag.MetadataGroup {
+			elems = append(elems, e)
+		}
+	}
+	j, err := json.Marshal(elems)
+	if err != nil {
+		t.Fatalf("datasetJSON: unable to marshal elements: %v", err)
+	}
+	return string(j)
+}
+
+func TestParse_lenient(t *testing.T) {
+	t.Run("malformed element value", func(t *testing.T) {
+		ds := dicom.Dataset{Elements: []*dicom.Element{
+			mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}),
+			mustNewElement(t, tag.PatientName, []string{"Bob", "Jones"}),
+			mustNewElement(t, tag.Rows, []int{128}),
+			mustNewElement(t, tag.Columns, []int{128}),
+			mustNewElement(t, tag.StudyDescription, []string{"Study"}),
+			mustNewElement(t, tag.SeriesDescription, []string{"Series"}),
+		}}
+		buf := bytes.Buffer{}
+		if err := dicom.Write(&buf, ds); err != nil {
+			t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+		}
+		// Corrupt the VL of Rows, so that its value overlaps with the
+		// following Columns element.
+		data := bytes.Replace(buf.Bytes(), []byte{0x28, 0x00, 0x10, 0x00, 'U', 'S', 0x02, 0x00}, []byte{0x28, 0x00, 0x10, 0x00, 'U', 'S', 0x03, 0x00}, 1)
+
+		if _, err := dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data)))); err == nil {
+			t.Errorf("dicom.Parse(malformed) expected error, got nil")
+		}
+
+		got, err := dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))), dicom.Lenient())
+		var elemErrs dicom.ElementErrors
+		if !errors.As(err, &elemErrs) {
+			t.Fatalf("dicom.Parse(malformed, Lenient()) got error %v, want dicom.ElementErrors", err)
+		}
+		if elemErrs[0].Tag != tag.Rows {
+			t.Errorf("dicom.Parse(malformed, Lenient()) unexpected first malformed element. got: %v, want: %v", elemErrs[0].Tag, tag.Rows)
+		}
+		for _, tg := range []tag.Tag{tag.PatientName, tag.StudyDescription, tag.SeriesDescription} {
+			if _, err := got.FindElementByTag(tg); err != nil {
+				t.Errorf("dicom.Parse(malformed, Lenient()) missing element %v: %v", tg, err)
+			}
+		}
+		if _, err := got.FindElementByTag(tag.Rows); err == nil {
+			t.Errorf("dicom.Parse(malformed, Lenient()) unexpectedly found malformed element %v", tag.Rows)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		raw, err := ioutil.ReadFile("./testdata/1.dcm")
+		if err != nil {
+			t.Fatalf("unable to read testdata/1.dcm: %v", err)
+		}
+		want, err := dicom.Parse(bytes.NewReader(raw), dicom.Limit(int64(len(raw))))
+		if err != nil {
+			t.Fatalf("dicom.Parse(1.dcm) unexpected error: %v", err)
+		}
+
+		data := raw[:len(raw)-100]
+		got, err := dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))), dicom.Lenient())
+		var elemErrs dicom.ElementErrors
+		if !errors.As(err, &elemErrs) {
+			t.Fatalf("dicom.Parse(truncated, Lenient()) got error %v, want dicom.ElementErrors", err)
+		}
+		if len(elemErrs) != 1 || elemErrs[0].Tag != tag.PixelData {
+			t.Errorf("dicom.Parse(truncated, Lenient()) unexpected malformed elements: %v", elemErrs)
+		}
+		if len(got.Elements) != len(want.Elements)-1 {
+			t.Errorf("dicom.Parse(truncated, Lenient()) unexpected number of elements. got: %d, want: %d", len(got.Elements), len(want.Elements)-1)
+		}
+	})
+}
+
+func TestParse_parseError(t *testing.T) {
+	t.Run("truncated pixel data", func(t *testing.T) {
+		raw, err := ioutil.ReadFile("./testdata/1.dcm")
+		if err != nil {
+			t.Fatalf("unable to read testdata/1.dcm: %v", err)
+		}
+		data := raw[:len(raw)-100]
+
+		_, err = dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))))
+		var pe *dicom.ParseError
+		if !errors.As(err, &pe) {
+			t.Fatalf("dicom.Parse(truncated) got error %v, want *dicom.ParseError", err)
+		}
+		if diff := cmp.Diff(dicom.TagPath{tag.PixelData}, pe.Path); diff != "" {
+			t.Errorf("dicom.Parse(truncated) unexpected ParseError.Path, diff: %v", diff)
+		}
+		if pe.VR != "OW" {
+			t.Errorf("dicom.Parse(truncated) unexpected ParseError.VR. got: %v, want: OW", pe.VR)
+		}
+		// PixelData is the last element in the file, and has a 12 byte header.
+		if want := int64(len(raw)) - int64(pe.VL) - 12; pe.ElementOffset != want {
+			t.Errorf("dicom.Parse(truncated) unexpected ParseError.ElementOffset. got: %d, want: %d", pe.ElementOffset, want)
+		}
+		// The VL is longer than the remaining input, which is detected right
+		// after the header is read.
+		if want := pe.ElementOffset + 12; pe.Offset != want {
+			t.Errorf("dicom.Parse(truncated) unexpected ParseError.Offset. got: %d, want: %d", pe.Offset, want)
+		}
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("dicom.Parse(truncated) got error %v, want it to wrap io.ErrUnexpectedEOF", err)
+		}
+	})
+
+	t.Run("truncated in sequence", func(t *testing.T) {
+		ds := dicom.Dataset{Elements: []*dicom.Element{
+			mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}),
+			mustNewElement(t, tag.ReferencedImageSequence, [][]*dicom.Element{
+				{
+					mustNewElement(t, tag.ReferencedSOPClassUID, []string{"1.2.3"}),
+					mustNewElement(t, tag.ReferencedSOPInstanceUID, []string{"1.2.3.4.5.6"}),
+				},
+			}),
+			mustNewElement(t, tag.PatientName, []string{"Bob"}),
+		}}
+		buf := bytes.Buffer{}
+		if err := dicom.Write(&buf, ds, dicom.SkipVRVerification()); err != nil {
+			t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+		}
+		// Truncate the input partway through the ReferencedSOPInstanceUID value.
+		start := bytes.Index(buf.Bytes(), []byte{0x08, 0x00, 0x55, 0x11, 'U', 'I'})
+		if start < 0 {
+			t.Fatalf("unable to find ReferencedSOPInstanceUID in written dataset")
+		}
+		data := buf.Bytes()[:start+8+4]
+
+		_, err := dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))))
+		var pe *dicom.ParseError
+		if !errors.As(err, &pe) {
+			t.Fatalf("dicom.Parse(truncated) got error %v, want *dicom.ParseError", err)
+		}
+		wantPath := dicom.TagPath{tag.ReferencedImageSequence, tag.Item, tag.ReferencedSOPInstanceUID}
+		if diff := cmp.Diff(wantPath, pe.Path); diff != "" {
+			t.Errorf("dicom.Parse(truncated) unexpected ParseError.Path, diff: %v", diff)
+		}
+		if pe.VR != "UI" || pe.VL != 12 {
+			t.Errorf("dicom.Parse(truncated) unexpected ParseError VR, VL. got: %v, %d, want: UI, 12", pe.VR, pe.VL)
+		}
+		if pe.ElementOffset != int64(start) {
+			t.Errorf("dicom.Parse(truncated) unexpected ParseError.ElementOffset. got: %d, want: %d", pe.ElementOffset, start)
+		}
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("dicom.Parse(truncated) got error %v, want it to wrap io.ErrUnexpectedEOF", err)
+		}
+	})
+}
+
+func TestParse_lazyPixelData(t *testing.T) {
+	files, err := ioutil.ReadDir("./testdata")
+	if err != nil {
+		t.Fatalf("unable to read testdata/: %v", err)
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".dcm") {
+			continue
+		}
+		path := "./testdata/" + f.Name()
+		t.Run(f.Name(), func(t *testing.T) {
+			raw, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("unable to read %s: %v", path, err)
+			}
+			want, err := dicom.ParseFile(path)
+			if err != nil {
+				t.Fatalf("dicom.ParseFile(%s) unexpected error: %v", path, err)
+			}
+			wantPixelData, err := want.FindElementByTag(tag.PixelData)
+			if err != nil {
+				t.Fatalf("unable to find PixelData in %s: %v", path, err)
+			}
+			wantInfo := dicom.MustGetPixelDataInfo(wantPixelData.Value)
+
+			inputs := map[string]func() (dicom.Dataset, error){
+				"ParseFile": func() (dicom.Dataset, error) {
+					return dicom.ParseFile(path, dicom.LazyPixelData())
+				},
+				"io.ReaderAt": func() (dicom.Dataset, error) {
+					return dicom.Parse(bytes.NewReader(raw), dicom.Limit(int64(len(raw))), dicom.LazyPixelData())
+				},
+				"io.ReadSeeker": func() (dicom.Dataset, error) {
+					rs := struct{ io.ReadSeeker }{bytes.NewReader(raw)}
+					return dicom.Parse(rs, dicom.Limit(int64(len(raw))), dicom.LazyPixelData())
+				},
+			}
+			for name, parse := range inputs {
+				t.Run(name, func(t *testing.T) {
+					got, err := parse()
+					if err != nil {
+						t.Fatalf("parse with LazyPixelData() unexpected error: %v", err)
+					}
+					gotPixelData, err := got.FindElementByTag(tag.PixelData)
+					if err != nil {
+						t.Fatalf("unable to find PixelData: %v", err)
+					}
+					gotInfo := dicom.MustGetPixelDataInfo(gotPixelData.Value)
+					if len(gotInfo.Frames) != 0 {
+						t.Errorf("parse with LazyPixelData() loaded %d frames into memory, want 0", len(gotInfo.Frames))
+					}
+					if gotInfo.NumFrames() != len(wantInfo.Frames) {
+						t.Fatalf("NumFrames() unexpected value. got: %d, want: %d", gotInfo.NumFrames(), len(wantInfo.Frames))
+					}
+					for i := range wantInfo.Frames {
+						gotFrame, err := gotInfo.Frame(i)
+						if err != nil {
+							t.Fatalf("Frame(%d) unexpected error: %v", i, err)
+						}
+						if !reflect.DeepEqual(&wantInfo.Frames[i], gotFrame) {
+							t.Errorf("Frame(%d) differs from eagerly parsed frame", i)
+						}
+					}
+					if _, err := gotInfo.Frame(gotInfo.NumFrames()); !errors.Is(err, dicom.ErrorFrameIndexOutOfRange) {
+						t.Errorf("Frame(%d) got error %v, want %v", gotInfo.NumFrames(), err, dicom.ErrorFrameIndexOutOfRange)
+					}
+
+					var wantBuf, gotBuf bytes.Buffer
+					if err := dicom.Write(&wantBuf, want, dicom.SkipVRVerification()); err != nil {
+						t.Fatalf("dicom.Write(eager) unexpected error: %v", err)
+					}
+					if err := dicom.Write(&gotBuf, got, dicom.SkipVRVerification()); err != nil {
+						t.Fatalf("dicom.Write(lazy) unexpected error: %v", err)
+					}
+					if !bytes.Equal(wantBuf.Bytes(), gotBuf.Bytes()) {
+						t.Errorf("dicom.Write of lazily parsed dataset differs from eagerly parsed dataset")
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestParse_lazyPixelData_encapsulated(t *testing.T) {
+	frames := []frame.Frame{
+		{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}, TransferSyntaxUID: uid.ExplicitVRLittleEndian, BitsAllocated: 8}},
+		{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{5, 6, 7, 8, 9, 10}, TransferSyntaxUID: uid.ExplicitVRLittleEndian, BitsAllocated: 8}},
+	}
+	pixelData := mustNewElement(t, tag.PixelData, dicom.PixelDataInfo{IsEncapsulated: true, Frames: frames})
+	pixelData.ValueLength = tag.VLUndefinedLength
+	ds := dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}),
+		mustNewElement(t, tag.BitsAllocated, []int{8}),
+		pixelData,
+		mustNewElement(t, tag.FloatingPointValue, []float64{128.10}),
+	}}
+	buf := bytes.Buffer{}
+	if err := dicom.Write(&buf, ds); err != nil {
+		t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+	}
+
+	// Start the input partway into the io.ReaderAt, to check that frame
+	// offsets are relative to the start of the io.ReaderAt.
+	prefix := []byte("not part of the DICOM")
+	in := bytes.NewReader(append(prefix, buf.Bytes()...))
+	if _, err := in.Seek(int64(len(prefix)), io.SeekStart); err != nil {
+		t.Fatalf("Seek unexpected error: %v", err)
+	}
+	got, err := dicom.Parse(in, dicom.Limit(int64(buf.Len())), dicom.LazyPixelData())
+	if err != nil {
+		t.Fatalf("dicom.Parse(LazyPixelData()) unexpected error: %v", err)
+	}
+	if _, err := got.FindElementByTag(tag.FloatingPointValue); err != nil {
+		t.Errorf("dicom.Parse(LazyPixelData()) missing element after PixelData: %v", err)
+	}
+	gotPixelData, err := got.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	info := dicom.MustGetPixelDataInfo(gotPixelData.Value)
+	if info.NumFrames() != len(frames) {
+		t.Fatalf("NumFrames() unexpected value. got: %d, want: %d", info.NumFrames(), len(frames))
+	}
+	for i := range frames {
+		f, err := info.Frame(i)
+		if err != nil {
+			t.Fatalf("Frame(%d) unexpected error: %v", i, err)
+		}
+		if diff := cmp.Diff(&frames[i], f); diff != "" {
+			t.Errorf("Frame(%d) unexpected diff: %v", i, diff)
+		}
+	}
+}
+
+func TestParse_jpegLossless(t *testing.T) {
+	// A 3x1 8 bit lossless JPEG image with samples 128, 130 and 129, padded
+	// to an even length.
+	jpegData := []byte{
+		0xFF, 0xD8,
+		0xFF, 0xC3, 0x00, 0x0B, 0x08, 0x00, 0x01, 0x00, 0x03, 0x01, 0x01, 0x11, 0x00,
+		0xFF, 0xC4, 0x00, 0x16, 0x00, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x00, 0x01, 0x02,
+		0xFF, 0xDA, 0x00, 0x08, 0x01, 0x01, 0x00, 0x01, 0x00, 0x00,
+		0x6A, 0x7F,
+		0xFF, 0xD9, 0x00,
+	}
+	pixelData := mustNewElement(t, tag.PixelData, dicom.PixelDataInfo{
+		IsEncapsulated: true,
+		Frames:         []frame.Frame{{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: jpegData}}},
+	})
+	pixelData.ValueLength = tag.VLUndefinedLength
+	ds := dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.TransferSyntaxUID, []string{uid.JPEGLosslessSV1}),
+		mustNewElement(t, tag.BitsAllocated, []int{8}),
+		mustNewElement(t, tag.PhotometricInterpretation, []string{"MONOCHROME2"}),
+		pixelData,
+	}}
+	buf := bytes.Buffer{}
+	if err := dicom.Write(&buf, ds); err != nil {
+		t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+	}
+
+	for _, lazy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("lazy: %v", lazy), func(t *testing.T) {
+			opts := []dicom.Option{dicom.Limit(int64(buf.Len()))}
+			if lazy {
+				opts = append(opts, dicom.LazyPixelData())
+			}
+			got, err := dicom.Parse(bytes.NewReader(buf.Bytes()), opts...)
+			if err != nil {
+				t.Fatalf("dicom.Parse unexpected error: %v", err)
+			}
+			gotPixelData, err := got.FindElementByTag(tag.PixelData)
+			if err != nil {
+				t.Fatalf("unable to find PixelData: %v", err)
+			}
+			f, err := dicom.MustGetPixelDataInfo(gotPixelData.Value).Frame(0)
+			if err != nil {
+				t.Fatalf("Frame(0) unexpected error: %v", err)
+			}
+			native, err := f.GetNativeFrame()
+			if err != nil {
+				t.Fatalf("GetNativeFrame unexpected error: %v", err)
+			}
+			want := &frame.NativeFrame{
+				Rows: 1, Cols: 3, BitsPerSample: 8, Samples: frame.Uint8Buffer{128, 130, 129}, SamplesPerPixel: 1,
+				PhotometricInterpretation: "MONOCHROME2",
+			}
+			if diff := cmp.Diff(want, native); diff != "" {
+				t.Errorf("GetNativeFrame unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// invertCodec is a codec.Codec that encodes each frame as a single fragment
+// holding its inverted samples.
+type invertCodec struct{}
+
+func (invertCodec) Decode(fragments [][]byte, l codec.Layout) ([]byte, codec.Layout, error) {
+	var data []byte
+	for _, f := range fragments {
+		for _, b := range f {
+			data = append(data, ^b)
+		}
+	}
+	return data, l, nil
+}
+
+func (c invertCodec) Encode(data []byte, l codec.Layout) ([][]byte, error) {
+	inverted, _, err := c.Decode([][]byte{data}, l)
+	return [][]byte{inverted}, err
+}
+
+func TestParse_registeredCodec(t *testing.T) {
+	// High-Throughput JPEG 2000 is not in the uid dictionary.
+	const htj2k = "1.2.840.10008.1.2.4.201"
+	codec.Register(htj2k, invertCodec{})
+	defer codec.Register(htj2k, nil)
+
+	want := &frame.NativeFrame{
+		Rows: 2, Cols: 2, BitsPerSample: 8, Samples: frame.Uint8Buffer{0, 1, 2, 255}, SamplesPerPixel: 1,
+		PhotometricInterpretation: "MONOCHROME2",
+	}
+	ds := dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.TransferSyntaxUID, []string{htj2k}),
+		mustNewElement(t, tag.Rows, []int{2}),
+		mustNewElement(t, tag.Columns, []int{2}),
+		mustNewElement(t, tag.SamplesPerPixel, []int{1}),
+		mustNewElement(t, tag.BitsAllocated, []int{8}),
+		mustNewElement(t, tag.PhotometricInterpretation, []string{"MONOCHROME2"}),
+		mustNewElement(t, tag.PixelData, dicom.PixelDataInfo{Frames: []frame.Frame{{NativeData: *want}}}),
+	}}
+	buf := bytes.Buffer{}
+	if err := dicom.Write(&buf, ds); err != nil {
+		t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+	}
+
+	var warnings []dicom.Warning
+	got, err := dicom.Parse(bytes.NewReader(buf.Bytes()), dicom.Limit(int64(buf.Len())), dicom.WarningHandler(func(w dicom.Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err != nil {
+		t.Fatalf("dicom.Parse unexpected error: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("dicom.Parse unexpected warnings: %v", warnings)
+	}
+	pixelData, err := got.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	info := dicom.MustGetPixelDataInfo(pixelData.Value)
+	if !info.IsEncapsulated || len(info.Frames) != 1 {
+		t.Fatalf("dicom.Write wrote unexpected PixelData: got %d frames, encapsulated: %v", len(info.Frames), info.IsEncapsulated)
+	}
+	if diff := cmp.Diff([]byte{255, 254, 253, 0}, info.Frames[0].EncapsulatedData.Data); diff != "" {
+		t.Errorf("dicom.Write unexpected encoded frame (-want +got):\n%s", diff)
+	}
+	native, err := info.Frames[0].GetNativeFrame()
+	if err != nil {
+		t.Fatalf("GetNativeFrame unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, native); diff != "" {
+		t.Errorf("GetNativeFrame unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParse_bulkDataThreshold(t *testing.T) {
+	doc := make([]byte, 4096)
+	for i := range doc {
+		doc[i] = byte(i)
+	}
+	ds := dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}),
+		mustNewElement(t, tag.PatientName, []string{"Bob", "Jones"}),
+		mustNewElement(t, tag.EncapsulatedDocument, doc),
+		mustNewElement(t, tag.WaveformData, []byte{1, 2, 3, 4}),
+	}}
+	buf := bytes.Buffer{}
+	if err := dicom.Write(&buf, ds); err != nil {
+		t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+	}
+	data := buf.Bytes()
+
+	dir, err := ioutil.TempDir("", "bulkdata")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/bulkdata.dcm"
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("unable to write %s: %v", path, err)
+	}
+
+	cases := []struct {
+		name    string
+		parse   func() (dicom.Dataset, error)
+		wantURI string
+	}{
+		{
+			name: "io.ReaderAt",
+			parse: func() (dicom.Dataset, error) {
+				return dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))), dicom.BulkDataThreshold(1024), dicom.BulkDataSourceURI("https://example.com/instance"))
+			},
+			wantURI: "https://example.com/instance",
+		},
+		{
+			name: "ParseFile",
+			parse: func() (dicom.Dataset, error) {
+				return dicom.ParseFile(path, dicom.BulkDataThreshold(1024))
+			},
+			wantURI: "file://" + path,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.parse()
+			if err != nil {
+				t.Fatalf("parse with BulkDataThreshold unexpected error: %v", err)
+			}
+
+			docElem, err := got.FindElementByTag(tag.EncapsulatedDocument)
+			if err != nil {
+				t.Fatalf("unable to find EncapsulatedDocument: %v", err)
+			}
+			if docElem.Value.ValueType() != dicom.BulkData {
+				t.Fatalf("EncapsulatedDocument unexpected ValueType. got: %v, want: %v", docElem.Value.ValueType(), dicom.BulkData)
+			}
+			ref := dicom.MustGetBulkDataReference(docElem.Value)
+			if ref.URI != tc.wantURI || ref.Length != int64(len(doc)) || ref.VR != "OB" {
+				t.Errorf("unexpected BulkDataReference: %+v", ref)
+			}
+			if want := fmt.Sprintf("%s?offset=%d&length=%d", tc.wantURI, ref.Offset, len(doc)); ref.BulkDataURI() != want {
+				t.Errorf("BulkDataURI() unexpected value. got: %v, want: %v", ref.BulkDataURI(), want)
+			}
+			resolved, err := ref.Resolve()
+			if err != nil {
+				t.Fatalf("Resolve() unexpected error: %v", err)
+			}
+			if !bytes.Equal(resolved, doc) {
+				t.Errorf("Resolve() returned unexpected bytes")
+			}
+
+			waveform, err := got.FindElementByTag(tag.WaveformData)
+			if err != nil {
+				t.Fatalf("unable to find WaveformData: %v", err)
+			}
+			if waveform.Value.ValueType() != dicom.Bytes {
+				t.Errorf("WaveformData below threshold unexpected ValueType. got: %v, want: %v", waveform.Value.ValueType(), dicom.Bytes)
+			}
+
+			written := bytes.Buffer{}
+			if err := dicom.Write(&written, got); err != nil {
+				t.Fatalf("dicom.Write unexpected error: %v", err)
+			}
+			if !bytes.Equal(written.Bytes(), data) {
+				t.Errorf("dicom.Write of dataset with BulkDataReferences differs from the original")
+			}
+		})
+	}
+
+	t.Run("io.Reader", func(t *testing.T) {
+		got, err := dicom.Parse(struct{ io.Reader }{bytes.NewReader(data)}, dicom.Limit(int64(len(data))), dicom.BulkDataThreshold(1024))
+		if err != nil {
+			t.Fatalf("dicom.Parse with BulkDataThreshold unexpected error: %v", err)
+		}
+		docElem, err := got.FindElementByTag(tag.EncapsulatedDocument)
+		if err != nil {
+			t.Fatalf("unable to find EncapsulatedDocument: %v", err)
+		}
+		ref := dicom.MustGetBulkDataReference(docElem.Value)
+		if _, err := ref.Resolve(); !errors.Is(err, dicom.ErrorUnresolvableBulkData) {
+			t.Errorf("Resolve() without a source got error %v, want %v", err, dicom.ErrorUnresolvableBulkData)
+		}
+		resolved, err := ref.ResolveFrom(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("ResolveFrom() unexpected error: %v", err)
+		}
+		if !bytes.Equal(resolved, doc) {
+			t.Errorf("ResolveFrom() returned unexpected bytes")
+		}
+	})
+
+	t.Run("big endian words", func(t *testing.T) {
+		words := make([]byte, 2048)
+		for i := range words {
+			words[i] = byte(i)
+		}
+		floats := make([]float64, 512)
+		for i := range floats {
+			floats[i] = float64(i) / 4
+		}
+		ds := dicom.Dataset{Elements: []*dicom.Element{
+			mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ExplicitVRBigEndian}),
+			mustNewElement(t, tag.RedPaletteColorLookupTableData, words),
+			mustNewElement(t, tag.VectorGridData, floats),
+		}}
+		buf := bytes.Buffer{}
+		if err := dicom.Write(&buf, ds); err != nil {
+			t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+		}
+		want, err := dicom.Parse(bytes.NewReader(buf.Bytes()), dicom.Limit(int64(buf.Len())))
+		if err != nil {
+			t.Fatalf("dicom.Parse unexpected error: %v", err)
+		}
+		got, err := dicom.Parse(bytes.NewReader(buf.Bytes()), dicom.Limit(int64(buf.Len())), dicom.BulkDataThreshold(1024))
+		if err != nil {
+			t.Fatalf("dicom.Parse with BulkDataThreshold unexpected error: %v", err)
+		}
+
+		// The words of the BulkData are converted to little endian when
+		// transcoding.
+		transcoded, err := dicom.Transcode(got, uid.ExplicitVRLittleEndian)
+		if err != nil {
+			t.Fatalf("dicom.Transcode unexpected error: %v", err)
+		}
+		written := bytes.Buffer{}
+		if err := dicom.Write(&written, transcoded); err != nil {
+			t.Fatalf("dicom.Write unexpected error: %v", err)
+		}
+		reparsed, err := dicom.Parse(bytes.NewReader(written.Bytes()), dicom.Limit(int64(written.Len())))
+		if err != nil {
+			t.Fatalf("dicom.Parse of transcoded dataset unexpected error: %v", err)
+		}
+		for _, tg := range []tag.Tag{tag.RedPaletteColorLookupTableData, tag.VectorGridData} {
+			wantElem, err := want.FindElementByTag(tg)
+			if err != nil {
+				t.Fatalf("unable to find %v: %v", tg, err)
+			}
+			gotElem, err := reparsed.FindElementByTag(tg)
+			if err != nil {
+				t.Fatalf("unable to find %v after Transcode: %v", tg, err)
+			}
+			if diff := cmp.Diff(wantElem.Value.GetValue(), gotElem.Value.GetValue()); diff != "" {
+				t.Errorf("unexpected %v after Transcode of BulkData (-want +got):\n%s", tg, diff)
+			}
+		}
+	})
+}
+
+func TestParseContext(t *testing.T) {
+	t.Run("cancelled before parsing", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		fc := make(chan *frame.Frame, 1)
+		_, err := dicom.ParseFileContext(ctx, "./testdata/1.dcm", dicom.FrameChannel(fc))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("dicom.ParseFileContext(cancelled) got error %v, want %v", err, context.Canceled)
+		}
+		if _, ok := <-fc; ok {
+			t.Errorf("dicom.ParseFileContext(cancelled) did not close the FrameChannel")
+		}
+	})
+
+	t.Run("cancelled while streaming frames", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		fc := make(chan *frame.Frame)
+		done := make(chan error)
+		go func() {
+			_, err := dicom.ParseFileContext(ctx, "./testdata/5.dcm", dicom.FrameChannel(fc))
+			done <- err
+		}()
+
+		// Cancel after the first frame, then drain the FrameChannel until it is
+		// closed.
+		<-fc
+		cancel()
+		timeout := time.After(10 * time.Second)
+		for closed := false; !closed; {
+			select {
+			case _, ok := <-fc:
+				closed = !ok
+			case <-timeout:
+				t.Fatalf("timed out waiting for the FrameChannel to be closed")
+			}
+		}
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("dicom.ParseFileContext got error %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("Parser.Next", func(t *testing.T) {
+		f, err := os.Open("./testdata/1.dcm")
+		if err != nil {
+			t.Fatalf("unable to open testdata/1.dcm: %v", err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatalf("unable to stat testdata/1.dcm: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		fc := make(chan *frame.Frame, 1)
+		p, err := dicom.NewParserContext(ctx, f, dicom.Limit(info.Size()), dicom.FrameChannel(fc))
+		if err != nil {
+			t.Fatalf("dicom.NewParserContext unexpected error: %v", err)
+		}
+		if _, err := p.Next(); err != nil {
+			t.Fatalf("Parser.Next unexpected error: %v", err)
+		}
+		cancel()
+		for i := 0; i < 2; i++ {
+			if _, err := p.Next(); !errors.Is(err, context.Canceled) {
+				t.Errorf("Parser.Next after cancellation got error %v, want %v", err, context.Canceled)
+			}
+		}
+		if _, ok := <-fc; ok {
+			t.Errorf("Parser.Next after cancellation did not close the FrameChannel")
+		}
+	})
+}
+
+func mustNewElement(t *testing.T, tg tag.Tag, data interface{}) *dicom.Element {
+	t.Helper()
+	e, err := dicom.NewElement(tg, data)
+	if err != nil {
+		t.Fatalf("dicom.NewElement(%v, %v) unexpected error: %v", tg, data, err)
+	}
+	return e
+}
+
+func TestParse_warnings(t *testing.T) {
+	t.Run("missing transfer syntax", func(t *testing.T) {
+		// A single implicit VR little endian element without any metadata.
+		value := []byte(strings.Repeat("A", 200))
+		data := bytes.Buffer{}
+		for _, v := range []interface{}{tag.PatientComments.Group, tag.PatientComments.Element, uint32(len(value)), value} {
+			if err := binary.Write(&data, binary.LittleEndian, v); err != nil {
+				t.Fatalf("unable to setup test buffer: %v", err)
+			}
+		}
+
+		var warnings []dicom.Warning
+		_, err := dicom.Parse(bytes.NewReader(data.Bytes()), dicom.Limit(int64(data.Len())), dicom.WarningHandler(func(w dicom.Warning) {
+			warnings = append(warnings, w)
+		}))
+		if err != nil {
+			t.Fatalf("dicom.Parse unexpected error: %v", err)
+		}
+		if len(warnings) != 1 || warnings[0].Category != dicom.WarningMissingTransferSyntax {
+			t.Errorf("dicom.Parse unexpected warnings. got: %v, want a single %v warning", warnings, dicom.WarningMissingTransferSyntax)
+		}
+	})
+
+	t.Run("invalid delimiter in pixel data", func(t *testing.T) {
+		ds := dicom.Dataset{Elements: []*dicom.Element{
+			mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}),
+			mustNewElement(t, tag.BitsAllocated, []int{8}),
+			mustNewElement(t, tag.PixelData, dicom.PixelDataInfo{
+				IsEncapsulated: true,
+				Frames: []frame.Frame{
+					{
+						Encapsulated:     true,
+						EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}},
+					},
+				},
+			}),
+		}}
+		ds.Elements[2].ValueLength = tag.VLUndefinedLength
+		buf := bytes.Buffer{}
+		if err := dicom.Write(&buf, ds); err != nil {
+			t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+		}
+		// Set the VL of the trailing SequenceDelimitationItem to 2.
+		data := buf.Bytes()
+		data[len(data)-4] = 2
+		delimiterOffset := int64(len(data) - 8)
+
+		p, err := dicom.NewParser(bytes.NewReader(data), dicom.Limit(int64(len(data))))
+		if err != nil {
+			t.Fatalf("dicom.NewParser unexpected error: %v", err)
+		}
+		for {
+			if _, err := p.Next(); err != nil {
+				if err == dicom.ErrorEndOfDICOM {
+					break
+				}
+				t.Fatalf("Parser.Next() unexpected error: %v", err)
+			}
+		}
+
+		want := []dicom.Warning{{
+			Category: dicom.WarningInvalidDelimiter,
+			Path:     dicom.TagPath{tag.PixelData},
+			Offset:   delimiterOffset,
+			Message:  "SequenceDelimitationItem's VL != 0: 2",
+		}}
+		if diff := cmp.Diff(want, p.Warnings()); diff != "" {
+			t.Errorf("Parser.Warnings() unexpected diff: %s", diff)
+		}
+	})
+
+	t.Run("non item in defined length sequence", func(t *testing.T) {
+		ds := dicom.Dataset{Elements: []*dicom.Element{
+			mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}),
+			mustNewElement(t, tag.PatientName, []string{"Bob"}),
+		}}
+		buf := bytes.Buffer{}
+		if err := dicom.Write(&buf, ds); err != nil {
+			t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+		}
+		seqOffset := int64(buf.Len())
+		// A ReferencedStudySequence of 20 bytes, holding an empty Item and a
+		// PatientID element.
+		for _, v := range []interface{}{
+			tag.ReferencedStudySequence.Group, tag.ReferencedStudySequence.Element, []byte("SQ"), uint16(0), uint32(20),
+			tag.Item.Group, tag.Item.Element, uint32(0),
+			tag.PatientID.Group, tag.PatientID.Element, []byte("LO"), uint16(4), []byte("1234"),
+		} {
+			if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+				t.Fatalf("unable to setup test buffer: %v", err)
+			}
+		}
+		data := buf.Bytes()
+
+		var warnings []dicom.Warning
+		got, err := dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))), dicom.WarningHandler(func(w dicom.Warning) {
+			warnings = append(warnings, w)
+		}))
+		if err != nil {
+			t.Fatalf("dicom.Parse unexpected error: %v", err)
+		}
+		seq, err := got.FindElementByTag(tag.ReferencedStudySequence)
+		if err != nil {
+			t.Fatalf("unable to find ReferencedStudySequence: %v", err)
+		}
+		if items := seq.Value.GetValue().([]*dicom.SequenceItemValue); len(items) != 1 {
+			t.Errorf("dicom.Parse unexpected number of items. got: %d, want: 1", len(items))
+		}
+		want := []dicom.Warning{{
+			Category: dicom.WarningUnexpectedTag,
+			Path:     dicom.TagPath{tag.ReferencedStudySequence},
+			Offset:   seqOffset + 20,
+			Message:  "non item " + tag.PatientID.String() + " found in sequence",
+		}}
+		if diff := cmp.Diff(want, warnings); diff != "" {
+			t.Errorf("dicom.Parse unexpected warnings diff: %s", diff)
+		}
+
+		_, err = dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))), dicom.Lenient())
+		var elemErrs dicom.ElementErrors
+		if !errors.As(err, &elemErrs) || len(elemErrs) != 1 || elemErrs[0].Tag != tag.PatientID {
+			t.Errorf("dicom.Parse(Lenient()) got error %v, want an ElementError for %v", err, tag.PatientID)
+		}
+	})
+}
+
+func TestParse_limits(t *testing.T) {
+	// A Sequence nested 3 deep.
+	nested := mustNewElement(t, tag.PatientName, []string{"Bob"})
+	for i := 0; i < 3; i++ {
+		nested = mustNewElement(t, tag.ReferencedSeriesSequence, [][]*dicom.Element{{nested}})
+	}
+	ds := dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}),
+		mustNewElement(t, tag.PatientComments, []string{strings.Repeat("A", 2048)}),
+		nested,
+	}}
+	buf := bytes.Buffer{}
+	if err := dicom.Write(&buf, ds); err != nil {
+		t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+	}
+	data := buf.Bytes()
+
+	// A single implicit VR element with a VL much larger than the input.
+	lying := bytes.Buffer{}
+	for _, v := range []interface{}{tag.PatientComments.Group, tag.PatientComments.Element, uint32(0xFFFFFFF0), []byte(strings.Repeat("A", 200))} {
+		if err := binary.Write(&lying, binary.LittleEndian, v); err != nil {
+			t.Fatalf("unable to setup test buffer: %v", err)
+		}
+	}
+
+	// Explicit VR elements with a VL much larger than the input, in a deflated
+	// Dataset. The length of the inflated input is unknown, so the VL cannot be
+	// checked up front.
+	lyingDeflated := func(vr string) []byte {
+		element := bytes.Buffer{}
+		for _, v := range []interface{}{tag.EncapsulatedDocument.Group, tag.EncapsulatedDocument.Element, []byte(vr), uint16(0), uint32(0xF0000000), []byte(strings.Repeat("A", 200))} {
+			if err := binary.Write(&element, binary.LittleEndian, v); err != nil {
+				t.Fatalf("unable to setup test buffer: %v", err)
+			}
+		}
+		return deflate(t, element.Bytes())
+	}
+
+	cases := []struct {
+		name    string
+		data    []byte
+		opts    []dicom.Option
+		wantErr error
+		// maxAlloc is the most memory that parsing may allocate, if set.
+		maxAlloc uint64
+	}{
+		{
+			name:    "MaxElementSize",
+			data:    data,
+			opts:    []dicom.Option{dicom.MaxElementSize(1024)},
+			wantErr: dicom.ErrorElementTooLarge,
+		},
+		{
+			name:    "MaxTotalAllocation",
+			data:    data,
+			opts:    []dicom.Option{dicom.MaxTotalAllocation(1024)},
+			wantErr: dicom.ErrorAllocationLimitExceeded,
+		},
+		{
+			name:    "MaxSequenceDepth",
+			data:    data,
+			opts:    []dicom.Option{dicom.MaxSequenceDepth(2)},
+			wantErr: dicom.ErrorSequenceTooDeep,
+		},
+		{
+			name:    "MaxElements",
+			data:    data,
+			opts:    []dicom.Option{dicom.MaxElements(5)},
+			wantErr: dicom.ErrorTooManyElements,
+		},
+		{
+			name:    "limits are not recovered from in Lenient mode",
+			data:    data,
+			opts:    []dicom.Option{dicom.MaxSequenceDepth(2), dicom.Lenient()},
+			wantErr: dicom.ErrorSequenceTooDeep,
+		},
+		{
+			name:    "within limits",
+			data:    data,
+			opts:    []dicom.Option{dicom.MaxElementSize(4096), dicom.MaxTotalAllocation(1 << 20), dicom.MaxSequenceDepth(3), dicom.MaxElements(100)},
+			wantErr: nil,
+		},
+		{
+			name:    "VL longer than the input",
+			data:    lying.Bytes(),
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:     "deflated OB VL longer than the input",
+			data:     lyingDeflated("OB"),
+			wantErr:  io.ErrUnexpectedEOF,
+			maxAlloc: 64 << 20,
+		},
+		{
+			name:     "deflated OF VL longer than the input",
+			data:     lyingDeflated("OF"),
+			wantErr:  io.EOF,
+			maxAlloc: 64 << 20,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]dicom.Option{dicom.Limit(int64(len(tc.data)))}, tc.opts...)
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			_, err := dicom.Parse(bytes.NewReader(tc.data), opts...)
+			runtime.ReadMemStats(&after)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("dicom.Parse unexpected error. got: %v, want: %v", err, tc.wantErr)
+			}
+			if alloc := after.TotalAlloc - before.TotalAlloc; tc.maxAlloc > 0 && alloc > tc.maxAlloc {
+				t.Errorf("dicom.Parse allocated %d bytes, want at most %d", alloc, tc.maxAlloc)
+			}
+		})
+	}
+}
+
+func TestParse_reinterpretUN(t *testing.T) {
+	write := func(buf *bytes.Buffer, bo binary.ByteOrder, values ...interface{}) {
+		for _, v := range values {
+			if err := binary.Write(buf, bo, v); err != nil {
+				t.Fatalf("unable to setup test buffer: %v", err)
+			}
+		}
+	}
+	// writeHeader writes an explicit VR element header with a 32-bit VL.
+	writeHeader := func(buf *bytes.Buffer, bo binary.ByteOrder, tg tag.Tag, vr string, vl uint32) {
+		write(buf, bo, tg.Group, tg.Element, []byte(vr), uint16(0), vl)
+	}
+	// writeImplicit writes an Implicit VR Little Endian element.
+	writeImplicit := func(buf *bytes.Buffer, tg tag.Tag, vl uint32, value []byte) {
+		write(buf, binary.LittleEndian, tg.Group, tg.Element, vl, value)
+	}
+	privateSequence := tag.Tag{Group: 0x0009, Element: 0x1010}
+
+	// makeData returns a DICOM in the given transfer syntax made up of UN
+	// elements (which are always encoded as implicit little endian),
+	// optionally including undefined length UN Sequences, followed by a
+	// regular element.
+	makeData := func(transferSyntax string, bo binary.ByteOrder, sequences bool) []byte {
+		ds := dicom.Dataset{Elements: []*dicom.Element{
+			mustNewElement(t, tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+			mustNewElement(t, tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+			mustNewElement(t, tag.TransferSyntaxUID, []string{transferSyntax}),
+		}}
+		buf := bytes.Buffer{}
+		if err := dicom.Write(&buf, ds); err != nil {
+			t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+		}
+
+		writeHeader(&buf, bo, tag.PatientName, "UN", 10)
+		buf.WriteString("Bob^Jones ")
+		writeHeader(&buf, bo, tag.Rows, "UN", 2)
+		write(&buf, binary.LittleEndian, uint16(512))
+		if sequences {
+			for _, tg := range []tag.Tag{tag.ReferencedSeriesSequence, privateSequence} {
+				writeHeader(&buf, bo, tg, "UN", tag.VLUndefinedLength)
+				writeImplicit(&buf, tag.Item, tag.VLUndefinedLength, nil)
+				writeImplicit(&buf, tag.SeriesInstanceUID, 6, []byte("1.2.3\x00"))
+				writeImplicit(&buf, tag.ItemDelimitationItem, 0, nil)
+				writeImplicit(&buf, tag.SequenceDelimitationItem, 0, nil)
+			}
+		}
+		write(&buf, bo, tag.StudyID.Group, tag.StudyID.Element, []byte("SH"), uint16(2), []byte("42"))
+		return buf.Bytes()
+	}
+
+	for _, ts := range []struct {
+		uid string
+		bo  binary.ByteOrder
+	}{
+		{uid.ExplicitVRLittleEndian, binary.LittleEndian},
+		{uid.ExplicitVRBigEndian, binary.BigEndian},
+	} {
+		t.Run(ts.uid, func(t *testing.T) {
+			data := makeData(ts.uid, ts.bo, true)
+			ds, err := dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))), dicom.ReinterpretUN())
+			if err != nil {
+				t.Fatalf("dicom.Parse with ReinterpretUN unexpected error: %v", err)
+			}
+
+			for _, want := range []struct {
+				tag   tag.Tag
+				vr    string
+				value interface{}
+			}{
+				{tag.PatientName, "PN", []string{"Bob^Jones"}},
+				{tag.Rows, "US", []int{512}},
+				{tag.ReferencedSeriesSequence, "SQ", nil},
+				{privateSequence, "SQ", nil},
+			} {
+				elem, err := ds.FindElementByTag(want.tag)
+				if err != nil {
+					t.Fatalf("unable to find %v: %v", want.tag, err)
+				}
+				if elem.RawValueRepresentation != want.vr || !elem.ReinterpretedFromUN {
+					t.Errorf("%v unexpected VR. got: %v (ReinterpretedFromUN=%v), want: %v (ReinterpretedFromUN=true)",
+						want.tag, elem.RawValueRepresentation, elem.ReinterpretedFromUN, want.vr)
+				}
+				if want.value == nil {
+					items := elem.Value.GetValue().([]*dicom.SequenceItemValue)
+					if len(items) != 1 {
+						t.Fatalf("%v unexpected number of items. got: %d, want: 1", want.tag, len(items))
+					}
+					itemElems := items[0].GetValue().([]*dicom.Element)
+					if len(itemElems) != 1 || itemElems[0].Tag != tag.SeriesInstanceUID {
+						t.Fatalf("%v unexpected item: %v", want.tag, itemElems)
+					}
+					want.value = []string{"1.2.3"}
+					elem = itemElems[0]
+				}
+				if diff := cmp.Diff(want.value, elem.Value.GetValue()); diff != "" {
+					t.Errorf("%v unexpected value. diff: %v", want.tag, diff)
+				}
+			}
+
+			studyID, err := ds.FindElementByTag(tag.StudyID)
+			if err != nil {
+				t.Fatalf("unable to find StudyID after the UN elements: %v", err)
+			}
+			if diff := cmp.Diff([]string{"42"}, studyID.Value.GetValue()); diff != "" {
+				t.Errorf("StudyID unexpected value. diff: %v", diff)
+			}
+		})
+	}
+
+	t.Run("without ReinterpretUN", func(t *testing.T) {
+		data := makeData(uid.ExplicitVRLittleEndian, binary.LittleEndian, false)
+		ds, err := dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))))
+		if err != nil {
+			t.Fatalf("dicom.Parse unexpected error: %v", err)
+		}
+		elem, err := ds.FindElementByTag(tag.Rows)
+		if err != nil {
+			t.Fatalf("unable to find Rows: %v", err)
+		}
+		if elem.RawValueRepresentation != "UN" || elem.ReinterpretedFromUN {
+			t.Errorf("Rows unexpected VR. got: %v (ReinterpretedFromUN=%v), want: UN", elem.RawValueRepresentation, elem.ReinterpretedFromUN)
+		}
+	})
+}
+
+func TestParse_privateTags(t *testing.T) {
+	buf := bytes.Buffer{}
+	ds := dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+		mustNewElement(t, tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+		mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ImplicitVRLittleEndian}),
+	}}
+	if err := dicom.Write(&buf, ds); err != nil {
+		t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+	}
+	// writeImplicit writes an Implicit VR Little Endian element.
+	writeImplicit := func(tg tag.Tag, vl uint32, value interface{}) {
+		for _, v := range []interface{}{tg.Group, tg.Element, vl, value} {
+			if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+				t.Fatalf("unable to setup test buffer: %v", err)
+			}
+		}
+	}
+	writeImplicit(tag.Tag{Group: 0x0019, Element: 0x0010}, 18, []byte("SIEMENS MR HEADER "))
+	writeImplicit(tag.Tag{Group: 0x0019, Element: 0x100A}, 2, uint16(6))
+	writeImplicit(tag.Tag{Group: 0x0019, Element: 0x100C}, 4, []byte("1000"))
+	writeImplicit(tag.Tag{Group: 0x0019, Element: 0x1101}, 2, []byte("??"))
+	// Private creators in a Sequence Item only apply within the Item.
+	writeImplicit(tag.ReferencedSeriesSequence, tag.VLUndefinedLength, []byte{})
+	writeImplicit(tag.Item, tag.VLUndefinedLength, []byte{})
+	writeImplicit(tag.Tag{Group: 0x0019, Element: 0x0010}, 12, []byte("GEMS_ACQU_01"))
+	writeImplicit(tag.Tag{Group: 0x0019, Element: 0x109C}, 8, []byte("epi2    "))
+	writeImplicit(tag.ItemDelimitationItem, 0, []byte{})
+	writeImplicit(tag.SequenceDelimitationItem, 0, []byte{})
+	data := buf.Bytes()
+
+	parsed, err := dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))))
+	if err != nil {
+		t.Fatalf("dicom.Parse unexpected error: %v", err)
+	}
+
+	for _, want := range []struct {
+		tag   tag.Tag
+		vr    string
+		value interface{}
+	}{
+		{tag.Tag{Group: 0x0019, Element: 0x0010}, "LO", []string{"SIEMENS MR HEADER"}},
+		{tag.Tag{Group: 0x0019, Element: 0x100A}, "US", []int{6}},
+		{tag.Tag{Group: 0x0019, Element: 0x100C}, "IS", []string{"1000"}},
+		{tag.Tag{Group: 0x0019, Element: 0x1101}, "UN", []string{"??"}},
+	} {
+		elem, err := parsed.FindElementByTag(want.tag)
+		if err != nil {
+			t.Fatalf("unable to find %v: %v", want.tag, err)
+		}
+		if elem.RawValueRepresentation != want.vr {
+			t.Errorf("%v unexpected VR. got: %v, want: %v", want.tag, elem.RawValueRepresentation, want.vr)
+		}
+		if diff := cmp.Diff(want.value, elem.Value.GetValue()); diff != "" {
+			t.Errorf("%v unexpected value. diff: %v", want.tag, diff)
+		}
+	}
+
+	seq, err := parsed.FindElementByTag(tag.ReferencedSeriesSequence)
+	if err != nil {
+		t.Fatalf("unable to find ReferencedSeriesSequence: %v", err)
+	}
+	itemElems := seq.Value.GetValue().([]*dicom.SequenceItemValue)[0].GetValue().([]*dicom.Element)
+	if len(itemElems) != 2 || itemElems[1].RawValueRepresentation != "LO" {
+		t.Fatalf("unexpected item elements: %v", itemElems)
+	}
+	if diff := cmp.Diff([]string{"epi2"}, itemElems[1].Value.GetValue()); diff != "" {
+		t.Errorf("unexpected private element value in item. diff: %v", diff)
+	}
+
+	creator, err := parsed.PrivateCreator(tag.Tag{Group: 0x0019, Element: 0x100A})
+	if err != nil || creator != tag.CreatorSiemensMRHeader {
+		t.Errorf("PrivateCreator returned unexpected result. got: %q, %v, want: %q", creator, err, tag.CreatorSiemensMRHeader)
+	}
+
+	str := parsed.String()
+	for _, name := range []string{"PrivateCreator", "NumberOfImagesInMosaic", "BValue", "PulseSequenceName"} {
+		if !strings.Contains(str, "Tag Name: "+name+"\n") {
+			t.Errorf("Dataset.String() does not contain the private tag name %v", name)
+		}
+	}
+}
+
+func TestParse_repeatingGroups(t *testing.T) {
+	buf := bytes.Buffer{}
+	ds := dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+		mustNewElement(t, tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+		mustNewElement(t, tag.TransferSyntaxUID, []string{uid.ImplicitVRLittleEndian}),
+	}}
+	if err := dicom.Write(&buf, ds); err != nil {
+		t.Fatalf("dicom.Write(%v) unexpected error: %v", ds, err)
+	}
+	overlayRows := tag.Tag{Group: 0x6002, Element: tag.OverlayRows.Element}
+	overlayData := tag.Tag{Group: 0x6002, Element: tag.OverlayData.Element}
+	for _, v := range []interface{}{
+		overlayRows, uint32(2), uint16(4),
+		overlayData, uint32(4), []byte{0x01, 0x02, 0x03, 0x04},
+	} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("unable to setup test buffer: %v", err)
+		}
+	}
+	data := buf.Bytes()
+
+	parsed, err := dicom.Parse(bytes.NewReader(data), dicom.Limit(int64(len(data))))
+	if err != nil {
+		t.Fatalf("dicom.Parse unexpected error: %v", err)
+	}
+	for _, want := range []struct {
+		tag   tag.Tag
+		vr    string
+		value interface{}
+	}{
+		{overlayRows, "US", []int{4}},
+		{overlayData, "OW", []byte{0x01, 0x02, 0x03, 0x04}},
+	} {
+		elem, err := parsed.FindElementByTag(want.tag)
+		if err != nil {
+			t.Fatalf("unable to find %v: %v", want.tag, err)
+		}
+		if elem.RawValueRepresentation != want.vr {
+			t.Errorf("%v unexpected VR. got: %v, want: %v", want.tag, elem.RawValueRepresentation, want.vr)
+		}
+		if diff := cmp.Diff(want.value, elem.Value.GetValue()); diff != "" {
+			t.Errorf("%v unexpected value. diff: %v", want.tag, diff)
+		}
+	}
+}
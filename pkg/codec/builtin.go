@@ -0,0 +1,214 @@
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+
+	"github.com/suyashkumar/dicom/pkg/jpeglossless"
+	"github.com/suyashkumar/dicom/pkg/jpegls"
+	"github.com/suyashkumar/dicom/pkg/rle"
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+func init() {
+	Register(uid.JPEGBaseline8Bit, jpegBaselineCodec{})
+	Register(uid.JPEGLossless, jpegLosslessCodec{})
+	Register(uid.JPEGLosslessSV1, jpegLosslessCodec{})
+	Register(uid.JPEGLSLossless, NewJPEGLS(0))
+	Register(uid.JPEGLSNearLossless, jpeglsNearLosslessCodec{})
+	Register(uid.RLELossless, rleCodec{})
+}
+
+// jpegBaselineCodec decodes 8 bit lossy JPEG frames using image/jpeg. It is not
+// registered for the JPEG Extended transfer syntax, as image/jpeg is unable to
+// decode its 12 bit frames.
+type jpegBaselineCodec struct{}
+
+func (jpegBaselineCodec) Decode(fragments [][]byte, l Layout) ([]byte, Layout, error) {
+	img, err := jpeg.Decode(bytes.NewReader(bytes.Join(fragments, nil)))
+	if err != nil {
+		return nil, Layout{}, err
+	}
+	b := img.Bounds()
+	out := Layout{
+		Rows:                b.Dy(),
+		Cols:                b.Dx(),
+		BitsAllocated:       8,
+		PixelRepresentation: l.PixelRepresentation,
+	}
+	var data []byte
+	switch img := img.(type) {
+	case *image.Gray:
+		out.SamplesPerPixel = 1
+		out.PhotometricInterpretation = l.PhotometricInterpretation
+		if !strings.HasPrefix(out.PhotometricInterpretation, "MONOCHROME") {
+			out.PhotometricInterpretation = "MONOCHROME2"
+		}
+		data = make([]byte, 0, out.Rows*out.Cols)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			data = append(data, img.Pix[img.PixOffset(b.Min.X, y):img.PixOffset(b.Max.X, y)]...)
+		}
+	case *image.CMYK:
+		return nil, Layout{}, fmt.Errorf("JPEG frame: unsupported CMYK color model")
+	default:
+		// Color frames are converted to RGB by image/jpeg.
+		out.SamplesPerPixel = 3
+		out.PhotometricInterpretation = "RGB"
+		data = make([]byte, 0, out.Rows*out.Cols*3)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				data = append(data, c.R, c.G, c.B)
+			}
+		}
+	}
+	return data, out, nil
+}
+
+func (jpegBaselineCodec) Encode([]byte, Layout) ([][]byte, error) {
+	return nil, ErrorEncodeUnsupported
+}
+
+// jpegLosslessCodec decodes JPEG Lossless (Process 14) frames.
+type jpegLosslessCodec struct{}
+
+func (jpegLosslessCodec) Decode(fragments [][]byte, l Layout) ([]byte, Layout, error) {
+	img, err := jpeglossless.Decode(bytes.Join(fragments, nil))
+	if err != nil {
+		return nil, Layout{}, err
+	}
+	data, out := nativeSamples(img.Data, img.Width, img.Height, img.Components, img.Precision, l)
+	return data, out, nil
+}
+
+func (jpegLosslessCodec) Encode([]byte, Layout) ([][]byte, error) {
+	return nil, ErrorEncodeUnsupported
+}
+
+// jpeglsCodec decodes and encodes JPEG-LS frames.
+type jpeglsCodec struct {
+	near int
+}
+
+// NewJPEGLS returns a Codec for JPEG-LS frames, which encodes frames with the
+// maximum sample error near (0 for lossless compression). By default, it is
+// registered with near 0 for the JPEG-LS Lossless transfer syntax. The codec
+// registered for the JPEG-LS Near-Lossless transfer syntax only decodes
+// frames, so that dicom.Write does not lossily compress frames unless asked
+// to: dicom.Transcode encodes them with NewJPEGLS and the NEAR of the
+// dicom.NearLossless TranscodeOption, and registering NewJPEGLS(near) for
+// uid.JPEGLSNearLossless makes dicom.Write encode them too.
+func NewJPEGLS(near int) Codec {
+	return jpeglsCodec{near: near}
+}
+
+// jpeglsNearLosslessCodec decodes JPEG-LS Near-Lossless frames.
+type jpeglsNearLosslessCodec struct {
+	jpeglsCodec
+}
+
+func (jpeglsNearLosslessCodec) Encode([]byte, Layout) ([][]byte, error) {
+	return nil, ErrorEncodeUnsupported
+}
+
+func (jpeglsCodec) Decode(fragments [][]byte, l Layout) ([]byte, Layout, error) {
+	img, err := jpegls.Decode(bytes.Join(fragments, nil))
+	if err != nil {
+		return nil, Layout{}, err
+	}
+	data, out := nativeSamples(img.Data, img.Width, img.Height, img.Components, img.Precision, l)
+	return data, out, nil
+}
+
+func (c jpeglsCodec) Encode(data []byte, l Layout) ([][]byte, error) {
+	if l.BitsAllocated != 8 && l.BitsAllocated != 16 {
+		return nil, fmt.Errorf("JPEG-LS frame: unsupported BitsAllocated %d", l.BitsAllocated)
+	}
+	precision := l.BitsStored
+	if precision == 0 || precision > l.BitsAllocated {
+		precision = l.BitsAllocated
+	}
+	img := &jpegls.Image{
+		Width:      l.Cols,
+		Height:     l.Rows,
+		Components: l.SamplesPerPixel,
+		Precision:  precision,
+		Data:       make([]uint16, l.Rows*l.Cols*l.SamplesPerPixel),
+	}
+	bytesPerSample := l.BitsAllocated / 8
+	if len(data) < len(img.Data)*bytesPerSample {
+		return nil, fmt.Errorf("JPEG-LS frame: got %d bytes of samples, want %d", len(data), len(img.Data)*bytesPerSample)
+	}
+	// Only the stored bits are coded, e.g. dropping the sign extension of
+	// signed samples.
+	mask := uint16(1<<uint(precision) - 1)
+	for i := range img.Data {
+		v := uint16(data[i*bytesPerSample])
+		if bytesPerSample > 1 {
+			v |= uint16(data[i*bytesPerSample+1]) << 8
+		}
+		img.Data[i] = v & mask
+	}
+	encoded, err := jpegls.Encode(img, jpegls.Options{Near: c.near, InterleaveMode: jpegls.InterleaveLine})
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{encoded}, nil
+}
+
+// nativeSamples returns the decoded samples of a width by height image with
+// components samples of precision bits per pixel as native samples, and their
+// layout based on l, the layout recorded in the Dataset. BitsAllocated is kept
+// if it is able to hold the samples. Signed samples are sign extended from
+// BitsStored bits, if l records fewer of them than BitsAllocated.
+func nativeSamples(samples []uint16, width, height, components, precision int, l Layout) ([]byte, Layout) {
+	out := l
+	out.Rows, out.Cols, out.SamplesPerPixel = height, width, components
+	if out.BitsAllocated < precision || out.BitsAllocated%8 != 0 {
+		out.BitsAllocated = 8
+		if precision > 8 {
+			out.BitsAllocated = 16
+		}
+	}
+	bytesPerSample := out.BitsAllocated / 8
+	var signBit, extension uint16
+	if l.PixelRepresentation == 1 && l.BitsStored > 0 && l.BitsStored < out.BitsAllocated {
+		signBit = 1 << uint(l.BitsStored-1)
+		extension = ^uint16(1<<uint(l.BitsStored) - 1)
+	}
+	data := make([]byte, len(samples)*bytesPerSample)
+	for i, v := range samples {
+		if v&signBit != 0 {
+			v |= extension
+		}
+		data[i*bytesPerSample] = byte(v)
+		if bytesPerSample > 1 {
+			data[i*bytesPerSample+1] = byte(v >> 8)
+		}
+	}
+	return data, out
+}
+
+// rleCodec decodes and encodes RLE Lossless frames.
+type rleCodec struct{}
+
+func (rleCodec) Decode(fragments [][]byte, l Layout) ([]byte, Layout, error) {
+	data, err := rle.Decode(bytes.Join(fragments, nil), l.Rows, l.Cols, l.SamplesPerPixel, l.BitsAllocated)
+	if err != nil {
+		return nil, Layout{}, err
+	}
+	return data, l, nil
+}
+
+func (rleCodec) Encode(data []byte, l Layout) ([][]byte, error) {
+	encoded, err := rle.Encode(data, l.Rows, l.Cols, l.SamplesPerPixel, l.BitsAllocated)
+	if err != nil {
+		return nil, err
+	}
+	// Each RLE Lossless frame is a single fragment.
+	return [][]byte{encoded}, nil
+}
@@ -0,0 +1,106 @@
+// Package codec provides a registry of Codecs, which decode encapsulated
+// (compressed) frames of PixelData to native samples and encode native samples
+// to encapsulated frames, keyed by transfer syntax UID.
+//
+// Codecs for the JPEG Baseline (decoding only), JPEG Lossless (decoding only),
+// JPEG-LS Lossless, JPEG-LS Near-Lossless (decoding only) and RLE Lossless
+// transfer syntaxes are registered by default. Other codecs (e.g. JPEG
+// Extended or JPEG 2000, including cgo implementations in separate modules) can
+// be registered with Register, typically from an init function:
+//
+//	func init() {
+//		codec.Register("1.2.840.10008.1.2.4.90", myJPEG2000Codec{})
+//	}
+//
+// Registered codecs are used by frame.EncapsulatedFrame to decode frames, by
+// the parser to recognize their transfer syntaxes, and by dicom.Write to encode
+// native PixelData.
+package codec
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrorNotRegistered indicates that no Codec is registered for a transfer
+	// syntax.
+	ErrorNotRegistered = errors.New("no codec registered for transfer syntax")
+	// ErrorEncodeUnsupported indicates that a Codec is only able to decode
+	// frames.
+	ErrorEncodeUnsupported = errors.New("codec does not support encoding")
+)
+
+// Layout describes the samples of a frame.
+type Layout struct {
+	Rows            int
+	Cols            int
+	SamplesPerPixel int
+	BitsAllocated   int
+	// BitsStored is the number of significant bits of each sample, or 0 if
+	// all BitsAllocated bits are significant.
+	BitsStored int
+	// PixelRepresentation is 1 for signed samples, and 0 for unsigned
+	// samples.
+	PixelRepresentation       int
+	PhotometricInterpretation string
+}
+
+// Codec decodes and encodes frames of a transfer syntax.
+//
+// Native samples are held as in native PixelData with the Explicit VR Little
+// Endian transfer syntax: BitsAllocated bits per sample in little endian
+// order, with the samples of each pixel together (i.e. PlanarConfiguration
+// 0).
+type Codec interface {
+	// Decode decodes the fragments of a single frame, whose layout is
+	// described by l as recorded in the Dataset, to native samples. It
+	// returns the layout of the native samples, which may differ from l: e.g.
+	// JPEG Baseline frames of YBR_FULL_422 pixels decode to RGB pixels.
+	Decode(fragments [][]byte, l Layout) ([]byte, Layout, error)
+	// Encode encodes the native samples of a single frame, whose layout is
+	// described by l, to one or more fragments. Codecs that are only able to
+	// decode return ErrorEncodeUnsupported.
+	Encode(data []byte, l Layout) ([][]byte, error)
+}
+
+var (
+	mu     sync.RWMutex
+	codecs = map[string]Codec{}
+)
+
+// Register registers c as the Codec for the transfer syntax transferSyntaxUID,
+// replacing any Codec previously registered for it (including the default
+// ones). Registering a nil Codec removes the registration.
+func Register(transferSyntaxUID string, c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+	if c == nil {
+		delete(codecs, transferSyntaxUID)
+		return
+	}
+	codecs[transferSyntaxUID] = c
+}
+
+// Lookup returns the Codec registered for the transfer syntax
+// transferSyntaxUID, if any.
+func Lookup(transferSyntaxUID string) (Codec, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := codecs[transferSyntaxUID]
+	return c, ok
+}
+
+// TransferSyntaxes returns the transfer syntax UIDs that Codecs are registered
+// for, in sorted order.
+func TransferSyntaxes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	uids := make([]string, 0, len(codecs))
+	for u := range codecs {
+		uids = append(uids, u)
+	}
+	sort.Strings(uids)
+	return uids
+}
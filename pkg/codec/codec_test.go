@@ -0,0 +1,196 @@
+package codec_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suyashkumar/dicom/pkg/codec"
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+type fakeCodec struct{ name string }
+
+func (fakeCodec) Decode([][]byte, codec.Layout) ([]byte, codec.Layout, error) {
+	return nil, codec.Layout{}, nil
+}
+
+func (fakeCodec) Encode([]byte, codec.Layout) ([][]byte, error) { return nil, nil }
+
+func TestRegister(t *testing.T) {
+	const ts = "1.2.3.4"
+	if _, ok := codec.Lookup(ts); ok {
+		t.Fatalf("Lookup(%q) found a codec before Register", ts)
+	}
+
+	codec.Register(ts, fakeCodec{name: "first"})
+	got, ok := codec.Lookup(ts)
+	if !ok || got != (fakeCodec{name: "first"}) {
+		t.Errorf("Lookup(%q) after Register unexpected result. got: %v, %v, want: first codec", ts, got, ok)
+	}
+
+	codec.Register(ts, fakeCodec{name: "second"})
+	got, ok = codec.Lookup(ts)
+	if !ok || got != (fakeCodec{name: "second"}) {
+		t.Errorf("Lookup(%q) after second Register unexpected result. got: %v, %v, want: second codec", ts, got, ok)
+	}
+
+	codec.Register(ts, nil)
+	if _, ok := codec.Lookup(ts); ok {
+		t.Errorf("Lookup(%q) found a codec after Register(%q, nil)", ts, ts)
+	}
+}
+
+func TestTransferSyntaxes(t *testing.T) {
+	want := []string{
+		uid.JPEGBaseline8Bit,
+		uid.JPEGLossless,
+		uid.JPEGLosslessSV1,
+		uid.JPEGLSLossless,
+		uid.JPEGLSNearLossless,
+		uid.RLELossless,
+	}
+	if diff := cmp.Diff(want, codec.TransferSyntaxes()); diff != "" {
+		t.Errorf("TransferSyntaxes unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestJPEGBaseline_Decode(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = 128
+	}
+	buf := bytes.Buffer{}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("jpeg.Encode unexpected error: %v", err)
+	}
+	c, ok := codec.Lookup(uid.JPEGBaseline8Bit)
+	if !ok {
+		t.Fatalf("Lookup(%q) found no codec", uid.JPEGBaseline8Bit)
+	}
+
+	data, l, err := c.Decode([][]byte{buf.Bytes()}, codec.Layout{PhotometricInterpretation: "MONOCHROME1"})
+	if err != nil {
+		t.Fatalf("Decode unexpected error: %v", err)
+	}
+	wantLayout := codec.Layout{Rows: 8, Cols: 8, SamplesPerPixel: 1, BitsAllocated: 8, PhotometricInterpretation: "MONOCHROME1"}
+	if diff := cmp.Diff(wantLayout, l); diff != "" {
+		t.Errorf("Decode unexpected Layout diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(img.Pix, data); diff != "" {
+		t.Errorf("Decode unexpected data diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := c.Encode(data, l); !errors.Is(err, codec.ErrorEncodeUnsupported) {
+		t.Errorf("Encode unexpected error. got: %v, want: %v", err, codec.ErrorEncodeUnsupported)
+	}
+}
+
+func TestRLE_roundTrip(t *testing.T) {
+	c, ok := codec.Lookup(uid.RLELossless)
+	if !ok {
+		t.Fatalf("Lookup(%q) found no codec", uid.RLELossless)
+	}
+	l := codec.Layout{Rows: 2, Cols: 2, SamplesPerPixel: 1, BitsAllocated: 16}
+	data := []byte{1, 0, 1, 0, 0xFF, 0xFF, 0x34, 0x12}
+
+	fragments, err := c.Encode(data, l)
+	if err != nil {
+		t.Fatalf("Encode unexpected error: %v", err)
+	}
+	if len(fragments) != 1 {
+		t.Errorf("Encode returned %d fragments, want 1", len(fragments))
+	}
+	got, gotLayout, err := c.Decode(fragments, l)
+	if err != nil {
+		t.Fatalf("Decode unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(l, gotLayout); diff != "" {
+		t.Errorf("Decode unexpected Layout diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(data, got); diff != "" {
+		t.Errorf("Decode(Encode(data)) unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestJPEGLS_roundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		l    codec.Layout
+		data []byte
+	}{
+		{
+			name: "12 bit grayscale",
+			l:    codec.Layout{Rows: 2, Cols: 2, SamplesPerPixel: 1, BitsAllocated: 16, BitsStored: 12, PhotometricInterpretation: "MONOCHROME2"},
+			data: []byte{1, 0, 1, 0, 0xFF, 0x0F, 0x34, 0x02},
+		},
+		{
+			// Samples are sign extended from BitsStored bits when decoded.
+			name: "signed 12 bit grayscale",
+			l:    codec.Layout{Rows: 2, Cols: 2, SamplesPerPixel: 1, BitsAllocated: 16, BitsStored: 12, PixelRepresentation: 1, PhotometricInterpretation: "MONOCHROME2"},
+			data: []byte{0xFF, 0xFF, 0x00, 0xF8, 0xFF, 0x07, 0x34, 0x02},
+		},
+		{
+			name: "8 bit RGB",
+			l:    codec.Layout{Rows: 1, Cols: 3, SamplesPerPixel: 3, BitsAllocated: 8, PhotometricInterpretation: "RGB"},
+			data: []byte{0, 0, 0, 10, 20, 30, 255, 254, 253},
+		},
+	}
+	c, ok := codec.Lookup(uid.JPEGLSLossless)
+	if !ok {
+		t.Fatalf("Lookup(%q) found no codec", uid.JPEGLSLossless)
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fragments, err := c.Encode(tc.data, tc.l)
+			if err != nil {
+				t.Fatalf("Encode unexpected error: %v", err)
+			}
+			got, gotLayout, err := c.Decode(fragments, tc.l)
+			if err != nil {
+				t.Fatalf("Decode unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.l, gotLayout); diff != "" {
+				t.Errorf("Decode unexpected Layout diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.data, got); diff != "" {
+				t.Errorf("Decode(Encode(data)) unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJPEGLS_near(t *testing.T) {
+	l := codec.Layout{Rows: 4, Cols: 4, SamplesPerPixel: 1, BitsAllocated: 8}
+	data := []byte{0, 0, 90, 74, 68, 50, 43, 205, 64, 145, 145, 145, 100, 145, 145, 145}
+	c := codec.NewJPEGLS(3)
+	fragments, err := c.Encode(data, l)
+	if err != nil {
+		t.Fatalf("Encode unexpected error: %v", err)
+	}
+	got, _, err := c.Decode(fragments, l)
+	if err != nil {
+		t.Fatalf("Decode unexpected error: %v", err)
+	}
+	for i, want := range data {
+		if d := int(got[i]) - int(want); d > 3 || d < -3 {
+			t.Errorf("Decode(Encode(data)) sample %d is %d, want within 3 of %d", i, got[i], want)
+		}
+	}
+
+	// The registered Codec decodes the frames, but does not lossily encode
+	// them.
+	registered, ok := codec.Lookup(uid.JPEGLSNearLossless)
+	if !ok {
+		t.Fatalf("Lookup(%q) found no codec", uid.JPEGLSNearLossless)
+	}
+	if _, _, err := registered.Decode(fragments, l); err != nil {
+		t.Errorf("Decode unexpected error: %v", err)
+	}
+	if _, err := registered.Encode(data, l); !errors.Is(err, codec.ErrorEncodeUnsupported) {
+		t.Errorf("Encode unexpected error. got: %v, want: %v", err, codec.ErrorEncodeUnsupported)
+	}
+}
@@ -2,11 +2,13 @@ package dicomio
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 
 	"github.com/suyashkumar/dicom/pkg/charset"
 	"golang.org/x/text/encoding"
@@ -19,6 +21,15 @@ var (
 	ErrorInsufficientBytesLeft = errors.New("not enough bytes left until buffer limit to complete this operation")
 )
 
+// LimitUnknown can be passed to NewReader when the number of bytes to read is
+// not known ahead of time (for example, when reading an inflated stream). In
+// that case the end of the underlying data is treated as the outermost limit.
+const LimitUnknown int64 = -1
+
+// MaxPreallocation is the most memory ReadFull allocates ahead of reading data
+// when the length of the underlying data is unknown.
+const MaxPreallocation = 1 << 20
+
 // Reader provides common functionality for reading underlying DICOM data.
 type Reader interface {
 	io.Reader
@@ -32,6 +43,10 @@ type Reader interface {
 	ReadInt16() (int16, error)
 	// ReadInt32 reads a int32 from the underlying reader.
 	ReadInt32() (int32, error)
+	// ReadUInt64 reads a uint64 from the underlying reader.
+	ReadUInt64() (uint64, error)
+	// ReadInt64 reads a int64 from the underlying reader.
+	ReadInt64() (int64, error)
 	// ReadFloat32 reads a float32 from the underlying reader.
 	ReadFloat32() (float32, error)
 	// ReadFloat64 reads a float32 from the underlying reader.
@@ -58,6 +73,13 @@ type Reader interface {
 	// BytesLeftUntilLimit returns the number of bytes remaining until we reach
 	// the currently set limit position.
 	BytesLeftUntilLimit() int64
+	// BytesRead returns the number of bytes read so far by this Reader, which
+	// is the current offset into the underlying data.
+	BytesRead() int64
+	// HasKnownLength indicates if the length of the underlying data is known.
+	// If it is not (see LimitUnknown), BytesLeftUntilLimit may be far more
+	// than the number of bytes actually left.
+	HasKnownLength() bool
 	// SetTransferSyntax sets the byte order and whether the current transfer
 	// syntax is implicit or not.
 	SetTransferSyntax(bo binary.ByteOrder, implicit bool)
@@ -77,23 +99,34 @@ type reader struct {
 	limit      int64
 	bytesRead  int64
 	limitStack []int64
+	// unknownLength indicates that the outermost limit is the end of the
+	// underlying data, rather than a known number of bytes.
+	unknownLength bool
 	// cs represents the CodingSystem to use when reading the string. If a
 	// particular encoding.Decoder within this CodingSystem is nil, assume
 	// ASCII.
 	cs charset.CodingSystem
 }
 
-// NewReader creates and returns a new dicomio.Reader.
+// NewReader creates and returns a new dicomio.Reader. If limit is
+// LimitUnknown, the Reader reads until the underlying data is exhausted.
 func NewReader(in *bufio.Reader, bo binary.ByteOrder, limit int64) (Reader, error) {
 	if limit == 0 {
 		limit = int64(in.Size())
 	}
 
+	unknownLength := false
+	if limit == LimitUnknown {
+		limit = math.MaxInt64
+		unknownLength = true
+	}
+
 	return &reader{
-		in:        in,
-		bo:        bo,
-		limit:     limit,
-		bytesRead: 0,
+		in:            in,
+		bo:            bo,
+		limit:         limit,
+		bytesRead:     0,
+		unknownLength: unknownLength,
 	}, nil
 }
 
@@ -101,6 +134,36 @@ func (r *reader) BytesLeftUntilLimit() int64 {
 	return r.limit - r.bytesRead
 }
 
+func (r *reader) BytesRead() int64 {
+	return r.bytesRead
+}
+
+func (r *reader) HasKnownLength() bool {
+	return !r.unknownLength
+}
+
+// ReadFull reads the next n bytes from r. If the length of the data underlying
+// r is unknown, n may be far more than is left, so the bytes are read into a
+// buffer that grows as they arrive (from at most MaxPreallocation bytes)
+// rather than into one allocated up front.
+func ReadFull(r Reader, n int64) ([]byte, error) {
+	if r.HasKnownLength() || n <= MaxPreallocation {
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, MaxPreallocation))
+	if _, err := io.CopyN(buf, r, n); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (r *reader) Read(p []byte) (int, error) {
 	// Check if we've hit the limit
 	if r.BytesLeftUntilLimit() <= 0 {
@@ -152,6 +215,18 @@ func (r *reader) ReadInt32() (int32, error) {
 	return out, err
 }
 
+func (r *reader) ReadUInt64() (uint64, error) {
+	var out uint64
+	err := binary.Read(r, r.bo, &out)
+	return out, err
+}
+
+func (r *reader) ReadInt64() (int64, error) {
+	var out int64
+	err := binary.Read(r, r.bo, &out)
+	return out, err
+}
+
 func (r *reader) ReadFloat32() (float32, error) {
 	var out float32
 	err := binary.Read(r, r.bo, &out)
@@ -180,8 +255,7 @@ func internalReadString(data []byte, d *encoding.Decoder) (string, error) {
 }
 
 func (r *reader) ReadString(n uint32) (string, error) {
-	data := make([]byte, n)
-	_, err := io.ReadFull(r, data)
+	data, err := ReadFull(r, int64(n))
 	if err != nil {
 		return "", err
 	}
@@ -222,7 +296,16 @@ func (r *reader) PopLimit() {
 }
 
 func (r *reader) IsLimitExhausted() bool {
-	return r.BytesLeftUntilLimit() <= 0
+	if r.BytesLeftUntilLimit() <= 0 {
+		return true
+	}
+	if r.unknownLength && len(r.limitStack) == 0 {
+		// The outermost limit is the end of the underlying data, so check if
+		// there is anything left to read.
+		_, err := r.in.Peek(1)
+		return err == io.EOF
+	}
+	return false
 }
 
 func (r *reader) SetTransferSyntax(bo binary.ByteOrder, implicit bool) {
@@ -0,0 +1,155 @@
+package frame
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrorUnsupportedBitsAllocated indicates that there is no Buffer type for
+// samples of the requested number of bits.
+var ErrorUnsupportedBitsAllocated = errors.New("unsupported BitsAllocated")
+
+// Buffer holds the samples of a NativeFrame contiguously, in a slice of a
+// compact type. Samples are held pixel by pixel, with the samples of each
+// pixel together.
+type Buffer interface {
+	// Len returns the number of samples in the Buffer.
+	Len() int
+	// At returns sample i as an int.
+	At(i int) int
+	// Set sets sample i to v, converted to the type of the Buffer.
+	Set(i, v int)
+	// SampleSize returns the size in bytes of each sample.
+	SampleSize() int
+	// AppendBytes appends the samples to dst in byte order bo, and returns
+	// the extended slice.
+	AppendBytes(dst []byte, bo binary.ByteOrder) []byte
+}
+
+// Uint8Buffer is a Buffer of 8 bit samples.
+type Uint8Buffer []uint8
+
+func (b Uint8Buffer) Len() int        { return len(b) }
+func (b Uint8Buffer) At(i int) int    { return int(b[i]) }
+func (b Uint8Buffer) Set(i, v int)    { b[i] = uint8(v) }
+func (b Uint8Buffer) SampleSize() int { return 1 }
+func (b Uint8Buffer) AppendBytes(dst []byte, _ binary.ByteOrder) []byte {
+	return append(dst, b...)
+}
+
+// Uint16Buffer is a Buffer of unsigned 16 bit samples.
+type Uint16Buffer []uint16
+
+func (b Uint16Buffer) Len() int        { return len(b) }
+func (b Uint16Buffer) At(i int) int    { return int(b[i]) }
+func (b Uint16Buffer) Set(i, v int)    { b[i] = uint16(v) }
+func (b Uint16Buffer) SampleSize() int { return 2 }
+func (b Uint16Buffer) AppendBytes(dst []byte, bo binary.ByteOrder) []byte {
+	dst, out := grow(dst, 2*len(b))
+	for i, v := range b {
+		bo.PutUint16(out[2*i:], v)
+	}
+	return dst
+}
+
+// Int16Buffer is a Buffer of signed 16 bit samples. It is not returned by
+// NewBuffer or DecodeBuffer, but may be used to build frames of signed samples.
+type Int16Buffer []int16
+
+func (b Int16Buffer) Len() int        { return len(b) }
+func (b Int16Buffer) At(i int) int    { return int(b[i]) }
+func (b Int16Buffer) Set(i, v int)    { b[i] = int16(v) }
+func (b Int16Buffer) SampleSize() int { return 2 }
+func (b Int16Buffer) AppendBytes(dst []byte, bo binary.ByteOrder) []byte {
+	dst, out := grow(dst, 2*len(b))
+	for i, v := range b {
+		bo.PutUint16(out[2*i:], uint16(v))
+	}
+	return dst
+}
+
+// Uint32Buffer is a Buffer of unsigned 32 bit samples.
+type Uint32Buffer []uint32
+
+func (b Uint32Buffer) Len() int        { return len(b) }
+func (b Uint32Buffer) At(i int) int    { return int(b[i]) }
+func (b Uint32Buffer) Set(i, v int)    { b[i] = uint32(v) }
+func (b Uint32Buffer) SampleSize() int { return 4 }
+func (b Uint32Buffer) AppendBytes(dst []byte, bo binary.ByteOrder) []byte {
+	dst, out := grow(dst, 4*len(b))
+	for i, v := range b {
+		bo.PutUint32(out[4*i:], v)
+	}
+	return dst
+}
+
+// Float32Buffer is a Buffer of 32 bit floating point samples. At truncates
+// samples to ints.
+type Float32Buffer []float32
+
+func (b Float32Buffer) Len() int        { return len(b) }
+func (b Float32Buffer) At(i int) int    { return int(b[i]) }
+func (b Float32Buffer) Set(i, v int)    { b[i] = float32(v) }
+func (b Float32Buffer) SampleSize() int { return 4 }
+func (b Float32Buffer) AppendBytes(dst []byte, bo binary.ByteOrder) []byte {
+	dst, out := grow(dst, 4*len(b))
+	for i, v := range b {
+		bo.PutUint32(out[4*i:], math.Float32bits(v))
+	}
+	return dst
+}
+
+// grow extends dst by n bytes, returning the extended slice and the n new
+// bytes.
+func grow(dst []byte, n int) ([]byte, []byte) {
+	l := len(dst)
+	if cap(dst)-l < n {
+		grown := make([]byte, l, l+n)
+		copy(grown, dst)
+		dst = grown
+	}
+	dst = dst[:l+n]
+	return dst, dst[l:]
+}
+
+// NewBuffer returns a Buffer of n zero samples, of the type used for integer
+// samples of bitsAllocated bits: Uint8Buffer, Uint16Buffer or Uint32Buffer.
+// Samples are held as their unsigned stored bit pattern, whatever their
+// PixelRepresentation, as [][]int Data always has; signed samples can be
+// interpreted with pixel.Format.StoredValue.
+func NewBuffer(n, bitsAllocated int) (Buffer, error) {
+	switch bitsAllocated {
+	case 8:
+		return make(Uint8Buffer, n), nil
+	case 16:
+		return make(Uint16Buffer, n), nil
+	case 32:
+		return make(Uint32Buffer, n), nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrorUnsupportedBitsAllocated, bitsAllocated)
+}
+
+// DecodeBuffer returns a Buffer holding the integer samples of bitsAllocated
+// bits encoded in b in byte order bo, of the type returned by NewBuffer. For 8
+// bit samples, the returned Buffer shares b.
+func DecodeBuffer(b []byte, bo binary.ByteOrder, bitsAllocated int) (Buffer, error) {
+	switch bitsAllocated {
+	case 8:
+		return Uint8Buffer(b), nil
+	case 16:
+		buf := make(Uint16Buffer, len(b)/2)
+		for i := range buf {
+			buf[i] = bo.Uint16(b[2*i:])
+		}
+		return buf, nil
+	case 32:
+		buf := make(Uint32Buffer, len(b)/4)
+		for i := range buf {
+			buf[i] = bo.Uint32(b[4*i:])
+		}
+		return buf, nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrorUnsupportedBitsAllocated, bitsAllocated)
+}
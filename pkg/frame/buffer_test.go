@@ -0,0 +1,106 @@
+package frame_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suyashkumar/dicom/pkg/frame"
+)
+
+func TestDecodeBuffer(t *testing.T) {
+	cases := []struct {
+		name          string
+		data          []byte
+		bo            binary.ByteOrder
+		bitsAllocated int
+		want          frame.Buffer
+	}{
+		{
+			name:          "8 bits",
+			data:          []byte{0x01, 0xFF},
+			bo:            binary.LittleEndian,
+			bitsAllocated: 8,
+			want:          frame.Uint8Buffer{0x01, 0xFF},
+		},
+		{
+			name:          "16 bits, big endian",
+			data:          []byte{0x01, 0x02, 0xFF, 0xFE},
+			bo:            binary.BigEndian,
+			bitsAllocated: 16,
+			want:          frame.Uint16Buffer{0x0102, 0xFFFE},
+		},
+		{
+			name:          "16 bits, signed samples are held unsigned",
+			data:          []byte{0x00, 0xFC, 0x00, 0x04},
+			bo:            binary.LittleEndian,
+			bitsAllocated: 16,
+			want:          frame.Uint16Buffer{0xFC00, 1024},
+		},
+		{
+			name:          "32 bits",
+			data:          []byte{0x01, 0x02, 0x03, 0x04},
+			bo:            binary.LittleEndian,
+			bitsAllocated: 32,
+			want:          frame.Uint32Buffer{0x04030201},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := frame.DecodeBuffer(tc.data, tc.bo, tc.bitsAllocated)
+			if err != nil {
+				t.Fatalf("DecodeBuffer unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("DecodeBuffer unexpected Buffer. diff: %v", diff)
+			}
+			if diff := cmp.Diff(tc.data, got.AppendBytes(nil, tc.bo)); diff != "" {
+				t.Errorf("AppendBytes did not return the decoded bytes. diff: %v", diff)
+			}
+		})
+	}
+
+	if _, err := frame.DecodeBuffer([]byte{0x01}, binary.LittleEndian, 1); !errors.Is(err, frame.ErrorUnsupportedBitsAllocated) {
+		t.Errorf("DecodeBuffer(1 bit) unexpected error. got: %v, want: %v", err, frame.ErrorUnsupportedBitsAllocated)
+	}
+}
+
+func TestBuffer_Set(t *testing.T) {
+	for _, b := range []frame.Buffer{
+		make(frame.Uint8Buffer, 2),
+		make(frame.Uint16Buffer, 2),
+		make(frame.Int16Buffer, 2),
+		make(frame.Uint32Buffer, 2),
+		make(frame.Float32Buffer, 2),
+	} {
+		b.Set(1, 100)
+		if got := b.At(1); got != 100 {
+			t.Errorf("%T.At(1) after Set(1, 100) = %d, want 100", b, got)
+		}
+		if got := b.At(0); got != 0 {
+			t.Errorf("%T.At(0) = %d, want 0", b, got)
+		}
+	}
+}
+
+func TestNativeFrame_accessors(t *testing.T) {
+	want := [][]int{{1, 2, 3}, {4, 5, 6}}
+	for _, nf := range []frame.NativeFrame{
+		{Rows: 1, Cols: 2, BitsPerSample: 8, Samples: frame.Uint8Buffer{1, 2, 3, 4, 5, 6}, SamplesPerPixel: 3},
+		{Rows: 1, Cols: 2, BitsPerSample: 8, Data: [][]int{{1, 2, 3}, {4, 5, 6}}},
+	} {
+		if got := nf.NumPixels(); got != 2 {
+			t.Errorf("NumPixels() = %d, want 2", got)
+		}
+		if got := nf.NumSamplesPerPixel(); got != 3 {
+			t.Errorf("NumSamplesPerPixel() = %d, want 3", got)
+		}
+		if got := nf.At(1, 2); got != 6 {
+			t.Errorf("At(1, 2) = %d, want 6", got)
+		}
+		if diff := cmp.Diff(want, nf.Ints()); diff != "" {
+			t.Errorf("Ints() unexpected pixels. diff: %v", diff)
+		}
+	}
+}
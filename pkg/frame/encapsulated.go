@@ -2,14 +2,34 @@ package frame
 
 import (
 	"bytes"
+	"encoding/binary"
+	"fmt"
 	"image"
 	"image/jpeg"
+
+	"github.com/suyashkumar/dicom/pkg/codec"
 )
 
 // EncapsulatedFrame represents an encapsulated image frame
 type EncapsulatedFrame struct {
-	// Data is a collection of bytes representing a JPEG encoded image frame
+	// Data is a collection of bytes representing an encoded (e.g. JPEG) image
+	// frame
 	Data []byte
+	// TransferSyntaxUID is the transfer syntax Data is encoded with, if known.
+	// It selects the codec.Codec GetNativeFrame and GetImage decode Data with.
+	TransferSyntaxUID string
+	// Rows, Cols, SamplesPerPixel, BitsAllocated, BitsStored,
+	// PixelRepresentation and PhotometricInterpretation describe the decoded
+	// frame. They are needed to decode RLE Lossless frames, but other frames
+	// describe their own layout, and BitsAllocated and BitsStored may be 0 if
+	// unknown.
+	Rows                      int
+	Cols                      int
+	SamplesPerPixel           int
+	BitsAllocated             int
+	BitsStored                int
+	PixelRepresentation       int
+	PhotometricInterpretation string
 }
 
 // IsEncapsulated indicates if the frame is encapsulated or not.
@@ -20,17 +40,115 @@ func (e *EncapsulatedFrame) GetEncapsulatedFrame() (*EncapsulatedFrame, error) {
 	return e, nil
 }
 
-// GetNativeFrame returns ErrorFrameTypeNotPresent, because this struct does
-// not hold a NativeFrame.
+// GetNativeFrame decodes Data into a NativeFrame with the codec.Codec
+// registered for TransferSyntaxUID. Data is decoded on each call. If no Codec
+// is registered for TransferSyntaxUID, ErrorFrameTypeNotPresent is returned.
 func (e *EncapsulatedFrame) GetNativeFrame() (*NativeFrame, error) {
-	return nil, ErrorFrameTypeNotPresent
+	c, ok := codec.Lookup(e.TransferSyntaxUID)
+	if !ok {
+		return nil, ErrorFrameTypeNotPresent
+	}
+	data, l, err := c.Decode([][]byte{e.Data}, e.layout())
+	if err != nil {
+		return nil, err
+	}
+	samples, err := DecodeBuffer(data, binary.LittleEndian, l.BitsAllocated)
+	if err != nil {
+		return nil, fmt.Errorf("decoded frame: %w", err)
+	}
+	return &NativeFrame{
+		Rows:                      l.Rows,
+		Cols:                      l.Cols,
+		BitsPerSample:             l.BitsAllocated,
+		Samples:                   samples,
+		SamplesPerPixel:           l.SamplesPerPixel,
+		PhotometricInterpretation: l.PhotometricInterpretation,
+	}, nil
 }
 
-// GetImage returns a Go image.Image from the underlying frame.
+// GetImage returns a Go image.Image from the underlying frame. Data is decoded
+// with the codec.Codec registered for TransferSyntaxUID, or as a JPEG image if
+// there is none.
 func (e *EncapsulatedFrame) GetImage() (image.Image, error) {
+	if _, ok := codec.Lookup(e.TransferSyntaxUID); ok {
+		n, err := e.GetNativeFrame()
+		if err != nil {
+			return nil, err
+		}
+		return n.GetImage()
+	}
 	// Decoding the data to only re-encode it as a JPEG *without* modifications
 	// is very inefficient. If all you want to do is write the JPEG to disk,
 	// you should fetch the EncapsulatedFrame and grab the []byte Data from
 	// there.
 	return jpeg.Decode(bytes.NewReader(e.Data))
 }
+
+// layout returns the codec.Layout of the decoded frame, as described by e.
+func (e *EncapsulatedFrame) layout() codec.Layout {
+	return codec.Layout{
+		Rows:                      e.Rows,
+		Cols:                      e.Cols,
+		SamplesPerPixel:           e.SamplesPerPixel,
+		BitsAllocated:             e.BitsAllocated,
+		BitsStored:                e.BitsStored,
+		PixelRepresentation:       e.PixelRepresentation,
+		PhotometricInterpretation: e.PhotometricInterpretation,
+	}
+}
+
+// Encode returns n encoded with the transfer syntax transferSyntaxUID, using
+// the codec.Codec registered for it. The samples of n are encoded with
+// BitsPerSample bits, of which bitsStored are significant (0 if all of them
+// are), and are signed if pixelRepresentation is 1, as recorded by the
+// PixelRepresentation and BitsStored elements of the Dataset. The fragments
+// returned by the Codec are joined in Data, which is padded to an even length
+// as required for encapsulated fragments.
+func Encode(n *NativeFrame, transferSyntaxUID string, pixelRepresentation, bitsStored int) (*EncapsulatedFrame, error) {
+	c, ok := codec.Lookup(transferSyntaxUID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", codec.ErrorNotRegistered, transferSyntaxUID)
+	}
+	return EncodeWith(n, c, transferSyntaxUID, pixelRepresentation, bitsStored)
+}
+
+// EncodeWith is like Encode, but encodes n with the codec.Codec c instead of
+// the one registered for transferSyntaxUID, e.g. a JPEG-LS Codec with a chosen
+// maximum sample error.
+func EncodeWith(n *NativeFrame, c codec.Codec, transferSyntaxUID string, pixelRepresentation, bitsStored int) (*EncapsulatedFrame, error) {
+	numPixels, samplesPerPixel := n.NumPixels(), n.NumSamplesPerPixel()
+	if numPixels != n.Rows*n.Cols {
+		return nil, fmt.Errorf("frame of %dx%d pixels holds %d pixels", n.Cols, n.Rows, numPixels)
+	}
+	samples := n.Samples
+	if samples == nil || samples.SampleSize()*8 != n.BitsPerSample {
+		var err error
+		if samples, err = NewBuffer(numPixels*samplesPerPixel, n.BitsPerSample); err != nil {
+			return nil, err
+		}
+		for pixel := 0; pixel < numPixels; pixel++ {
+			for s := 0; s < samplesPerPixel; s++ {
+				samples.Set(pixel*samplesPerPixel+s, n.At(pixel, s))
+			}
+		}
+	}
+	e := &EncapsulatedFrame{
+		TransferSyntaxUID:         transferSyntaxUID,
+		Rows:                      n.Rows,
+		Cols:                      n.Cols,
+		SamplesPerPixel:           samplesPerPixel,
+		BitsAllocated:             n.BitsPerSample,
+		BitsStored:                bitsStored,
+		PixelRepresentation:       pixelRepresentation,
+		PhotometricInterpretation: n.PhotometricInterpretation,
+	}
+	fragments, err := c.Encode(samples.AppendBytes(nil, binary.LittleEndian), e.layout())
+	if err != nil {
+		return nil, err
+	}
+	e.Data = bytes.Join(fragments, nil)
+	if len(e.Data)%2 != 0 {
+		e.Data = append(e.Data, 0)
+	}
+	return e, nil
+}
@@ -0,0 +1,205 @@
+package frame_test
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suyashkumar/dicom/pkg/codec"
+	"github.com/suyashkumar/dicom/pkg/frame"
+	"github.com/suyashkumar/dicom/pkg/rle"
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+// losslessJPEG is a 3x1 8 bit lossless JPEG image with samples 128, 130 and
+// 129.
+var losslessJPEG = []byte{
+	0xFF, 0xD8,
+	0xFF, 0xC3, 0x00, 0x0B, 0x08, 0x00, 0x01, 0x00, 0x03, 0x01, 0x01, 0x11, 0x00,
+	0xFF, 0xC4, 0x00, 0x16, 0x00, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x00, 0x01, 0x02,
+	0xFF, 0xDA, 0x00, 0x08, 0x01, 0x01, 0x00, 0x01, 0x00, 0x00,
+	0x6A, 0x7F,
+	0xFF, 0xD9,
+}
+
+func TestEncapsulatedFrame_GetNativeFrame(t *testing.T) {
+	cases := []struct {
+		name              string
+		encapsulatedFrame frame.EncapsulatedFrame
+		want              *frame.NativeFrame
+	}{
+		{
+			name: "JPEG Lossless SV1",
+			encapsulatedFrame: frame.EncapsulatedFrame{
+				Data: losslessJPEG, TransferSyntaxUID: uid.JPEGLosslessSV1, BitsAllocated: 8, PhotometricInterpretation: "MONOCHROME2",
+			},
+			want: &frame.NativeFrame{
+				Rows: 1, Cols: 3, BitsPerSample: 8, Samples: frame.Uint8Buffer{128, 130, 129}, SamplesPerPixel: 1,
+				PhotometricInterpretation: "MONOCHROME2",
+			},
+		},
+		{
+			name: "JPEG Lossless, signed 16 bits",
+			encapsulatedFrame: frame.EncapsulatedFrame{
+				Data: losslessJPEG, TransferSyntaxUID: uid.JPEGLossless, BitsAllocated: 16, PixelRepresentation: 1,
+			},
+			want: &frame.NativeFrame{
+				Rows: 1, Cols: 3, BitsPerSample: 16, Samples: frame.Uint16Buffer{128, 130, 129}, SamplesPerPixel: 1,
+			},
+		},
+		{
+			name: "BitsAllocated unknown",
+			encapsulatedFrame: frame.EncapsulatedFrame{
+				Data: losslessJPEG, TransferSyntaxUID: uid.JPEGLosslessSV1,
+			},
+			want: &frame.NativeFrame{
+				Rows: 1, Cols: 3, BitsPerSample: 8, Samples: frame.Uint8Buffer{128, 130, 129}, SamplesPerPixel: 1,
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.encapsulatedFrame.GetNativeFrame()
+			if err != nil {
+				t.Fatalf("GetNativeFrame unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("GetNativeFrame unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEncapsulatedFrame_GetNativeFrame_notPresent(t *testing.T) {
+	f := frame.EncapsulatedFrame{Data: losslessJPEG, TransferSyntaxUID: "1.2.840.10008.1.2.4.100"}
+	if _, err := f.GetNativeFrame(); !errors.Is(err, frame.ErrorFrameTypeNotPresent) {
+		t.Errorf("GetNativeFrame unexpected error. got: %v, want: %v", err, frame.ErrorFrameTypeNotPresent)
+	}
+}
+
+func TestEncapsulatedFrame_GetImage_lossless(t *testing.T) {
+	f := frame.EncapsulatedFrame{Data: losslessJPEG, TransferSyntaxUID: uid.JPEGLosslessSV1, BitsAllocated: 8}
+	img, err := f.GetImage()
+	if err != nil {
+		t.Fatalf("GetImage unexpected error: %v", err)
+	}
+	gray, ok := img.(*image.Gray16)
+	if !ok {
+		t.Fatalf("GetImage returned %T, want *image.Gray16", img)
+	}
+	for x, want := range []uint16{128, 130, 129} {
+		if got := gray.Gray16At(x, 0).Y; got != want {
+			t.Errorf("GetImage unexpected value at (%d, 0). got: %d, want: %d", x, got, want)
+		}
+	}
+}
+
+func TestEncode_rle(t *testing.T) {
+	cases := []struct {
+		name                string
+		nativeFrame         frame.NativeFrame
+		pixelRepresentation int
+		want                *frame.NativeFrame
+	}{
+		{
+			name: "RGB",
+			nativeFrame: frame.NativeFrame{
+				Rows: 2, Cols: 2, BitsPerSample: 8, PhotometricInterpretation: "RGB",
+				Samples: frame.Uint8Buffer{1, 2, 3, 1, 2, 3, 1, 2, 3, 4, 5, 6}, SamplesPerPixel: 3,
+			},
+		},
+		{
+			name: "32 bits",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 3, BitsPerSample: 32,
+				Samples: frame.Uint32Buffer{0x01020304, 0x01020304, 0xFFFFFFFF}, SamplesPerPixel: 1,
+			},
+		},
+		{
+			name: "signed 16 bits",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 3, BitsPerSample: 16, PhotometricInterpretation: "MONOCHROME2",
+				Samples: frame.Int16Buffer{-1, 0, 1000}, SamplesPerPixel: 1,
+			},
+			pixelRepresentation: 1,
+			want: &frame.NativeFrame{
+				Rows: 1, Cols: 3, BitsPerSample: 16, PhotometricInterpretation: "MONOCHROME2",
+				Samples: frame.Uint16Buffer{0xFFFF, 0, 1000}, SamplesPerPixel: 1,
+			},
+		},
+		{
+			name: "signed [][]int Data",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 2, BitsPerSample: 16, PhotometricInterpretation: "MONOCHROME2",
+				Data: [][]int{{-1000}, {2000}},
+			},
+			pixelRepresentation: 1,
+			want: &frame.NativeFrame{
+				Rows: 1, Cols: 2, BitsPerSample: 16, PhotometricInterpretation: "MONOCHROME2",
+				Samples: frame.Uint16Buffer{0xFC18, 2000}, SamplesPerPixel: 1,
+			},
+		},
+		{
+			name: "[][]int Data",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 2, BitsPerSample: 16, PhotometricInterpretation: "MONOCHROME2",
+				Data: [][]int{{1000}, {2000}},
+			},
+			want: &frame.NativeFrame{
+				Rows: 1, Cols: 2, BitsPerSample: 16, PhotometricInterpretation: "MONOCHROME2",
+				Samples: frame.Uint16Buffer{1000, 2000}, SamplesPerPixel: 1,
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := frame.Encode(&tc.nativeFrame, uid.RLELossless, tc.pixelRepresentation, 0)
+			if err != nil {
+				t.Fatalf("Encode unexpected error: %v", err)
+			}
+			if e.TransferSyntaxUID != uid.RLELossless {
+				t.Errorf("Encode unexpected TransferSyntaxUID. got: %q, want: %q", e.TransferSyntaxUID, uid.RLELossless)
+			}
+			got, err := e.GetNativeFrame()
+			if err != nil {
+				t.Fatalf("GetNativeFrame unexpected error: %v", err)
+			}
+			want := tc.want
+			if want == nil {
+				want = &tc.nativeFrame
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("GetNativeFrame(Encode(frame)) unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEncode_notRegistered(t *testing.T) {
+	n := frame.NativeFrame{Rows: 1, Cols: 2, BitsPerSample: 8, Samples: frame.Uint8Buffer{1, 2}, SamplesPerPixel: 1}
+	if _, err := frame.Encode(&n, "1.2.840.10008.1.2.4.100", 0, 0); !errors.Is(err, codec.ErrorNotRegistered) {
+		t.Errorf("Encode unexpected error. got: %v, want: %v", err, codec.ErrorNotRegistered)
+	}
+	if _, err := frame.Encode(&n, uid.JPEGBaseline8Bit, 0, 0); !errors.Is(err, codec.ErrorEncodeUnsupported) {
+		t.Errorf("Encode unexpected error. got: %v, want: %v", err, codec.ErrorEncodeUnsupported)
+	}
+}
+
+func TestEncapsulatedFrame_GetNativeFrame_rleErrors(t *testing.T) {
+	e, err := frame.Encode(&frame.NativeFrame{
+		Rows: 1, Cols: 2, BitsPerSample: 8, Samples: frame.Uint8Buffer{1, 2}, SamplesPerPixel: 1,
+	}, uid.RLELossless, 0, 0)
+	if err != nil {
+		t.Fatalf("Encode unexpected error: %v", err)
+	}
+	unknownLayout := frame.EncapsulatedFrame{Data: e.Data, TransferSyntaxUID: uid.RLELossless}
+	if _, err := unknownLayout.GetNativeFrame(); !errors.Is(err, rle.ErrorUnsupportedLayout) {
+		t.Errorf("GetNativeFrame unexpected error. got: %v, want: %v", err, rle.ErrorUnsupportedLayout)
+	}
+	wrongLayout := *e
+	wrongLayout.SamplesPerPixel = 3
+	if _, err := wrongLayout.GetNativeFrame(); !errors.Is(err, rle.ErrorInvalidData) {
+		t.Errorf("GetNativeFrame unexpected error. got: %v, want: %v", err, rle.ErrorInvalidData)
+	}
+}
@@ -1,17 +1,47 @@
 package frame
 
 import (
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
 )
 
+// ErrorUnsupportedPhotometricInterpretation indicates that GetImage does not
+// support the PhotometricInterpretation of a NativeFrame.
+var ErrorUnsupportedPhotometricInterpretation = errors.New("unsupported PhotometricInterpretation")
+
 // NativeFrame represents a native image frame
 type NativeFrame struct {
-	// Data is a slice of pixels, where each pixel can have multiple values
+	// Data is a slice of pixels, where each pixel can have multiple values.
+	// A frame holds its samples in either Data or Samples: frames read from
+	// a DICOM hold them in Data, unless they are read with the
+	// dicom.CompactNativeFrames Option, which holds them in Samples instead.
+	// Data is ignored if Samples is set, so prefer At or Ints to access the
+	// samples of any frame.
 	Data          [][]int
 	Rows          int
 	Cols          int
 	BitsPerSample int
+	// Samples holds the samples of all pixels contiguously, in row-major
+	// order with the samples of each pixel together: sample s of the pixel
+	// at row, col is at index (row*Cols+col)*SamplesPerPixel+s. Samples read
+	// or decoded from a DICOM are held unsigned, as described by NewBuffer.
+	Samples Buffer
+	// SamplesPerPixel is the number of samples of each pixel in Samples.
+	SamplesPerPixel int
+	// PhotometricInterpretation describes how the samples of each pixel are
+	// interpreted, e.g. MONOCHROME2, RGB, YBR_FULL or PALETTE COLOR. If empty,
+	// pixels with three samples are treated as RGB, and other pixels as
+	// grayscale.
+	PhotometricInterpretation string
+	// PlanarConfiguration describes how samples were laid out in the encoded
+	// frame: 0 if the samples of each pixel are interleaved, or 1 if each
+	// sample is held in a separate plane. Data always holds the samples of
+	// each pixel together.
+	PlanarConfiguration int
+	// Palette is the palette color lookup table of PALETTE COLOR frames.
+	Palette *PaletteLUT
 }
 
 // IsEncapsulated indicates if the frame is encapsulated or not.
@@ -29,13 +59,177 @@ func (n *NativeFrame) GetEncapsulatedFrame() (*EncapsulatedFrame, error) {
 	return nil, ErrorFrameTypeNotPresent
 }
 
+// NumPixels returns the number of pixels in the frame.
+func (n *NativeFrame) NumPixels() int {
+	if n.Samples != nil {
+		if n.SamplesPerPixel <= 0 {
+			return 0
+		}
+		return n.Samples.Len() / n.SamplesPerPixel
+	}
+	return len(n.Data)
+}
+
+// NumSamplesPerPixel returns the number of samples of each pixel in the frame.
+func (n *NativeFrame) NumSamplesPerPixel() int {
+	if n.Samples != nil {
+		return n.SamplesPerPixel
+	}
+	if len(n.Data) == 0 {
+		return 0
+	}
+	return len(n.Data[0])
+}
+
+// At returns sample s of pixel i, where pixels are numbered in row-major
+// order.
+func (n *NativeFrame) At(i, s int) int {
+	if n.Samples != nil {
+		return n.Samples.At(i*n.SamplesPerPixel + s)
+	}
+	return n.Data[i][s]
+}
+
+// Ints returns the samples of the frame as a slice of pixels, each holding
+// its samples. It returns Data for frames that do not hold Samples. This
+// representation uses much more memory than Samples, and is provided for
+// compatibility.
+func (n *NativeFrame) Ints() [][]int {
+	if n.Samples == nil {
+		return n.Data
+	}
+	spp := n.SamplesPerPixel
+	pixels := make([][]int, n.NumPixels())
+	buf := make([]int, len(pixels)*spp)
+	for i := range buf {
+		buf[i] = n.Samples.At(i)
+	}
+	for i := range pixels {
+		pixels[i] = buf[i*spp : (i+1)*spp]
+	}
+	return pixels
+}
+
 // GetImage returns an image.Image representation the frame, using default
 // processing. This default processing is basic at the moment, and does not
 // autoscale pixel values or use window width or level info.
+//
+// Grayscale frames are returned as an *image.Gray16. Color frames are
+// converted to RGB, and returned as an *image.RGBA if they have 8 bits per
+// sample (or 8 bits per palette entry), and as an *image.RGBA64 otherwise.
 func (n *NativeFrame) GetImage() (image.Image, error) {
+	switch n.PhotometricInterpretation {
+	case "RGB":
+		return n.colorImage(n.BitsPerSample, func(p []int) (int, int, int) { return p[0], p[1], p[2] })
+	case "YBR_FULL", "YBR_FULL_422":
+		// YBR_FULL_422 frames hold the (repeated) chrominance samples of each
+		// pixel, once read.
+		offset := 1 << uint(n.BitsPerSample-1)
+		max := 1<<uint(n.BitsPerSample) - 1
+		return n.colorImage(n.BitsPerSample, func(p []int) (int, int, int) {
+			return ybrToRGB(p[0], p[1]-offset, p[2]-offset, max)
+		})
+	case "PALETTE COLOR":
+		return n.paletteImage()
+	case "", "MONOCHROME1", "MONOCHROME2":
+		if n.PhotometricInterpretation == "" && n.NumSamplesPerPixel() == 3 {
+			return n.colorImage(n.BitsPerSample, func(p []int) (int, int, int) { return p[0], p[1], p[2] })
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrorUnsupportedPhotometricInterpretation, n.PhotometricInterpretation)
+	}
+
 	i := image.NewGray16(image.Rect(0, 0, n.Cols, n.Rows))
-	for j := 0; j < len(n.Data); j++ {
-		i.SetGray16(j%n.Cols, j/n.Cols, color.Gray16{Y: uint16(n.Data[j][0])}) // for now, assume we're not overflowing uint16, assume gray image
+	for j := 0; j < n.NumPixels(); j++ {
+		i.SetGray16(j%n.Cols, j/n.Cols, color.Gray16{Y: uint16(n.At(j, 0))}) // for now, assume we're not overflowing uint16
 	}
 	return i, nil
 }
+
+// colorImage returns the frame as an RGB image, using toRGB to convert the
+// samples of each pixel to RGB values of the given number of bits.
+func (n *NativeFrame) colorImage(bits int, toRGB func(samples []int) (r, g, b int)) (image.Image, error) {
+	if err := n.checkSamples(3); err != nil {
+		return nil, err
+	}
+	rect := image.Rect(0, 0, n.Cols, n.Rows)
+	p := make([]int, 3)
+	if bits <= 8 {
+		img := image.NewRGBA(rect)
+		for j := 0; j < n.NumPixels(); j++ {
+			p[0], p[1], p[2] = n.At(j, 0), n.At(j, 1), n.At(j, 2)
+			r, g, b := toRGB(p)
+			img.Pix[4*j], img.Pix[4*j+1], img.Pix[4*j+2], img.Pix[4*j+3] = uint8(r), uint8(g), uint8(b), 0xFF
+		}
+		return img, nil
+	}
+	img := image.NewRGBA64(rect)
+	for j := 0; j < n.NumPixels(); j++ {
+		p[0], p[1], p[2] = n.At(j, 0), n.At(j, 1), n.At(j, 2)
+		r, g, b := toRGB(p)
+		img.SetRGBA64(j%n.Cols, j/n.Cols, color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: 0xFFFF})
+	}
+	return img, nil
+}
+
+// paletteImage returns the PALETTE COLOR frame as an RGB image, by looking up
+// each pixel in the Palette.
+func (n *NativeFrame) paletteImage() (image.Image, error) {
+	if n.Palette == nil {
+		return nil, fmt.Errorf("%w: no palette for PALETTE COLOR frame", ErrorInvalidPaletteLUT)
+	}
+	if err := n.Palette.Validate(); err != nil {
+		return nil, err
+	}
+	if err := n.checkSamples(1); err != nil {
+		return nil, err
+	}
+	rect := image.Rect(0, 0, n.Cols, n.Rows)
+	if n.Palette.BitsPerEntry == 8 {
+		img := image.NewRGBA(rect)
+		for j := 0; j < n.NumPixels(); j++ {
+			c := n.Palette.At(n.At(j, 0))
+			img.Pix[4*j], img.Pix[4*j+1], img.Pix[4*j+2], img.Pix[4*j+3] = uint8(c.R>>8), uint8(c.G>>8), uint8(c.B>>8), 0xFF
+		}
+		return img, nil
+	}
+	img := image.NewRGBA64(rect)
+	for j := 0; j < n.NumPixels(); j++ {
+		img.SetRGBA64(j%n.Cols, j/n.Cols, n.Palette.At(n.At(j, 0)))
+	}
+	return img, nil
+}
+
+// checkSamples returns an error if the frame does not hold Rows*Cols pixels
+// of at least the given number of samples.
+func (n *NativeFrame) checkSamples(samples int) error {
+	if n.NumPixels() != n.Rows*n.Cols {
+		return fmt.Errorf("%d pixels for %dx%d frame", n.NumPixels(), n.Rows, n.Cols)
+	}
+	if n.Samples == nil {
+		for _, p := range n.Data {
+			if len(p) < samples {
+				return fmt.Errorf("%s frame has pixels with %d samples, want %d", n.PhotometricInterpretation, len(p), samples)
+			}
+		}
+	} else if n.SamplesPerPixel < samples {
+		return fmt.Errorf("%s frame has pixels with %d samples, want %d", n.PhotometricInterpretation, n.SamplesPerPixel, samples)
+	}
+	return nil
+}
+
+// ybrToRGB converts the luminance y and the chrominance cb and cr (centered on
+// 0) to RGB values in the range [0, max]. See PS3.3 C.7.6.3.1.2.
+func ybrToRGB(y, cb, cr, max int) (int, int, int) {
+	fy, fcb, fcr := float64(y), float64(cb), float64(cr)
+	clamp := func(v float64) int {
+		if v < 0 {
+			return 0
+		}
+		if v > float64(max) {
+			return max
+		}
+		return int(v + 0.5)
+	}
+	return clamp(fy + 1.402*fcr), clamp(fy - 0.344136*fcb - 0.714136*fcr), clamp(fy + 1.772*fcb)
+}
@@ -1,7 +1,9 @@
 package frame_test
 
 import (
+	"errors"
 	"image"
+	"image/color"
 	"testing"
 
 	"github.com/suyashkumar/dicom/pkg/frame"
@@ -91,3 +93,126 @@ func within(pt point, set []point) bool {
 	}
 	return false
 }
+
+func TestNativeFrame_GetImage_color(t *testing.T) {
+	palette := &frame.PaletteLUT{
+		FirstValue:   10,
+		BitsPerEntry: 16,
+		Red:          []uint16{0xFFFF, 0x0000},
+		Green:        []uint16{0x0000, 0x8000},
+		Blue:         []uint16{0x1234, 0xFFFF},
+	}
+	cases := []struct {
+		name        string
+		nativeFrame frame.NativeFrame
+		want        []color.Color
+	}{
+		{
+			name: "RGB",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 2, BitsPerSample: 8, PhotometricInterpretation: "RGB",
+				Data: [][]int{{255, 0, 0}, {1, 2, 3}},
+			},
+			want: []color.Color{color.RGBA{R: 255, A: 255}, color.RGBA{R: 1, G: 2, B: 3, A: 255}},
+		},
+		{
+			name: "RGB, no PhotometricInterpretation",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 1, BitsPerSample: 8,
+				Data: [][]int{{4, 5, 6}},
+			},
+			want: []color.Color{color.RGBA{R: 4, G: 5, B: 6, A: 255}},
+		},
+		{
+			name: "RGB, 16 bits",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 1, BitsPerSample: 16, PhotometricInterpretation: "RGB",
+				Data: [][]int{{0x1000, 0x2000, 0xFFFF}},
+			},
+			want: []color.Color{color.RGBA64{R: 0x1000, G: 0x2000, B: 0xFFFF, A: 0xFFFF}},
+		},
+		{
+			name: "YBR_FULL",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 2, BitsPerSample: 8, PhotometricInterpretation: "YBR_FULL",
+				Data: [][]int{{128, 128, 128}, {76, 85, 255}},
+			},
+			want: []color.Color{color.RGBA{R: 128, G: 128, B: 128, A: 255}, color.RGBA{R: 254, A: 255}},
+		},
+		{
+			name: "YBR_FULL_422",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 2, BitsPerSample: 8, PhotometricInterpretation: "YBR_FULL_422",
+				Data: [][]int{{0, 128, 128}, {255, 128, 128}},
+			},
+			want: []color.Color{color.RGBA{A: 255}, color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+		},
+		{
+			name: "PALETTE COLOR",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 3, BitsPerSample: 16, PhotometricInterpretation: "PALETTE COLOR", Palette: palette,
+				Data: [][]int{{10}, {11}, {12}},
+			},
+			want: []color.Color{
+				color.RGBA64{R: 0xFFFF, B: 0x1234, A: 0xFFFF},
+				color.RGBA64{G: 0x8000, B: 0xFFFF, A: 0xFFFF},
+				// Values past the last entry map to the last entry.
+				color.RGBA64{G: 0x8000, B: 0xFFFF, A: 0xFFFF},
+			},
+		},
+		{
+			name: "PALETTE COLOR, 8 bits",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 1, BitsPerSample: 8, PhotometricInterpretation: "PALETTE COLOR",
+				Palette: &frame.PaletteLUT{BitsPerEntry: 8, Red: []uint16{1}, Green: []uint16{2}, Blue: []uint16{3}},
+				Data:    [][]int{{0}},
+			},
+			want: []color.Color{color.RGBA{R: 1, G: 2, B: 3, A: 255}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := tc.nativeFrame.GetImage()
+			if err != nil {
+				t.Fatalf("GetImage unexpected error: %v", err)
+			}
+			for j, want := range tc.want {
+				if got := img.At(j%tc.nativeFrame.Cols, j/tc.nativeFrame.Cols); got != want {
+					t.Errorf("GetImage unexpected color for pixel %d. got: %v, want: %v", j, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNativeFrame_GetImage_errors(t *testing.T) {
+	cases := []struct {
+		name        string
+		nativeFrame frame.NativeFrame
+		wantErr     error
+	}{
+		{
+			name: "unsupported PhotometricInterpretation",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 1, BitsPerSample: 8, PhotometricInterpretation: "HSV",
+				Data: [][]int{{1, 2, 3}},
+			},
+			wantErr: frame.ErrorUnsupportedPhotometricInterpretation,
+		},
+		{
+			name: "PALETTE COLOR without a palette",
+			nativeFrame: frame.NativeFrame{
+				Rows: 1, Cols: 1, BitsPerSample: 8, PhotometricInterpretation: "PALETTE COLOR",
+				Data: [][]int{{1}},
+			},
+			wantErr: frame.ErrorInvalidPaletteLUT,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.nativeFrame.GetImage(); !errors.Is(err, tc.wantErr) {
+				t.Errorf("GetImage unexpected error. got: %v, want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
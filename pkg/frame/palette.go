@@ -0,0 +1,171 @@
+package frame
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/color"
+)
+
+// ErrorInvalidPaletteLUT indicates that a palette color lookup table is
+// invalid.
+var ErrorInvalidPaletteLUT = errors.New("invalid palette color lookup table")
+
+// PaletteLUT is a palette color lookup table, which maps the single sample of
+// each pixel of a PALETTE COLOR frame to a color. See PS3.3 C.7.6.3.1.5.
+type PaletteLUT struct {
+	// FirstValue is the first sample value mapped by the LUT. Sample values
+	// below FirstValue map to the first entry, and sample values past the
+	// last entry map to the last entry.
+	FirstValue int
+	// BitsPerEntry is the number of bits in each entry, 8 or 16.
+	BitsPerEntry int
+	Red          []uint16
+	Green        []uint16
+	Blue         []uint16
+}
+
+// At returns the color the sample value v maps to.
+func (p *PaletteLUT) At(v int) color.RGBA64 {
+	return color.RGBA64{
+		R: p.scale(p.lookup(p.Red, v)),
+		G: p.scale(p.lookup(p.Green, v)),
+		B: p.scale(p.lookup(p.Blue, v)),
+		A: 0xFFFF,
+	}
+}
+
+func (p *PaletteLUT) lookup(entries []uint16, v int) uint16 {
+	i := v - p.FirstValue
+	if i < 0 {
+		i = 0
+	} else if i >= len(entries) {
+		i = len(entries) - 1
+	}
+	return entries[i]
+}
+
+// scale scales the entry v to 16 bits.
+func (p *PaletteLUT) scale(v uint16) uint16 {
+	if p.BitsPerEntry == 8 {
+		return v * 0x101
+	}
+	return v
+}
+
+// Validate returns an error if the PaletteLUT cannot be used to look up
+// colors.
+func (p *PaletteLUT) Validate() error {
+	if p.BitsPerEntry != 8 && p.BitsPerEntry != 16 {
+		return fmt.Errorf("%w: %d bits per entry", ErrorInvalidPaletteLUT, p.BitsPerEntry)
+	}
+	if len(p.Red) == 0 || len(p.Green) == 0 || len(p.Blue) == 0 {
+		return fmt.Errorf("%w: no entries", ErrorInvalidPaletteLUT)
+	}
+	return nil
+}
+
+// DecodePaletteData returns the numEntries entries held in the palette color
+// LUT data b (as read from an OW value, so in little endian byte order), for a
+// LUT descriptor with the given number of bits per entry. 8 bit entries are
+// expected to be packed two per 16 bit word, but LUT data that holds one 8 bit
+// entry per word is also supported.
+func DecodePaletteData(b []byte, numEntries, bitsPerEntry int) ([]uint16, error) {
+	entries := make([]uint16, numEntries)
+	switch {
+	case bitsPerEntry == 8 && len(b) >= numEntries && len(b) < 2*numEntries:
+		for i := range entries {
+			entries[i] = uint16(b[i])
+		}
+	case len(b) >= 2*numEntries:
+		var max uint16
+		for i := range entries {
+			entries[i] = binary.LittleEndian.Uint16(b[2*i:])
+			if entries[i] > max {
+				max = entries[i]
+			}
+		}
+		if bitsPerEntry == 8 && max > 0xFF {
+			// Some implementations hold 8 bit entries in the high byte of
+			// each word.
+			for i := range entries {
+				entries[i] >>= 8
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: %d bytes of data for %d entries", ErrorInvalidPaletteLUT, len(b), numEntries)
+	}
+	return entries, nil
+}
+
+// Segment opcodes of segmented palette color LUT data. See PS3.3 C.7.9.2.
+const (
+	segmentDiscrete = 0
+	segmentLinear   = 1
+	segmentIndirect = 2
+)
+
+// ExpandSegmentedPaletteData returns the entries described by the segmented
+// palette color LUT data b (as read from an OW value, so in little endian byte
+// order). See PS3.3 C.7.9.2.
+func ExpandSegmentedPaletteData(b []byte) ([]uint16, error) {
+	words := make([]uint16, len(b)/2)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return expandSegments(words, -1, nil, 0)
+}
+
+// expandSegments appends the entries described by the segments in words to
+// out, stopping after maxSegments segments if maxSegments is not negative.
+// depth limits the nesting of indirect segments.
+func expandSegments(words []uint16, maxSegments int, out []uint16, depth int) ([]uint16, error) {
+	if depth > 1 {
+		return nil, fmt.Errorf("%w: nested indirect segments", ErrorInvalidPaletteLUT)
+	}
+	for i, n := 0, 0; i < len(words) && (maxSegments < 0 || n < maxSegments); n++ {
+		if i+1 >= len(words) {
+			return nil, fmt.Errorf("%w: truncated segment at word %d", ErrorInvalidPaletteLUT, i)
+		}
+		opcode, length := words[i], int(words[i+1])
+		i += 2
+		switch opcode {
+		case segmentDiscrete:
+			if i+length > len(words) {
+				return nil, fmt.Errorf("%w: truncated discrete segment at word %d", ErrorInvalidPaletteLUT, i-2)
+			}
+			out = append(out, words[i:i+length]...)
+			i += length
+		case segmentLinear:
+			if len(out) == 0 || i >= len(words) {
+				return nil, fmt.Errorf("%w: invalid linear segment at word %d", ErrorInvalidPaletteLUT, i-2)
+			}
+			// The segment interpolates from the previous entry (exclusive) to
+			// the end value (inclusive).
+			y0, y1 := float64(out[len(out)-1]), float64(words[i])
+			for j := 1; j <= length; j++ {
+				out = append(out, uint16(y0+(y1-y0)*float64(j)/float64(length)+0.5))
+			}
+			i++
+		case segmentIndirect:
+			if i+1 >= len(words) {
+				return nil, fmt.Errorf("%w: truncated indirect segment at word %d", ErrorInvalidPaletteLUT, i-2)
+			}
+			// The offset (least significant word first) is the position of
+			// the first segment to copy. As in common implementations, it is
+			// counted in words from the start of the data.
+			offset := int(words[i]) | int(words[i+1])<<16
+			if offset >= len(words) {
+				return nil, fmt.Errorf("%w: indirect segment offset %d out of range", ErrorInvalidPaletteLUT, offset)
+			}
+			var err error
+			if out, err = expandSegments(words[offset:], length, out, depth+1); err != nil {
+				return nil, err
+			}
+			i += 2
+		default:
+			return nil, fmt.Errorf("%w: unknown segment opcode %d", ErrorInvalidPaletteLUT, opcode)
+		}
+	}
+	return out, nil
+}
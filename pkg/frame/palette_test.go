@@ -0,0 +1,120 @@
+package frame_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suyashkumar/dicom/pkg/frame"
+)
+
+func TestDecodePaletteData(t *testing.T) {
+	cases := []struct {
+		name         string
+		data         []byte
+		numEntries   int
+		bitsPerEntry int
+		want         []uint16
+	}{
+		{
+			name:         "16 bit entries",
+			data:         []byte{0x01, 0x02, 0x03, 0x04},
+			numEntries:   2,
+			bitsPerEntry: 16,
+			want:         []uint16{0x0201, 0x0403},
+		},
+		{
+			name:         "packed 8 bit entries",
+			data:         []byte{0x01, 0x02, 0x03, 0x00},
+			numEntries:   3,
+			bitsPerEntry: 8,
+			want:         []uint16{0x01, 0x02, 0x03},
+		},
+		{
+			name:         "8 bit entries in the low byte of each word",
+			data:         []byte{0x01, 0x00, 0x02, 0x00},
+			numEntries:   2,
+			bitsPerEntry: 8,
+			want:         []uint16{0x01, 0x02},
+		},
+		{
+			name:         "8 bit entries in the high byte of each word",
+			data:         []byte{0x00, 0x01, 0x00, 0xFF},
+			numEntries:   2,
+			bitsPerEntry: 8,
+			want:         []uint16{0x01, 0xFF},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := frame.DecodePaletteData(tc.data, tc.numEntries, tc.bitsPerEntry)
+			if err != nil {
+				t.Fatalf("DecodePaletteData unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("DecodePaletteData unexpected entries. diff: %v", diff)
+			}
+		})
+	}
+
+	if _, err := frame.DecodePaletteData([]byte{0x01, 0x02}, 2, 16); !errors.Is(err, frame.ErrorInvalidPaletteLUT) {
+		t.Errorf("DecodePaletteData with too little data unexpected error. got: %v, want: %v", err, frame.ErrorInvalidPaletteLUT)
+	}
+}
+
+func TestExpandSegmentedPaletteData(t *testing.T) {
+	cases := []struct {
+		name    string
+		words   []uint16
+		want    []uint16
+		wantErr error
+	}{
+		{
+			name: "discrete and linear segments",
+			words: []uint16{
+				0, 2, 0, 100, // Discrete: 0, 100.
+				1, 4, 500, // Linear: from 100 to 500 in 4 steps.
+			},
+			want: []uint16{0, 100, 200, 300, 400, 500},
+		},
+		{
+			name: "indirect segment",
+			words: []uint16{
+				0, 1, 10, // Discrete: 10.
+				1, 2, 30, // Linear: 20, 30.
+				2, 2, 0, 0, // Indirect: the 2 segments from word 0.
+			},
+			want: []uint16{10, 20, 30, 10, 20, 30},
+		},
+		{
+			name:    "truncated discrete segment",
+			words:   []uint16{0, 3, 1, 2},
+			wantErr: frame.ErrorInvalidPaletteLUT,
+		},
+		{
+			name:    "linear segment without a previous entry",
+			words:   []uint16{1, 2, 30},
+			wantErr: frame.ErrorInvalidPaletteLUT,
+		},
+		{
+			name:    "unknown opcode",
+			words:   []uint16{3, 0},
+			wantErr: frame.ErrorInvalidPaletteLUT,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := make([]byte, 2*len(tc.words))
+			for i, w := range tc.words {
+				data[2*i], data[2*i+1] = byte(w), byte(w>>8)
+			}
+			got, err := frame.ExpandSegmentedPaletteData(data)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("ExpandSegmentedPaletteData unexpected error. got: %v, want: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ExpandSegmentedPaletteData unexpected entries. diff: %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package jpeglossless
+
+import "fmt"
+
+// lookupBits is the number of bits looked up at once when decoding Huffman
+// codes. Longer codes are decoded one bit at a time.
+const lookupBits = 8
+
+// huffmanTable is a Huffman table, see ITU T.81 C and F.2.2.3.
+type huffmanTable struct {
+	// lookup maps the next lookupBits bits to the length (in the high byte)
+	// and value (in the low byte) of the code they start with, or 0 if the
+	// code is longer than lookupBits.
+	lookup [1 << lookupBits]uint16
+	// maxCode, minCode and valPtr hold, for each code length, the largest
+	// code (less than minCode if there are no codes), the smallest code, and
+	// the index in values of the value of the smallest code.
+	maxCode [17]int
+	minCode [17]int
+	valPtr  [17]int
+	values  []byte
+}
+
+// newHuffmanTable returns the Huffman table with counts[i] codes of length i+1,
+// for the values in order of increasing code length.
+func newHuffmanTable(counts [16]int, values []byte) (*huffmanTable, error) {
+	t := &huffmanTable{values: append([]byte(nil), values...)}
+	code, k := 0, 0
+	for l := 1; l <= 16; l++ {
+		n := counts[l-1]
+		t.minCode[l], t.maxCode[l], t.valPtr[l] = code, code+n-1, k
+		if code+n > 1<<uint(l) {
+			return nil, fmt.Errorf("%w: too many Huffman codes of length %d", ErrorInvalidData, l)
+		}
+		for ; n > 0; n-- {
+			if l <= lookupBits {
+				shift := uint(lookupBits - l)
+				for m := 0; m < 1<<shift; m++ {
+					t.lookup[code<<shift+m] = uint16(l<<8 | int(values[k]))
+				}
+			}
+			code++
+			k++
+		}
+		code <<= 1
+	}
+	return t, nil
+}
+
+// bitReader reads the bits of entropy coded data, see ITU T.81 F.2.2.5.
+type bitReader struct {
+	data []byte
+	pos  int
+	// acc holds the next n bits to be read, left aligned.
+	acc uint32
+	n   uint
+	// marker indicates that a marker (or the end of data) was reached at pos.
+	// Bits past a marker are read as zero.
+	marker bool
+}
+
+// fill makes at least 25 bits available in acc.
+func (r *bitReader) fill() {
+	for r.n <= 24 {
+		var b byte
+		if !r.marker && r.pos < len(r.data) {
+			b = r.data[r.pos]
+			if b != 0xFF {
+				r.pos++
+			} else if r.pos+1 < len(r.data) && r.data[r.pos+1] == 0 {
+				// A stuffed zero byte follows each 0xFF byte of data.
+				r.pos += 2
+			} else {
+				r.marker = true
+				b = 0
+			}
+		} else {
+			r.marker = true
+		}
+		r.acc |= uint32(b) << (24 - r.n)
+		r.n += 8
+	}
+}
+
+// bits reads k bits, for k up to 16.
+func (r *bitReader) bits(k uint) int {
+	if r.n < k {
+		r.fill()
+	}
+	v := int(r.acc >> (32 - k))
+	r.acc <<= k
+	r.n -= k
+	return v
+}
+
+// decodeHuffman reads a value coded with t.
+func (r *bitReader) decodeHuffman(t *huffmanTable) (int, error) {
+	if r.n < 16 {
+		r.fill()
+	}
+	if e := t.lookup[r.acc>>(32-lookupBits)]; e != 0 {
+		l := uint(e >> 8)
+		r.acc <<= l
+		r.n -= l
+		return int(e & 0xFF), nil
+	}
+	for l := uint(lookupBits + 1); l <= 16; l++ {
+		code := int(r.acc >> (32 - l))
+		if code <= t.maxCode[l] {
+			r.acc <<= l
+			r.n -= l
+			return int(t.values[t.valPtr[l]+code-t.minCode[l]]), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: invalid Huffman code", ErrorInvalidData)
+}
+
+// decodeDiff reads a difference coded with t, see ITU T.81 H.1.2.2.
+func (r *bitReader) decodeDiff(t *huffmanTable) (int, error) {
+	s, err := r.decodeHuffman(t)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case s == 0:
+		return 0, nil
+	case s == 16:
+		// The difference 32768 is coded without additional bits.
+		return 32768, nil
+	case s > 16:
+		return 0, fmt.Errorf("%w: difference category %d", ErrorInvalidData, s)
+	}
+	v := r.bits(uint(s))
+	if v < 1<<uint(s-1) {
+		v += 1 - 1<<uint(s)
+	}
+	return v, nil
+}
+
+// markerPos returns the position of the marker that follows the entropy coded
+// data read so far, or len(data) if there is none.
+func (r *bitReader) markerPos() int {
+	pos := r.pos
+	for pos+1 < len(r.data) {
+		if r.data[pos] == 0xFF && r.data[pos+1] != 0 {
+			return pos
+		}
+		pos++
+	}
+	return len(r.data)
+}
+
+// restart moves past the RSTn marker that ends the current restart interval,
+// and resets r to read the next interval.
+func (r *bitReader) restart() error {
+	pos := r.markerPos()
+	for pos+1 < len(r.data) && r.data[pos+1] == 0xFF {
+		pos++
+	}
+	if pos+1 >= len(r.data) || r.data[pos+1] < markerRST0 || r.data[pos+1] > markerRST7 {
+		return fmt.Errorf("%w: missing RST marker at offset %d", ErrorInvalidData, pos)
+	}
+	*r = bitReader{data: r.data, pos: pos + 2}
+	return nil
+}
@@ -0,0 +1,347 @@
+// Package jpeglossless implements a decoder for lossless JPEG images, i.e. the
+// Huffman coded, non-hierarchical lossless process (Process 14) of ITU T.81
+// Annex H. This is the process used by the JPEG Lossless transfer syntaxes
+// 1.2.840.10008.1.2.4.57 and 1.2.840.10008.1.2.4.70 (Selection Value 1).
+//
+// Images of 2 to 16 bit precision, with any number of components, all
+// predictors, point transforms and restart intervals are supported.
+// Components must not be subsampled.
+package jpeglossless
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrorInvalidData indicates that the data is not a valid lossless JPEG
+	// image.
+	ErrorInvalidData = errors.New("invalid lossless JPEG data")
+	// ErrorUnsupported indicates that the data is a JPEG image that uses a
+	// process (or feature) this package does not support, e.g. a lossy
+	// process or subsampled components.
+	ErrorUnsupported = errors.New("unsupported JPEG image")
+)
+
+// JPEG markers, see ITU T.81 Table B.1.
+const (
+	markerSOF3 = 0xC3
+	markerDHT  = 0xC4
+	markerRST0 = 0xD0
+	markerRST7 = 0xD7
+	markerSOI  = 0xD8
+	markerEOI  = 0xD9
+	markerSOS  = 0xDA
+	markerDNL  = 0xDC
+	markerDRI  = 0xDD
+)
+
+// Image is a decoded lossless JPEG image.
+type Image struct {
+	Width  int
+	Height int
+	// Components is the number of components (samples) of each pixel.
+	Components int
+	// Precision is the number of bits of each sample.
+	Precision int
+	// Data holds the samples of all pixels in row-major order, with the
+	// samples of each pixel together: component c of the pixel at row, col is
+	// at index (row*Width+col)*Components+c.
+	Data []uint16
+}
+
+// component is a component of the frame, as described by the SOF segment.
+type component struct {
+	id int
+	// table is the index of the Huffman table used by the current scan.
+	table int
+}
+
+// decoder holds the state of a single decode.
+type decoder struct {
+	data []byte
+	pos  int
+
+	img        *Image
+	components []component
+	tables     [4]*huffmanTable
+	// restartInterval is the number of MCUs (pixels) in each restart
+	// interval, or 0 if restart intervals are not used.
+	restartInterval int
+}
+
+// Decode decodes the lossless JPEG image in data.
+func Decode(data []byte) (*Image, error) {
+	d := &decoder{data: data}
+	if err := d.decode(); err != nil {
+		return nil, err
+	}
+	return d.img, nil
+}
+
+func (d *decoder) decode() error {
+	if len(d.data) < 2 || d.data[0] != 0xFF || d.data[1] != markerSOI {
+		return fmt.Errorf("%w: missing SOI marker", ErrorInvalidData)
+	}
+	d.pos = 2
+	scans := 0
+	for {
+		marker, err := d.nextMarker()
+		if err != nil {
+			return err
+		}
+		if marker == markerEOI {
+			break
+		}
+		segment, err := d.segment()
+		if err != nil {
+			return err
+		}
+		switch {
+		case marker == markerSOF3:
+			if d.img != nil {
+				return fmt.Errorf("%w: multiple frames", ErrorInvalidData)
+			}
+			err = d.parseSOF(segment)
+		case marker == markerDHT:
+			err = d.parseDHT(segment)
+		case marker == markerDRI:
+			err = d.parseDRI(segment)
+		case marker == markerSOS:
+			if err = d.parseSOS(segment); err == nil {
+				scans++
+			}
+		case marker == markerDNL:
+			err = fmt.Errorf("%w: DNL marker", ErrorUnsupported)
+		case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			err = fmt.Errorf("%w: SOF%d frames are not lossless Huffman coded", ErrorUnsupported, marker-0xC0)
+		}
+		// Other segments (APPn, COM, DQT, ...) are ignored.
+		if err != nil {
+			return err
+		}
+	}
+	if scans == 0 {
+		return fmt.Errorf("%w: no scans", ErrorInvalidData)
+	}
+	return nil
+}
+
+// nextMarker returns the next marker, skipping any fill bytes.
+func (d *decoder) nextMarker() (byte, error) {
+	for d.pos+1 < len(d.data) {
+		if d.data[d.pos] != 0xFF {
+			return 0, fmt.Errorf("%w: expected a marker at offset %d", ErrorInvalidData, d.pos)
+		}
+		if m := d.data[d.pos+1]; m != 0xFF {
+			d.pos += 2
+			return m, nil
+		}
+		d.pos++
+	}
+	return 0, fmt.Errorf("%w: missing EOI marker", ErrorInvalidData)
+}
+
+// segment returns the parameters of the marker segment at the current
+// position, and moves past it.
+func (d *decoder) segment() ([]byte, error) {
+	if d.pos+2 > len(d.data) {
+		return nil, fmt.Errorf("%w: truncated marker segment", ErrorInvalidData)
+	}
+	n := int(binary.BigEndian.Uint16(d.data[d.pos:]))
+	if n < 2 || d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("%w: invalid marker segment length %d", ErrorInvalidData, n)
+	}
+	s := d.data[d.pos+2 : d.pos+n]
+	d.pos += n
+	return s, nil
+}
+
+// parseSOF parses a SOF3 segment, see ITU T.81 B.2.2.
+func (d *decoder) parseSOF(s []byte) error {
+	if len(s) < 6 {
+		return fmt.Errorf("%w: truncated SOF segment", ErrorInvalidData)
+	}
+	img := &Image{
+		Precision:  int(s[0]),
+		Height:     int(binary.BigEndian.Uint16(s[1:])),
+		Width:      int(binary.BigEndian.Uint16(s[3:])),
+		Components: int(s[5]),
+	}
+	if img.Precision < 2 || img.Precision > 16 {
+		return fmt.Errorf("%w: precision %d", ErrorInvalidData, img.Precision)
+	}
+	if img.Height == 0 {
+		return fmt.Errorf("%w: number of lines defined by DNL", ErrorUnsupported)
+	}
+	if img.Width == 0 || img.Components == 0 || len(s) != 6+3*img.Components {
+		return fmt.Errorf("%w: invalid SOF segment", ErrorInvalidData)
+	}
+	for i := 0; i < img.Components; i++ {
+		c := s[6+3*i:]
+		if c[1] != 0x11 {
+			return fmt.Errorf("%w: component %d has sampling factors %dx%d", ErrorUnsupported, c[0], c[1]>>4, c[1]&0xF)
+		}
+		d.components = append(d.components, component{id: int(c[0])})
+	}
+	img.Data = make([]uint16, img.Width*img.Height*img.Components)
+	d.img = img
+	return nil
+}
+
+// parseDHT parses a DHT segment, which defines one or more Huffman tables.
+// See ITU T.81 B.2.4.2.
+func (d *decoder) parseDHT(s []byte) error {
+	for len(s) > 0 {
+		if len(s) < 17 {
+			return fmt.Errorf("%w: truncated DHT segment", ErrorInvalidData)
+		}
+		class, index := s[0]>>4, int(s[0]&0xF)
+		if class > 1 || index > 3 {
+			return fmt.Errorf("%w: invalid Huffman table %d of class %d", ErrorInvalidData, index, class)
+		}
+		var counts [16]int
+		n := 0
+		for i := range counts {
+			counts[i] = int(s[1+i])
+			n += counts[i]
+		}
+		if len(s) < 17+n {
+			return fmt.Errorf("%w: truncated DHT segment", ErrorInvalidData)
+		}
+		t, err := newHuffmanTable(counts, s[17:17+n])
+		if err != nil {
+			return err
+		}
+		// Lossless scans only use DC tables, but AC table definitions are
+		// harmless.
+		if class == 0 {
+			d.tables[index] = t
+		}
+		s = s[17+n:]
+	}
+	return nil
+}
+
+// parseDRI parses a DRI segment, see ITU T.81 B.2.4.4.
+func (d *decoder) parseDRI(s []byte) error {
+	if len(s) != 2 {
+		return fmt.Errorf("%w: invalid DRI segment", ErrorInvalidData)
+	}
+	d.restartInterval = int(binary.BigEndian.Uint16(s))
+	return nil
+}
+
+// parseSOS parses a SOS segment (see ITU T.81 B.2.3), and decodes the scan
+// that follows it.
+func (d *decoder) parseSOS(s []byte) error {
+	if d.img == nil {
+		return fmt.Errorf("%w: SOS before SOF", ErrorInvalidData)
+	}
+	if len(s) < 1 || len(s) != 4+2*int(s[0]) || s[0] == 0 {
+		return fmt.Errorf("%w: invalid SOS segment", ErrorInvalidData)
+	}
+	n := int(s[0])
+	var scan []int
+	for i := 0; i < n; i++ {
+		id, table := int(s[1+2*i]), int(s[2+2*i]>>4)
+		c := -1
+		for j, comp := range d.components {
+			if comp.id == id {
+				c = j
+			}
+		}
+		if c < 0 {
+			return fmt.Errorf("%w: scan component %d is not in the frame", ErrorInvalidData, id)
+		}
+		if table > 3 || d.tables[table] == nil {
+			return fmt.Errorf("%w: scan component %d uses undefined Huffman table %d", ErrorInvalidData, id, table)
+		}
+		d.components[c].table = table
+		scan = append(scan, c)
+	}
+	predictor, pointTransform := int(s[1+2*n]), int(s[3+2*n]&0xF)
+	if predictor < 1 || predictor > 7 {
+		return fmt.Errorf("%w: predictor %d", ErrorInvalidData, predictor)
+	}
+	if pointTransform >= d.img.Precision {
+		return fmt.Errorf("%w: point transform %d for precision %d", ErrorInvalidData, pointTransform, d.img.Precision)
+	}
+	return d.decodeScan(scan, predictor, pointTransform)
+}
+
+// decodeScan decodes the entropy coded data of a scan of the components
+// scan, see ITU T.81 H.1.2.
+func (d *decoder) decodeScan(scan []int, predictor, pointTransform int) error {
+	img := d.img
+	nc := img.Components
+	r := &bitReader{data: d.data, pos: d.pos}
+	numPixels := img.Width * img.Height
+	// intervalStart is the index of the first pixel of the current restart
+	// interval.
+	intervalStart := 0
+	initial := 1 << uint(img.Precision-pointTransform-1)
+	for i := 0; i < numPixels; i++ {
+		if d.restartInterval > 0 && i > 0 && i%d.restartInterval == 0 {
+			if err := r.restart(); err != nil {
+				return err
+			}
+			intervalStart = i
+		}
+		x, y := i%img.Width, i/img.Width
+		firstLine := y == intervalStart/img.Width
+		for _, c := range scan {
+			idx := i*nc + c
+			var pred int
+			switch {
+			case i == intervalStart:
+				pred = initial
+			case firstLine:
+				pred = int(img.Data[idx-nc])
+			case x == 0:
+				pred = int(img.Data[idx-img.Width*nc])
+			default:
+				ra := int(img.Data[idx-nc])
+				rb := int(img.Data[idx-img.Width*nc])
+				rc := int(img.Data[idx-img.Width*nc-nc])
+				pred = predict(predictor, ra, rb, rc)
+			}
+			diff, err := r.decodeDiff(d.tables[d.components[c].table])
+			if err != nil {
+				return err
+			}
+			img.Data[idx] = uint16(pred + diff)
+		}
+	}
+	if pointTransform > 0 {
+		for i := 0; i < numPixels; i++ {
+			for _, c := range scan {
+				img.Data[i*nc+c] <<= uint(pointTransform)
+			}
+		}
+	}
+	d.pos = r.markerPos()
+	return nil
+}
+
+// predict returns the prediction of predictor (Table H.1) from the
+// reconstructed samples to the left (ra), above (rb) and above left (rc).
+func predict(predictor, ra, rb, rc int) int {
+	switch predictor {
+	case 1:
+		return ra
+	case 2:
+		return rb
+	case 3:
+		return rc
+	case 4:
+		return ra + rb - rc
+	case 5:
+		return ra + (rb-rc)>>1
+	case 6:
+		return rb + (ra-rc)>>1
+	default:
+		return (ra + rb) >> 1
+	}
+}
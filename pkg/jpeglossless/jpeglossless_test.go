@@ -0,0 +1,304 @@
+package jpeglossless_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suyashkumar/dicom/pkg/jpeglossless"
+)
+
+func TestDecode(t *testing.T) {
+	// A 3x1 8 bit image with samples 128, 130, 129 and predictor 1, using a
+	// Huffman table with codes 0, 10 and 110 for categories 0, 1 and 2. The
+	// scan is 0 (diff 0), 110 10 (diff 2), 10 0 (diff -1), padded with ones.
+	data := []byte{
+		0xFF, 0xD8,
+		0xFF, 0xC3, 0x00, 0x0B, 0x08, 0x00, 0x01, 0x00, 0x03, 0x01, 0x01, 0x11, 0x00,
+		0xFF, 0xC4, 0x00, 0x16, 0x00, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x00, 0x01, 0x02,
+		0xFF, 0xDA, 0x00, 0x08, 0x01, 0x01, 0x00, 0x01, 0x00, 0x00,
+		0x6A, 0x7F,
+		0xFF, 0xD9,
+	}
+	got, err := jpeglossless.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode unexpected error: %v", err)
+	}
+	want := &jpeglossless.Image{Width: 3, Height: 1, Components: 1, Precision: 8, Data: []uint16{128, 130, 129}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Decode unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecode_roundTrip(t *testing.T) {
+	type testCase struct {
+		name string
+		img  *jpeglossless.Image
+		opts encodeOpts
+	}
+	var cases []testCase
+	for _, precision := range []int{2, 8, 12, 16} {
+		for predictor := 1; predictor <= 7; predictor++ {
+			cases = append(cases, testCase{
+				name: fmt.Sprintf("precision %d, predictor %d", precision, predictor),
+				img:  randomImage(7, 5, 1, precision, 0),
+				opts: encodeOpts{predictor: predictor},
+			})
+		}
+	}
+	cases = append(cases,
+		testCase{
+			name: "3 components, interleaved",
+			img:  randomImage(6, 4, 3, 8, 0),
+			opts: encodeOpts{predictor: 4},
+		},
+		testCase{
+			name: "3 components, one scan per component",
+			img:  randomImage(6, 4, 3, 12, 0),
+			opts: encodeOpts{predictor: 6, scanPerComponent: true},
+		},
+		testCase{
+			name: "restart interval of one row",
+			img:  randomImage(5, 6, 1, 16, 0),
+			opts: encodeOpts{predictor: 7, restartInterval: 5},
+		},
+		testCase{
+			name: "restart interval within rows",
+			img:  randomImage(5, 6, 3, 8, 0),
+			opts: encodeOpts{predictor: 5, restartInterval: 3},
+		},
+		testCase{
+			name: "point transform",
+			img:  randomImage(6, 3, 1, 12, 2),
+			opts: encodeOpts{predictor: 1, pointTransform: 2},
+		},
+	)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := jpeglossless.Decode(encode(tc.img, tc.opts))
+			if err != nil {
+				t.Fatalf("Decode unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.img, got); diff != "" {
+				t.Errorf("Decode unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecode_errors(t *testing.T) {
+	valid := encode(randomImage(4, 4, 1, 8, 0), encodeOpts{predictor: 1})
+	cases := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{
+			name:    "no SOI marker",
+			data:    valid[2:],
+			wantErr: jpeglossless.ErrorInvalidData,
+		},
+		{
+			name:    "truncated",
+			data:    valid[:len(valid)-4],
+			wantErr: jpeglossless.ErrorInvalidData,
+		},
+		{
+			name:    "baseline",
+			data:    []byte{0xFF, 0xD8, 0xFF, 0xC0, 0x00, 0x0B, 0x08, 0x00, 0x01, 0x00, 0x01, 0x01, 0x01, 0x11, 0x00, 0xFF, 0xD9},
+			wantErr: jpeglossless.ErrorUnsupported,
+		},
+		{
+			name:    "subsampled component",
+			data:    []byte{0xFF, 0xD8, 0xFF, 0xC3, 0x00, 0x0B, 0x08, 0x00, 0x01, 0x00, 0x01, 0x01, 0x01, 0x21, 0x00, 0xFF, 0xD9},
+			wantErr: jpeglossless.ErrorUnsupported,
+		},
+		{
+			name:    "no scans",
+			data:    []byte{0xFF, 0xD8, 0xFF, 0xC3, 0x00, 0x0B, 0x08, 0x00, 0x01, 0x00, 0x01, 0x01, 0x01, 0x11, 0x00, 0xFF, 0xD9},
+			wantErr: jpeglossless.ErrorInvalidData,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := jpeglossless.Decode(tc.data); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Decode unexpected error. got: %v, want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data := encode(randomImage(512, 512, 1, 12, 0), encodeOpts{predictor: 1})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := jpeglossless.Decode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// randomImage returns an image with random samples of precision bits, whose
+// low pointTransform bits are zero. Neighbouring samples are correlated, so
+// that both small and large differences are coded.
+func randomImage(width, height, components, precision, pointTransform int) *jpeglossless.Image {
+	r := rand.New(rand.NewSource(int64(width*height*components*precision + pointTransform)))
+	img := &jpeglossless.Image{Width: width, Height: height, Components: components, Precision: precision}
+	max := 1 << uint(precision)
+	for i := 0; i < width*height*components; i++ {
+		v := r.Intn(max)
+		if i > 0 && r.Intn(2) == 0 {
+			v = (int(img.Data[i-1]) + r.Intn(5) - 2 + max) % max
+		}
+		img.Data = append(img.Data, uint16(v>>uint(pointTransform)<<uint(pointTransform)))
+	}
+	return img
+}
+
+type encodeOpts struct {
+	predictor        int
+	pointTransform   int
+	restartInterval  int
+	scanPerComponent bool
+}
+
+// huffmanCounts are the number of codes of each length of the Huffman table
+// used by encode, for the categories 0 to 16 in order. Some codes are longer
+// than 8 bits.
+var huffmanCounts = [16]byte{0, 1, 2, 2, 2, 2, 2, 2, 2, 2}
+
+// encode returns img encoded as a lossless JPEG.
+func encode(img *jpeglossless.Image, opts encodeOpts) []byte {
+	out := []byte{0xFF, 0xD8}
+	segment := func(marker byte, params ...byte) {
+		out = append(out, 0xFF, marker, 0, 0)
+		binary.BigEndian.PutUint16(out[len(out)-2:], uint16(len(params)+2))
+		out = append(out, params...)
+	}
+
+	sof := []byte{byte(img.Precision), 0, 0, 0, 0, byte(img.Components)}
+	binary.BigEndian.PutUint16(sof[1:], uint16(img.Height))
+	binary.BigEndian.PutUint16(sof[3:], uint16(img.Width))
+	for c := 0; c < img.Components; c++ {
+		sof = append(sof, byte(c+1), 0x11, 0)
+	}
+	segment(0xC3, sof...)
+
+	dht := append([]byte{0x00}, huffmanCounts[:]...)
+	for v := 0; v <= 16; v++ {
+		dht = append(dht, byte(v))
+	}
+	segment(0xC4, dht...)
+
+	var codes [17]struct{ code, length int }
+	code, v := 0, 0
+	for l := 1; l <= 16; l++ {
+		for n := 0; n < int(huffmanCounts[l-1]); n++ {
+			codes[v].code, codes[v].length = code, l
+			code++
+			v++
+		}
+		code <<= 1
+	}
+
+	if opts.restartInterval > 0 {
+		segment(0xDD, byte(opts.restartInterval>>8), byte(opts.restartInterval))
+	}
+
+	scans := [][]int{nil}
+	for c := 0; c < img.Components; c++ {
+		if opts.scanPerComponent && c > 0 {
+			scans = append(scans, nil)
+		}
+		scans[len(scans)-1] = append(scans[len(scans)-1], c)
+	}
+	for _, scan := range scans {
+		sos := []byte{byte(len(scan))}
+		for _, c := range scan {
+			sos = append(sos, byte(c+1), 0x00)
+		}
+		sos = append(sos, byte(opts.predictor), 0, byte(opts.pointTransform))
+		segment(0xDA, sos...)
+
+		w := &bitWriter{}
+		nc, width := img.Components, img.Width
+		sample := func(i int) int { return int(img.Data[i]) >> uint(opts.pointTransform) }
+		intervalStart := 0
+		for i := 0; i < width*img.Height; i++ {
+			if opts.restartInterval > 0 && i > 0 && i%opts.restartInterval == 0 {
+				w.flush()
+				out = append(out, w.out...)
+				out = append(out, 0xFF, byte(0xD0+(i/opts.restartInterval-1)%8))
+				w = &bitWriter{}
+				intervalStart = i
+			}
+			x, y := i%width, i/width
+			for _, c := range scan {
+				idx := i*nc + c
+				var pred int
+				switch {
+				case i == intervalStart:
+					pred = 1 << uint(img.Precision-opts.pointTransform-1)
+				case y == intervalStart/width:
+					pred = sample(idx - nc)
+				case x == 0:
+					pred = sample(idx - width*nc)
+				default:
+					ra, rb, rc := sample(idx-nc), sample(idx-width*nc), sample(idx-width*nc-nc)
+					pred = []int{0, ra, rb, rc, ra + rb - rc, ra + (rb-rc)>>1, rb + (ra-rc)>>1, (ra + rb) >> 1}[opts.predictor]
+				}
+				diff := (sample(idx) - pred) & 0xFFFF
+				if diff >= 1<<15 {
+					diff -= 1 << 16
+				}
+				abs := diff
+				if abs < 0 {
+					abs = -abs
+				}
+				category := bits.Len(uint(abs))
+				w.write(codes[category].code, codes[category].length)
+				if category < 16 {
+					if diff < 0 {
+						diff += 1<<uint(category) - 1
+					}
+					w.write(diff, category)
+				}
+			}
+		}
+		w.flush()
+		out = append(out, w.out...)
+	}
+	return append(out, 0xFF, 0xD9)
+}
+
+// bitWriter writes entropy coded data, stuffing a zero byte after each 0xFF.
+type bitWriter struct {
+	out []byte
+	acc int
+	n   int
+}
+
+func (w *bitWriter) write(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		w.acc = w.acc<<1 | (v>>uint(i))&1
+		w.n++
+		if w.n == 8 {
+			w.out = append(w.out, byte(w.acc))
+			if w.acc == 0xFF {
+				w.out = append(w.out, 0)
+			}
+			w.acc, w.n = 0, 0
+		}
+	}
+}
+
+// flush pads the last byte with one bits.
+func (w *bitWriter) flush() {
+	if w.n > 0 {
+		w.write(0xFF, 8-w.n)
+	}
+}
@@ -0,0 +1,167 @@
+package jpegls
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// bitReader reads the bits of a JPEG-LS scan, see ITU T.87 9.1. After each
+// 0xFF byte of data, a zero bit is stuffed into the next byte, so 0xFF followed
+// by a byte with its high bit set is a marker.
+type bitReader struct {
+	data []byte
+	pos  int
+	// acc holds the next n bits to be read, left aligned. The bits after them
+	// are zero.
+	acc uint64
+	n   uint
+	// ff indicates that the last byte read was 0xFF.
+	ff bool
+	// marker indicates that a marker (or the end of data) was reached at pos.
+	// Bits past a marker are read as zero, and padding counts them.
+	marker  bool
+	padding uint
+}
+
+// fill makes at least 57 bits available in acc.
+func (r *bitReader) fill() {
+	for r.n <= 56 {
+		if !r.marker && r.pos < len(r.data) {
+			b := r.data[r.pos]
+			switch {
+			case r.ff && b&0x80 != 0:
+				r.marker = true
+				continue
+			case r.ff:
+				r.pos++
+				r.acc |= uint64(b) << (57 - r.n)
+				r.n += 7
+				r.ff = false
+				continue
+			case b == 0xFF && (r.pos+1 == len(r.data) || r.data[r.pos+1]&0x80 != 0):
+				r.marker = true
+				continue
+			}
+			r.pos++
+			r.acc |= uint64(b) << (56 - r.n)
+			r.n += 8
+			r.ff = b == 0xFF
+			continue
+		}
+		r.marker = true
+		r.n += 8
+		r.padding += 8
+	}
+}
+
+// bits reads k bits, for k up to 32.
+func (r *bitReader) bits(k uint) int {
+	if k == 0 {
+		return 0
+	}
+	if r.n < k {
+		r.fill()
+	}
+	v := int(r.acc >> (64 - k))
+	r.acc <<= k
+	r.n -= k
+	return v
+}
+
+// zeros reads zero bits up to and including the next one bit, and returns the
+// number of zero bits read. More than max zero bits are invalid.
+func (r *bitReader) zeros(max int) (int, error) {
+	count := 0
+	for {
+		if r.n == 0 {
+			r.fill()
+		}
+		lz := uint(bits.LeadingZeros64(r.acc))
+		if lz < r.n {
+			count += int(lz)
+			if count > max {
+				break
+			}
+			r.acc <<= lz + 1
+			r.n -= lz + 1
+			return count, nil
+		}
+		count += int(r.n)
+		r.acc, r.n = 0, 0
+		if count > max {
+			break
+		}
+	}
+	return 0, fmt.Errorf("%w: invalid Golomb code", ErrorInvalidData)
+}
+
+// overrun reports if more bits were read than the scan holds.
+func (r *bitReader) overrun() bool {
+	return r.n < r.padding
+}
+
+// markerPos returns the position of the marker that follows the scan data read
+// so far, or len(data) if there is none.
+func (r *bitReader) markerPos() int {
+	for pos := r.pos; pos+1 < len(r.data); pos++ {
+		if r.data[pos] == 0xFF && r.data[pos+1]&0x80 != 0 {
+			return pos
+		}
+	}
+	return len(r.data)
+}
+
+// bitWriter writes the bits of a JPEG-LS scan, stuffing a zero bit after each
+// 0xFF byte.
+type bitWriter struct {
+	buf []byte
+	// acc holds the n bits not yet written, right aligned.
+	acc uint64
+	n   uint
+	// ff indicates that the last byte written was 0xFF.
+	ff bool
+}
+
+// writeBits writes the k low bits of v, for k up to 32.
+func (w *bitWriter) writeBits(v int, k uint) {
+	w.acc = w.acc<<k | uint64(v)&(1<<k-1)
+	w.n += k
+	for {
+		size := uint(8)
+		if w.ff {
+			size = 7
+		}
+		if w.n < size {
+			return
+		}
+		w.n -= size
+		b := byte(w.acc>>w.n) & byte(1<<size-1)
+		w.buf = append(w.buf, b)
+		w.ff = b == 0xFF
+		w.acc &= 1<<w.n - 1
+	}
+}
+
+// writeZeros writes k zero bits.
+func (w *bitWriter) writeZeros(k int) {
+	for ; k > 32; k -= 32 {
+		w.writeBits(0, 32)
+	}
+	w.writeBits(0, uint(k))
+}
+
+// flush pads the bits written to a byte boundary with zero bits. If the last
+// byte written is 0xFF, a zero byte is added so it is not taken for the start
+// of a marker.
+func (w *bitWriter) flush() {
+	if w.n > 0 {
+		size := uint(8)
+		if w.ff {
+			size = 7
+		}
+		w.writeBits(0, size-w.n)
+	}
+	if w.ff {
+		w.writeBits(0, 7)
+	}
+}
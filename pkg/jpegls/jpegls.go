@@ -0,0 +1,353 @@
+// Package jpegls implements a decoder and an encoder for JPEG-LS images, i.e.
+// the LOCO-I based lossless and near-lossless process of ITU T.87 (ISO/IEC
+// 14495-1). This is the process used by the JPEG-LS Lossless and JPEG-LS
+// Near-Lossless transfer syntaxes 1.2.840.10008.1.2.4.80 and
+// 1.2.840.10008.1.2.4.81.
+//
+// Images of 2 to 16 bit precision, with any number of components in any
+// interleave mode, are supported, including preset coding parameters.
+// Components must not be subsampled, and mapping tables, restart intervals and
+// point transforms are not supported.
+package jpegls
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrorInvalidData indicates that the data is not a valid JPEG-LS image.
+	ErrorInvalidData = errors.New("invalid JPEG-LS data")
+	// ErrorUnsupported indicates that the data is a JPEG-LS image that uses a
+	// feature this package does not support, e.g. mapping tables, or that an
+	// image cannot be encoded.
+	ErrorUnsupported = errors.New("unsupported JPEG-LS image")
+)
+
+// JPEG-LS markers, see ITU T.87 Table C.1.
+const (
+	markerSOI   = 0xD8
+	markerEOI   = 0xD9
+	markerSOS   = 0xDA
+	markerDNL   = 0xDC
+	markerDRI   = 0xDD
+	markerSOF55 = 0xF7
+	markerLSE   = 0xF8
+)
+
+// InterleaveMode is the way the components of an image are interleaved in its
+// scans, see ITU T.87 Annex B.
+type InterleaveMode int
+
+const (
+	// InterleaveNone codes each component in a separate scan.
+	InterleaveNone InterleaveMode = iota
+	// InterleaveLine codes a line of each component in turn.
+	InterleaveLine
+	// InterleaveSample codes the samples of each pixel together.
+	InterleaveSample
+)
+
+// Image is a JPEG-LS image.
+type Image struct {
+	Width  int
+	Height int
+	// Components is the number of components (samples) of each pixel.
+	Components int
+	// Precision is the number of bits of each sample.
+	Precision int
+	// Data holds the samples of all pixels in row-major order, with the
+	// samples of each pixel together: component c of the pixel at row, col is
+	// at index (row*Width+col)*Components+c.
+	Data []uint16
+}
+
+// Options are the coding options used by Encode.
+type Options struct {
+	// Near is the largest difference allowed between a sample and its decoded
+	// value: 0 for lossless coding, or up to 255 (and half the largest
+	// sample value) for near-lossless coding.
+	Near int
+	// InterleaveMode is the interleave mode of images with more than one
+	// component. Images with a single component are not interleaved.
+	InterleaveMode InterleaveMode
+}
+
+// presets are the coding parameters that can be set in an LSE segment, see
+// ITU T.87 C.2.4.1.1. Zero values are replaced with their defaults.
+type presets struct {
+	maxVal, t1, t2, t3, reset int
+}
+
+// Decode decodes the JPEG-LS image in data.
+func Decode(data []byte) (*Image, error) {
+	d := &decoder{data: data}
+	if err := d.decode(); err != nil {
+		return nil, err
+	}
+	return d.img, nil
+}
+
+// decoder holds the state of a single decode.
+type decoder struct {
+	data []byte
+	pos  int
+
+	img *Image
+	// componentIDs are the identifiers of the components, in frame order.
+	componentIDs []int
+	presets      presets
+}
+
+func (d *decoder) decode() error {
+	if len(d.data) < 2 || d.data[0] != 0xFF || d.data[1] != markerSOI {
+		return fmt.Errorf("%w: missing SOI marker", ErrorInvalidData)
+	}
+	d.pos = 2
+	scans := 0
+	for {
+		marker, err := d.nextMarker()
+		if err != nil {
+			return err
+		}
+		if marker == markerEOI {
+			break
+		}
+		segment, err := d.segment()
+		if err != nil {
+			return err
+		}
+		switch {
+		case marker == markerSOF55:
+			if d.img != nil {
+				return fmt.Errorf("%w: multiple frames", ErrorInvalidData)
+			}
+			err = d.parseSOF(segment)
+		case marker == markerLSE:
+			err = d.parseLSE(segment)
+		case marker == markerSOS:
+			if err = d.parseSOS(segment); err == nil {
+				scans++
+			}
+		case marker == markerDRI:
+			if len(segment) >= 2 && binary.BigEndian.Uint16(segment) != 0 {
+				err = fmt.Errorf("%w: restart intervals", ErrorUnsupported)
+			}
+		case marker == markerDNL:
+			err = fmt.Errorf("%w: DNL marker", ErrorUnsupported)
+		case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			err = fmt.Errorf("%w: SOF%d frames are not JPEG-LS coded", ErrorUnsupported, marker-0xC0)
+		}
+		// Other segments (APPn, COM, ...) are ignored.
+		if err != nil {
+			return err
+		}
+	}
+	if scans == 0 {
+		return fmt.Errorf("%w: no scans", ErrorInvalidData)
+	}
+	return nil
+}
+
+// nextMarker returns the next marker, skipping any fill bytes.
+func (d *decoder) nextMarker() (byte, error) {
+	for d.pos+1 < len(d.data) {
+		if d.data[d.pos] != 0xFF {
+			return 0, fmt.Errorf("%w: expected a marker at offset %d", ErrorInvalidData, d.pos)
+		}
+		if m := d.data[d.pos+1]; m != 0xFF {
+			d.pos += 2
+			return m, nil
+		}
+		d.pos++
+	}
+	return 0, fmt.Errorf("%w: missing EOI marker", ErrorInvalidData)
+}
+
+// segment returns the parameters of the marker segment at the current
+// position, and moves past it.
+func (d *decoder) segment() ([]byte, error) {
+	if d.pos+2 > len(d.data) {
+		return nil, fmt.Errorf("%w: truncated marker segment", ErrorInvalidData)
+	}
+	n := int(binary.BigEndian.Uint16(d.data[d.pos:]))
+	if n < 2 || d.pos+n > len(d.data) {
+		return nil, fmt.Errorf("%w: invalid marker segment length %d", ErrorInvalidData, n)
+	}
+	s := d.data[d.pos+2 : d.pos+n]
+	d.pos += n
+	return s, nil
+}
+
+// parseSOF parses a SOF55 segment, see ITU T.87 C.2.2.
+func (d *decoder) parseSOF(s []byte) error {
+	if len(s) < 6 {
+		return fmt.Errorf("%w: truncated SOF segment", ErrorInvalidData)
+	}
+	img := &Image{
+		Precision:  int(s[0]),
+		Height:     int(binary.BigEndian.Uint16(s[1:])),
+		Width:      int(binary.BigEndian.Uint16(s[3:])),
+		Components: int(s[5]),
+	}
+	if img.Precision < 2 || img.Precision > 16 {
+		return fmt.Errorf("%w: precision %d", ErrorInvalidData, img.Precision)
+	}
+	if img.Height == 0 {
+		return fmt.Errorf("%w: number of lines defined by DNL", ErrorUnsupported)
+	}
+	if img.Width == 0 || img.Components == 0 || len(s) != 6+3*img.Components {
+		return fmt.Errorf("%w: invalid SOF segment", ErrorInvalidData)
+	}
+	for i := 0; i < img.Components; i++ {
+		c := s[6+3*i:]
+		if c[1] != 0x11 {
+			return fmt.Errorf("%w: component %d has sampling factors %dx%d", ErrorUnsupported, c[0], c[1]>>4, c[1]&0xF)
+		}
+		d.componentIDs = append(d.componentIDs, int(c[0]))
+	}
+	img.Data = make([]uint16, img.Width*img.Height*img.Components)
+	d.img = img
+	return nil
+}
+
+// parseLSE parses an LSE segment, see ITU T.87 C.2.4.1. Only preset coding
+// parameters are supported.
+func (d *decoder) parseLSE(s []byte) error {
+	if len(s) < 1 || s[0] != 1 {
+		return fmt.Errorf("%w: LSE segment other than preset coding parameters", ErrorUnsupported)
+	}
+	if len(s) != 11 {
+		return fmt.Errorf("%w: invalid LSE segment", ErrorInvalidData)
+	}
+	d.presets = presets{
+		maxVal: int(binary.BigEndian.Uint16(s[1:])),
+		t1:     int(binary.BigEndian.Uint16(s[3:])),
+		t2:     int(binary.BigEndian.Uint16(s[5:])),
+		t3:     int(binary.BigEndian.Uint16(s[7:])),
+		reset:  int(binary.BigEndian.Uint16(s[9:])),
+	}
+	return nil
+}
+
+// parseSOS parses a SOS segment (see ITU T.87 C.2.3), and decodes the scan
+// that follows it.
+func (d *decoder) parseSOS(s []byte) error {
+	if d.img == nil {
+		return fmt.Errorf("%w: SOS before SOF", ErrorInvalidData)
+	}
+	if len(s) < 1 || s[0] == 0 || len(s) != 4+2*int(s[0]) {
+		return fmt.Errorf("%w: invalid SOS segment", ErrorInvalidData)
+	}
+	n := int(s[0])
+	var components []int
+	for i := 0; i < n; i++ {
+		id, table := int(s[1+2*i]), s[2+2*i]
+		c := -1
+		for j, cid := range d.componentIDs {
+			if cid == id {
+				c = j
+			}
+		}
+		if c < 0 {
+			return fmt.Errorf("%w: scan component %d is not in the frame", ErrorInvalidData, id)
+		}
+		if table != 0 {
+			return fmt.Errorf("%w: mapping tables", ErrorUnsupported)
+		}
+		components = append(components, c)
+	}
+	near, mode, pointTransform := int(s[1+2*n]), InterleaveMode(s[2+2*n]), s[3+2*n]&0xF
+	if mode > InterleaveSample || (mode == InterleaveNone) != (n == 1) {
+		return fmt.Errorf("%w: interleave mode %d for %d components", ErrorInvalidData, mode, n)
+	}
+	if pointTransform != 0 {
+		return fmt.Errorf("%w: point transform %d", ErrorUnsupported, pointTransform)
+	}
+	p, err := newParams(d.img.Precision, near, d.presets)
+	if err != nil {
+		return err
+	}
+	sc := newScan(d.img, components, mode, p)
+	sc.r = &bitReader{data: d.data, pos: d.pos}
+	if err := sc.code(); err != nil {
+		return err
+	}
+	d.pos = sc.r.markerPos()
+	return nil
+}
+
+// Encode encodes img as a JPEG-LS image, using the default coding parameters.
+func Encode(img *Image, opts Options) ([]byte, error) {
+	if img.Precision < 2 || img.Precision > 16 {
+		return nil, fmt.Errorf("%w: precision %d", ErrorUnsupported, img.Precision)
+	}
+	if img.Width < 1 || img.Width > 0xFFFF || img.Height < 1 || img.Height > 0xFFFF || img.Components < 1 || img.Components > 0xFF {
+		return nil, fmt.Errorf("%w: %dx%d image with %d components", ErrorUnsupported, img.Width, img.Height, img.Components)
+	}
+	if len(img.Data) != img.Width*img.Height*img.Components {
+		return nil, fmt.Errorf("%w: %d samples for a %dx%d image with %d components", ErrorInvalidData, len(img.Data), img.Width, img.Height, img.Components)
+	}
+	maxVal := 1<<uint(img.Precision) - 1
+	for _, v := range img.Data {
+		if int(v) > maxVal {
+			return nil, fmt.Errorf("%w: sample %d exceeds precision %d", ErrorInvalidData, v, img.Precision)
+		}
+	}
+	if opts.InterleaveMode < InterleaveNone || opts.InterleaveMode > InterleaveSample {
+		return nil, fmt.Errorf("%w: interleave mode %d", ErrorUnsupported, opts.InterleaveMode)
+	}
+	p, err := newParams(img.Precision, opts.Near, presets{})
+	if err != nil {
+		return nil, err
+	}
+	mode := opts.InterleaveMode
+	if img.Components == 1 {
+		mode = InterleaveNone
+	}
+
+	out := []byte{0xFF, markerSOI}
+	sof := []byte{byte(img.Precision), byte(img.Height >> 8), byte(img.Height), byte(img.Width >> 8), byte(img.Width), byte(img.Components)}
+	for c := 0; c < img.Components; c++ {
+		sof = append(sof, byte(c+1), 0x11, 0)
+	}
+	out = appendSegment(out, markerSOF55, sof)
+
+	var scans [][]int
+	if mode == InterleaveNone {
+		for c := 0; c < img.Components; c++ {
+			scans = append(scans, []int{c})
+		}
+	} else {
+		all := make([]int, img.Components)
+		for c := range all {
+			all[c] = c
+		}
+		scans = append(scans, all)
+	}
+	for _, components := range scans {
+		sos := []byte{byte(len(components))}
+		for _, c := range components {
+			sos = append(sos, byte(c+1), 0)
+		}
+		sos = append(sos, byte(opts.Near), byte(mode), 0)
+		out = appendSegment(out, markerSOS, sos)
+
+		sc := newScan(img, components, mode, p)
+		sc.w = &bitWriter{buf: out}
+		if err := sc.code(); err != nil {
+			return nil, err
+		}
+		sc.w.flush()
+		out = sc.w.buf
+	}
+	return append(out, 0xFF, markerEOI), nil
+}
+
+// appendSegment appends the marker segment with the given parameters to b.
+func appendSegment(b []byte, marker byte, params []byte) []byte {
+	n := len(params) + 2
+	b = append(b, 0xFF, marker, byte(n>>8), byte(n))
+	return append(b, params...)
+}
@@ -0,0 +1,281 @@
+package jpegls_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suyashkumar/dicom/pkg/jpegls"
+)
+
+// annexH3 is the example image of ITU T.87 H.3, and its encoding.
+var (
+	annexH3 = &jpegls.Image{Width: 4, Height: 4, Components: 1, Precision: 8, Data: []uint16{
+		0, 0, 90, 74,
+		68, 50, 43, 205,
+		64, 145, 145, 145,
+		100, 145, 145, 145,
+	}}
+	annexH3Data = []byte{
+		0xFF, 0xD8,
+		0xFF, 0xF7, 0x00, 0x0B, 0x08, 0x00, 0x04, 0x00, 0x04, 0x01, 0x01, 0x11, 0x00,
+		0xFF, 0xDA, 0x00, 0x08, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00,
+		0xC0, 0x00, 0x00, 0x6C, 0x80, 0x20, 0x8E, 0x01, 0xC0, 0x00, 0x00, 0x57, 0x40, 0x00, 0x00, 0x6E,
+		0xE6, 0x00, 0x00, 0x01, 0xBC, 0x18, 0x00, 0x00, 0x05, 0xD8, 0x00, 0x00, 0x91, 0x60,
+		0xFF, 0xD9,
+	}
+)
+
+func TestDecode(t *testing.T) {
+	got, err := jpegls.Decode(annexH3Data)
+	if err != nil {
+		t.Fatalf("Decode unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(annexH3, got); diff != "" {
+		t.Errorf("Decode unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecode_presets(t *testing.T) {
+	// An LSE segment with the default parameters of 8 bit images does not
+	// change the decoding.
+	lse := []byte{0xFF, 0xF8, 0x00, 0x0D, 0x01, 0x00, 0xFF, 0x00, 0x03, 0x00, 0x07, 0x00, 0x15, 0x00, 0x40}
+	data := append(append(append([]byte(nil), annexH3Data[:15]...), lse...), annexH3Data[15:]...)
+	got, err := jpegls.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(annexH3, got); diff != "" {
+		t.Errorf("Decode unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	got, err := jpegls.Encode(annexH3, jpegls.Options{})
+	if err != nil {
+		t.Fatalf("Encode unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(annexH3Data, got); diff != "" {
+		t.Errorf("Encode unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestEncode_roundTrip(t *testing.T) {
+	type testCase struct {
+		name string
+		img  *jpegls.Image
+		opts jpegls.Options
+	}
+	var cases []testCase
+	for _, precision := range []int{2, 5, 8, 12, 16} {
+		for _, near := range []int{0, 1, 3} {
+			if near > (1<<uint(precision)-1)/2 {
+				continue
+			}
+			cases = append(cases, testCase{
+				name: fmt.Sprintf("precision %d, NEAR %d", precision, near),
+				img:  testImage(13, 9, 1, precision),
+				opts: jpegls.Options{Near: near},
+			})
+		}
+	}
+	for _, mode := range []jpegls.InterleaveMode{jpegls.InterleaveNone, jpegls.InterleaveLine, jpegls.InterleaveSample} {
+		for _, near := range []int{0, 2} {
+			cases = append(cases, testCase{
+				name: fmt.Sprintf("3 components, interleave mode %d, NEAR %d", mode, near),
+				img:  testImage(11, 7, 3, 8),
+				opts: jpegls.Options{Near: near, InterleaveMode: mode},
+			})
+		}
+	}
+	cases = append(cases,
+		testCase{
+			name: "single row",
+			img:  testImage(40, 1, 1, 8),
+		},
+		testCase{
+			name: "single column",
+			img:  testImage(1, 40, 1, 8),
+		},
+		testCase{
+			name: "constant",
+			img:  &jpegls.Image{Width: 300, Height: 2, Components: 1, Precision: 16, Data: make([]uint16, 600)},
+		},
+		testCase{
+			name: "noise",
+			img:  noiseImage(64, 64, 2, 16),
+			opts: jpegls.Options{InterleaveMode: jpegls.InterleaveSample},
+		},
+	)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := jpegls.Encode(tc.img, tc.opts)
+			if err != nil {
+				t.Fatalf("Encode unexpected error: %v", err)
+			}
+			got, err := jpegls.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode unexpected error: %v", err)
+			}
+			if got.Width != tc.img.Width || got.Height != tc.img.Height || got.Components != tc.img.Components || got.Precision != tc.img.Precision {
+				t.Fatalf("Decode(Encode(img)) returned a %dx%d image with %d components of %d bits, want %dx%d with %d of %d bits",
+					got.Width, got.Height, got.Components, got.Precision, tc.img.Width, tc.img.Height, tc.img.Components, tc.img.Precision)
+			}
+			if tc.opts.Near == 0 {
+				if diff := cmp.Diff(tc.img.Data, got.Data); diff != "" {
+					t.Errorf("Decode(Encode(img)) unexpected diff (-want +got):\n%s", diff)
+				}
+				return
+			}
+			for i, want := range tc.img.Data {
+				if d := int(got.Data[i]) - int(want); d > tc.opts.Near || d < -tc.opts.Near {
+					t.Fatalf("Decode(Encode(img)) sample %d is %d, want within %d of %d", i, got.Data[i], tc.opts.Near, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEncode_errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		img     *jpegls.Image
+		opts    jpegls.Options
+		wantErr error
+	}{
+		{
+			name:    "precision",
+			img:     &jpegls.Image{Width: 1, Height: 1, Components: 1, Precision: 17, Data: []uint16{0}},
+			wantErr: jpegls.ErrorUnsupported,
+		},
+		{
+			name:    "missing samples",
+			img:     &jpegls.Image{Width: 2, Height: 1, Components: 1, Precision: 8, Data: []uint16{0}},
+			wantErr: jpegls.ErrorInvalidData,
+		},
+		{
+			name:    "sample exceeds precision",
+			img:     &jpegls.Image{Width: 1, Height: 1, Components: 1, Precision: 8, Data: []uint16{256}},
+			wantErr: jpegls.ErrorInvalidData,
+		},
+		{
+			name:    "NEAR",
+			img:     &jpegls.Image{Width: 1, Height: 1, Components: 1, Precision: 2, Data: []uint16{0}},
+			opts:    jpegls.Options{Near: 2},
+			wantErr: jpegls.ErrorInvalidData,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := jpegls.Encode(tc.img, tc.opts); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Encode unexpected error. got: %v, want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecode_errors(t *testing.T) {
+	withSOS := func(sos ...byte) []byte {
+		data := append([]byte(nil), annexH3Data[:15]...)
+		data = append(data, 0xFF, 0xDA, 0x00, byte(len(sos)+2))
+		return append(append(data, sos...), annexH3Data[25:]...)
+	}
+	cases := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{
+			name:    "no SOI marker",
+			data:    annexH3Data[2:],
+			wantErr: jpegls.ErrorInvalidData,
+		},
+		{
+			name:    "truncated",
+			data:    append(append([]byte(nil), annexH3Data[:40]...), 0xFF, 0xD9),
+			wantErr: jpegls.ErrorInvalidData,
+		},
+		{
+			name:    "lossless JPEG",
+			data:    []byte{0xFF, 0xD8, 0xFF, 0xC3, 0x00, 0x0B, 0x08, 0x00, 0x01, 0x00, 0x01, 0x01, 0x01, 0x11, 0x00, 0xFF, 0xD9},
+			wantErr: jpegls.ErrorUnsupported,
+		},
+		{
+			name:    "mapping table",
+			data:    withSOS(0x01, 0x01, 0x01, 0x00, 0x00, 0x00),
+			wantErr: jpegls.ErrorUnsupported,
+		},
+		{
+			name:    "point transform",
+			data:    withSOS(0x01, 0x01, 0x00, 0x00, 0x00, 0x01),
+			wantErr: jpegls.ErrorUnsupported,
+		},
+		{
+			name:    "interleave mode of a single component",
+			data:    withSOS(0x01, 0x01, 0x00, 0x00, 0x01, 0x00),
+			wantErr: jpegls.ErrorInvalidData,
+		},
+		{
+			name:    "no scans",
+			data:    append(append([]byte(nil), annexH3Data[:15]...), 0xFF, 0xD9),
+			wantErr: jpegls.ErrorInvalidData,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := jpegls.Decode(tc.data); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Decode unexpected error. got: %v, want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data, err := jpegls.Encode(testImage(512, 512, 1, 12), jpegls.Options{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := jpegls.Decode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// testImage returns an image with samples of precision bits that has flat
+// areas (coded in run mode), smooth areas and edges.
+func testImage(width, height, components, precision int) *jpegls.Image {
+	r := rand.New(rand.NewSource(int64(width*height*components*precision + 1)))
+	img := &jpegls.Image{Width: width, Height: height, Components: components, Precision: precision}
+	max := 1 << uint(precision)
+	for i := 0; i < width*height; i++ {
+		kind := r.Intn(4)
+		for c := 0; c < components; c++ {
+			var v int
+			switch {
+			case i == 0 || kind == 0:
+				v = r.Intn(max)
+			case kind == 1:
+				v = (int(img.Data[(i-1)*components+c]) + r.Intn(7) - 3 + max) % max
+			default:
+				v = int(img.Data[(i-1)*components+c])
+			}
+			img.Data = append(img.Data, uint16(v))
+		}
+	}
+	return img
+}
+
+// noiseImage returns an image with uniformly random samples of precision bits,
+// which are expensive to code.
+func noiseImage(width, height, components, precision int) *jpegls.Image {
+	r := rand.New(rand.NewSource(int64(width * height)))
+	img := &jpegls.Image{Width: width, Height: height, Components: components, Precision: precision}
+	for i := 0; i < width*height*components; i++ {
+		img.Data = append(img.Data, uint16(r.Intn(1<<uint(precision))))
+	}
+	return img
+}
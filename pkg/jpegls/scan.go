@@ -0,0 +1,577 @@
+package jpegls
+
+import "fmt"
+
+// params are the coding parameters of a scan, see ITU T.87 A.2.1 and C.2.4.1.
+type params struct {
+	maxVal, near    int
+	t1, t2, t3      int
+	reset           int
+	rng, qbpp, lim  int
+	initialA        int
+	quantizedFactor int
+}
+
+// newParams returns the coding parameters for samples of the given precision
+// and the NEAR parameter near, using the presets (or their defaults).
+func newParams(precision, near int, pr presets) (params, error) {
+	p := params{maxVal: pr.maxVal, near: near, reset: pr.reset}
+	if p.maxVal == 0 {
+		p.maxVal = 1<<uint(precision) - 1
+	}
+	if near < 0 || near > 255 || near > p.maxVal/2 {
+		return params{}, fmt.Errorf("%w: NEAR %d for MAXVAL %d", ErrorInvalidData, near, p.maxVal)
+	}
+	if p.reset == 0 {
+		p.reset = 64
+	}
+	if p.reset < 3 {
+		return params{}, fmt.Errorf("%w: RESET %d", ErrorInvalidData, p.reset)
+	}
+	p.quantizedFactor = 2*near + 1
+	p.rng = (p.maxVal+2*near)/p.quantizedFactor + 1
+	p.qbpp = ceilLog2(p.rng)
+	bpp := ceilLog2(p.maxVal + 1)
+	if bpp < 2 {
+		bpp = 2
+	}
+	p.lim = 2 * (bpp + max(8, bpp))
+	p.initialA = max(2, (p.rng+32)/64)
+
+	// Default thresholds, see ITU T.87 C.2.4.1.1.1.
+	var t1, t2, t3 int
+	if p.maxVal >= 128 {
+		factor := (min(p.maxVal, 4095) + 128) / 256
+		t1 = clampThreshold(factor*(3-2)+2+3*near, near+1, p.maxVal)
+		t2 = clampThreshold(factor*(7-3)+3+5*near, t1, p.maxVal)
+		t3 = clampThreshold(factor*(21-4)+4+7*near, t2, p.maxVal)
+	} else {
+		factor := 256 / (p.maxVal + 1)
+		t1 = clampThreshold(max(2, 3/factor+3*near), near+1, p.maxVal)
+		t2 = clampThreshold(max(3, 7/factor+5*near), t1, p.maxVal)
+		t3 = clampThreshold(max(4, 21/factor+7*near), t2, p.maxVal)
+	}
+	p.t1, p.t2, p.t3 = t1, t2, t3
+	if pr.t1 != 0 {
+		p.t1 = pr.t1
+	}
+	if pr.t2 != 0 {
+		p.t2 = pr.t2
+	}
+	if pr.t3 != 0 {
+		p.t3 = pr.t3
+	}
+	if p.t1 < near+1 || p.t2 < p.t1 || p.t3 < p.t2 || p.t3 > p.maxVal {
+		return params{}, fmt.Errorf("%w: thresholds %d, %d, %d", ErrorInvalidData, p.t1, p.t2, p.t3)
+	}
+	return p, nil
+}
+
+// clampThreshold is the CLAMP function of ITU T.87 C.2.4.1.1.1.
+func clampThreshold(i, j, maxVal int) int {
+	if i > maxVal || i < j {
+		return j
+	}
+	return i
+}
+
+// ceilLog2 returns the smallest n such that 1<<n >= v.
+func ceilLog2(v int) int {
+	n := 0
+	for 1<<uint(n) < v {
+		n++
+	}
+	return n
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// quantize returns the region number of the local gradient d, see ITU T.87
+// A.3.3.
+func (p *params) quantize(d int) int {
+	switch {
+	case d <= -p.t3:
+		return -4
+	case d <= -p.t2:
+		return -3
+	case d <= -p.t1:
+		return -2
+	case d < -p.near:
+		return -1
+	case d <= p.near:
+		return 0
+	case d < p.t1:
+		return 1
+	case d < p.t2:
+		return 2
+	case d < p.t3:
+		return 3
+	}
+	return 4
+}
+
+// errorValue returns the prediction error e quantized for near-lossless coding
+// and reduced modulo RANGE, see ITU T.87 A.4.4 and A.4.5.
+func (p *params) errorValue(e int) int {
+	if p.near > 0 {
+		if e > 0 {
+			e = (e + p.near) / p.quantizedFactor
+		} else {
+			e = -(p.near - e) / p.quantizedFactor
+		}
+	}
+	if e < 0 {
+		e += p.rng
+	}
+	if e >= (p.rng+1)/2 {
+		e -= p.rng
+	}
+	return e
+}
+
+// reconstruct returns the sample reconstructed from the prediction px and
+// the (quantized) prediction error e.
+func (p *params) reconstruct(px, e int) int {
+	v := px + e*p.quantizedFactor
+	if v < -p.near {
+		v += p.rng * p.quantizedFactor
+	} else if v > p.maxVal+p.near {
+		v -= p.rng * p.quantizedFactor
+	}
+	if v < 0 {
+		return 0
+	}
+	if v > p.maxVal {
+		return p.maxVal
+	}
+	return v
+}
+
+// context holds the variables of a regular mode context, see ITU T.87 A.2.
+type context struct {
+	a, b, c, n int
+}
+
+// k returns the Golomb coding parameter of the context.
+func (c *context) k() int {
+	k := 0
+	for c.n<<uint(k) < c.a {
+		k++
+	}
+	return k
+}
+
+// update updates the context with the error value e, see ITU T.87 A.6.
+func (c *context) update(e int, p *params) {
+	c.b += e * p.quantizedFactor
+	c.a += abs(e)
+	if c.n == p.reset {
+		c.a >>= 1
+		c.b >>= 1
+		c.n >>= 1
+	}
+	c.n++
+	if c.b <= -c.n {
+		c.b += c.n
+		if c.c > -128 {
+			c.c--
+		}
+		if c.b <= -c.n {
+			c.b = -c.n + 1
+		}
+	} else if c.b > 0 {
+		c.b -= c.n
+		if c.c < 127 {
+			c.c++
+		}
+		if c.b > 0 {
+			c.b = 0
+		}
+	}
+}
+
+// runContext holds the variables of a run interruption context, see ITU T.87
+// A.7.2.
+type runContext struct {
+	a, n, nn int
+}
+
+// j is the order of the run length codes for each run index, see ITU T.87
+// A.7.1.2.
+var j = [32]uint{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// scan codes (encodes or decodes) the samples of the components of a scan.
+type scan struct {
+	img        *Image
+	components []int
+	mode       InterleaveMode
+	p          params
+
+	contexts    [365]context
+	runContexts [2]runContext
+	runIndex    int
+
+	// prev and cur hold the reconstructed samples of the previous and current
+	// line of each scan component, with an extra sample at each end.
+	prev, cur [][]int
+	// in holds the samples being encoded of the current line of each scan
+	// component.
+	in [][]int
+	// q holds the quantized gradients of the current sample of each scan
+	// component.
+	q [][3]int
+
+	// Exactly one of r and w is set, for decoding and encoding respectively.
+	r *bitReader
+	w *bitWriter
+}
+
+func newScan(img *Image, components []int, mode InterleaveMode, p params) *scan {
+	s := &scan{img: img, components: components, mode: mode, p: p}
+	for i := range s.contexts {
+		s.contexts[i] = context{a: p.initialA, n: 1}
+	}
+	for i := range s.runContexts {
+		s.runContexts[i] = runContext{a: p.initialA, n: 1}
+	}
+	n := len(components)
+	s.prev, s.cur, s.in, s.q = make([][]int, n), make([][]int, n), make([][]int, n), make([][3]int, n)
+	for i := range components {
+		s.prev[i] = make([]int, img.Width+2)
+		s.cur[i] = make([]int, img.Width+2)
+		s.in[i] = make([]int, img.Width)
+	}
+	return s
+}
+
+// code codes all lines of the scan.
+func (s *scan) code() error {
+	img := s.img
+	nc := img.Components
+	runIndexes := make([]int, len(s.components))
+	for y := 0; y < img.Height; y++ {
+		if s.w != nil {
+			for i, c := range s.components {
+				for x := range s.in[i] {
+					s.in[i][x] = int(img.Data[(y*img.Width+x)*nc+c])
+				}
+			}
+		}
+		if s.mode == InterleaveSample {
+			if err := s.codeLine(0, len(s.components)); err != nil {
+				return err
+			}
+		} else {
+			// Each component has its own run index, see ITU T.87 B.2.
+			for i := range s.components {
+				s.runIndex = runIndexes[i]
+				if err := s.codeLine(i, i+1); err != nil {
+					return err
+				}
+				runIndexes[i] = s.runIndex
+			}
+		}
+		if s.r != nil {
+			if s.r.overrun() {
+				return fmt.Errorf("%w: truncated scan", ErrorInvalidData)
+			}
+			for i, c := range s.components {
+				for x, v := range s.cur[i][1 : img.Width+1] {
+					img.Data[(y*img.Width+x)*nc+c] = uint16(v)
+				}
+			}
+		}
+		s.prev, s.cur = s.cur, s.prev
+	}
+	return nil
+}
+
+// codeLine codes the current line of the scan components lo to hi (exclusive),
+// which are sample interleaved if there is more than one.
+func (s *scan) codeLine(lo, hi int) error {
+	width := s.img.Width
+	for i := lo; i < hi; i++ {
+		// The samples before and after the line, see ITU T.87 A.2.1.
+		s.prev[i][width+1] = s.prev[i][width]
+		s.cur[i][0] = s.prev[i][1]
+	}
+	for x := 0; x < width; {
+		run := true
+		for i := lo; i < hi; i++ {
+			prev, cur := s.prev[i], s.cur[i]
+			q := &s.q[i]
+			q[0], q[1], q[2] = s.p.quantize(prev[x+2]-prev[x+1]), s.p.quantize(prev[x+1]-prev[x]), s.p.quantize(prev[x]-cur[x])
+			run = run && *q == [3]int{}
+		}
+		if run {
+			n, err := s.codeRun(lo, hi, x)
+			if err != nil {
+				return err
+			}
+			x += n
+			continue
+		}
+		for i := lo; i < hi; i++ {
+			rx, err := s.codeRegular(s.q[i], s.cur[i][x], s.prev[i][x+1], s.prev[i][x], s.in[i][x])
+			if err != nil {
+				return err
+			}
+			s.cur[i][x+1] = rx
+		}
+		x++
+	}
+	return nil
+}
+
+// codeRegular codes the sample ix (if encoding) in regular mode, given its
+// quantized gradients q and the reconstructed samples to the left (ra), above
+// (rb) and above left (rc). It returns the reconstructed sample. See ITU T.87
+// A.3 to A.6.
+func (s *scan) codeRegular(q [3]int, ra, rb, rc, ix int) (int, error) {
+	sign := 1
+	if q[0] < 0 || (q[0] == 0 && (q[1] < 0 || (q[1] == 0 && q[2] < 0))) {
+		q[0], q[1], q[2] = -q[0], -q[1], -q[2]
+		sign = -1
+	}
+	// q[0] is in [0, 4], and q[1] is not negative if q[0] is 0, and so on,
+	// so this maps the contexts to [0, 364].
+	ctx := &s.contexts[q[0]*81+(q[1]+4)*9+q[2]+4-40]
+
+	// Edge detecting prediction, see ITU T.87 A.4.
+	var px int
+	switch {
+	case rc >= max(ra, rb):
+		px = min(ra, rb)
+	case rc <= min(ra, rb):
+		px = max(ra, rb)
+	default:
+		px = ra + rb - rc
+	}
+	px += sign * ctx.c
+	if px < 0 {
+		px = 0
+	} else if px > s.p.maxVal {
+		px = s.p.maxVal
+	}
+
+	k := ctx.k()
+	// Error values are mapped to non negative values, see ITU T.87 A.5.2.
+	special := s.p.near == 0 && k == 0 && 2*ctx.b <= -ctx.n
+	var e int
+	if s.w != nil {
+		e = s.p.errorValue(sign * (ix - px))
+		m := e
+		if special {
+			m = -m - 1
+		}
+		if m >= 0 {
+			m = 2 * m
+		} else {
+			m = -2*m - 1
+		}
+		s.encodeValue(m, k, s.p.lim)
+	} else {
+		m, err := s.decodeValue(k, s.p.lim)
+		if err != nil {
+			return 0, err
+		}
+		if m%2 == 0 {
+			e = m / 2
+		} else {
+			e = -(m + 1) / 2
+		}
+		if special {
+			e = -e - 1
+		}
+	}
+	ctx.update(e, &s.p)
+	return s.p.reconstruct(px, sign*e), nil
+}
+
+// codeRun codes a run of samples of the scan components lo to hi (exclusive)
+// starting at x, and the sample that interrupts it, if any. It returns the
+// number of samples coded. See ITU T.87 A.7.
+func (s *scan) codeRun(lo, hi, x int) (int, error) {
+	width := s.img.Width
+	n := 0
+	if s.w != nil {
+	run:
+		for x+n < width {
+			for i := lo; i < hi; i++ {
+				if abs(s.in[i][x+n]-s.cur[i][x]) > s.p.near {
+					break run
+				}
+			}
+			n++
+		}
+		s.encodeRunLength(n, x+n == width)
+	} else {
+		var err error
+		if n, err = s.decodeRunLength(width - x); err != nil {
+			return 0, err
+		}
+	}
+	for i := lo; i < hi; i++ {
+		for k := 1; k <= n; k++ {
+			s.cur[i][x+k] = s.cur[i][x]
+		}
+	}
+	if x+n == width {
+		return n, nil
+	}
+	for i := lo; i < hi; i++ {
+		rx, err := s.codeRunInterruption(s.cur[i][x], s.prev[i][x+n+1], s.in[i][x+n])
+		if err != nil {
+			return 0, err
+		}
+		s.cur[i][x+n+1] = rx
+	}
+	if s.runIndex > 0 {
+		s.runIndex--
+	}
+	return n + 1, nil
+}
+
+// encodeRunLength encodes the length n of a run, which ends at the end of the
+// line if eol. See ITU T.87 A.7.1.2.
+func (s *scan) encodeRunLength(n int, eol bool) {
+	for n >= 1<<j[s.runIndex] {
+		s.w.writeBits(1, 1)
+		n -= 1 << j[s.runIndex]
+		if s.runIndex < 31 {
+			s.runIndex++
+		}
+	}
+	if eol {
+		if n > 0 {
+			s.w.writeBits(1, 1)
+		}
+		return
+	}
+	s.w.writeBits(n, j[s.runIndex]+1)
+}
+
+// decodeRunLength decodes the length of a run of at most remaining samples.
+func (s *scan) decodeRunLength(remaining int) (int, error) {
+	n := 0
+	for n < remaining && s.r.bits(1) == 1 {
+		count := min(1<<j[s.runIndex], remaining-n)
+		n += count
+		if count == 1<<j[s.runIndex] && s.runIndex < 31 {
+			s.runIndex++
+		}
+	}
+	if n < remaining {
+		n += s.r.bits(j[s.runIndex])
+		if n >= remaining {
+			return 0, fmt.Errorf("%w: run length exceeds the line", ErrorInvalidData)
+		}
+	}
+	return n, nil
+}
+
+// codeRunInterruption codes the sample ix (if encoding) that interrupts a run
+// of samples ra, given the reconstructed sample above it (rb). It returns the
+// reconstructed sample. See ITU T.87 A.7.2.
+func (s *scan) codeRunInterruption(ra, rb, ix int) (int, error) {
+	// Sample interleaved components are always coded in the first context.
+	riType := 0
+	if s.mode != InterleaveSample && abs(ra-rb) <= s.p.near {
+		riType = 1
+	}
+	ctx := &s.runContexts[riType]
+	px, sign := rb, 1
+	if riType == 1 {
+		px = ra
+	} else if rb < ra {
+		sign = -1
+	}
+
+	temp := ctx.a + (ctx.n>>1)*riType
+	k := 0
+	for ctx.n<<uint(k) < temp {
+		k++
+	}
+	limit := s.p.lim - int(j[s.runIndex]) - 1
+	var e, m int
+	if s.w != nil {
+		e = s.p.errorValue(sign * (ix - px))
+		mapped := 0
+		if (k == 0 && e > 0 && 2*ctx.nn < ctx.n) || (e < 0 && 2*ctx.nn >= ctx.n) || (e < 0 && k != 0) {
+			mapped = 1
+		}
+		m = 2*abs(e) - riType - mapped
+		s.encodeValue(m, k, limit)
+	} else {
+		var err error
+		if m, err = s.decodeValue(k, limit); err != nil {
+			return 0, err
+		}
+		temp := m + riType
+		mapped := temp & 1
+		e = (temp + mapped) / 2
+		if (k != 0 || 2*ctx.nn >= ctx.n) == (mapped == 1) {
+			e = -e
+		}
+	}
+
+	if e < 0 {
+		ctx.nn++
+	}
+	ctx.a += (m + 1 - riType) >> 1
+	if ctx.n == s.p.reset {
+		ctx.a >>= 1
+		ctx.n >>= 1
+		ctx.nn >>= 1
+	}
+	ctx.n++
+	return s.p.reconstruct(px, sign*e), nil
+}
+
+// encodeValue encodes the mapped error value m with the limited length Golomb
+// code of parameter k, see ITU T.87 A.5.3.
+func (s *scan) encodeValue(m, k, limit int) {
+	escape := limit - s.p.qbpp - 1
+	if high := m >> uint(k); high < escape {
+		s.w.writeZeros(high)
+		s.w.writeBits(1, 1)
+		s.w.writeBits(m, uint(k))
+		return
+	}
+	s.w.writeZeros(escape)
+	s.w.writeBits(1, 1)
+	s.w.writeBits(m-1, uint(s.p.qbpp))
+}
+
+// decodeValue decodes a mapped error value coded with the limited length
+// Golomb code of parameter k.
+func (s *scan) decodeValue(k, limit int) (int, error) {
+	escape := limit - s.p.qbpp - 1
+	high, err := s.r.zeros(escape)
+	if err != nil {
+		return 0, err
+	}
+	if high == escape {
+		return s.r.bits(uint(s.p.qbpp)) + 1, nil
+	}
+	return high<<uint(k) | s.r.bits(uint(k)), nil
+}
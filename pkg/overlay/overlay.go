@@ -0,0 +1,307 @@
+// Package overlay extracts overlay planes from a DICOM Dataset, and renders
+// them as masks or images that can be composited onto image frames.
+//
+// Overlay planes are stored in the repeating groups 6000-601E, with each group
+// holding one (possibly multi-frame) overlay. See PS3.3 C.9.2.
+package overlay
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/internal/dsvalue"
+	"github.com/suyashkumar/dicom/pkg/frame"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+const (
+	// FirstGroup is the group of the first overlay plane.
+	FirstGroup = 0x6000
+	// LastGroup is the group of the last overlay plane.
+	LastGroup = 0x601E
+)
+
+var (
+	// ErrorNoOverlayData indicates that an overlay has neither OverlayData nor
+	// overlay bits embedded in the PixelData.
+	ErrorNoOverlayData = errors.New("overlay has no OverlayData or embedded overlay bits")
+	// ErrorOverlayDataTooShort indicates that the OverlayData of an overlay is
+	// too short for its dimensions and number of frames.
+	ErrorOverlayDataTooShort = errors.New("OverlayData is too short for the overlay dimensions")
+	// ErrorNoFrame indicates that an overlay has no frame that applies to the
+	// requested image frame.
+	ErrorNoFrame = errors.New("overlay has no frame for the image frame")
+)
+
+// Overlay is a single overlay plane, i.e., the contents of one overlay group.
+type Overlay struct {
+	// Group is the repeating group the overlay is stored in, 0x6000 through
+	// 0x601E.
+	Group uint16
+	Rows  int
+	Cols  int
+	// Type is "G" for a graphics overlay, or "R" for a region of interest.
+	Type        string
+	Subtype     string
+	Label       string
+	Description string
+	// OriginRow and OriginCol locate the first overlay pixel relative to the
+	// image, where 1, 1 is the top left image pixel. They may be negative or
+	// zero if the overlay extends beyond the image.
+	OriginRow int
+	OriginCol int
+	// BitsAllocated is 1 for overlays held in OverlayData. Larger values
+	// indicate that the overlay is embedded in the BitPosition bit of the
+	// PixelData samples (which is retired, but still found in older files).
+	BitsAllocated int
+	BitPosition   int
+	// ImageFrameOrigin is the 1-based index of the image frame that the first
+	// overlay frame applies to.
+	ImageFrameOrigin int
+	// Frames holds one Mask per overlay frame.
+	Frames []*Mask
+}
+
+// Embedded indicates if the overlay was embedded in unused bits of the
+// PixelData, rather than held in OverlayData.
+func (o *Overlay) Embedded() bool {
+	return o.BitsAllocated > 1
+}
+
+// FrameFor returns the overlay frame that applies to the image frame with
+// 0-based index imageFrame, or ErrorNoFrame if there is none.
+func (o *Overlay) FrameFor(imageFrame int) (*Mask, error) {
+	i := imageFrame - (o.ImageFrameOrigin - 1)
+	if i < 0 || i >= len(o.Frames) {
+		return nil, fmt.Errorf("%w: overlay %04x has %d frames starting at image frame %d, got image frame %d",
+			ErrorNoFrame, o.Group, len(o.Frames), o.ImageFrameOrigin-1, imageFrame)
+	}
+	return o.Frames[i], nil
+}
+
+// Composite draws the overlay frame that applies to the image frame with
+// 0-based index imageFrame onto img (for example, from NativeFrame.GetImage),
+// positioned according to the overlay origin. Overlay pixels that are set are
+// drawn in color c. img is not modified; a new RGBA image is returned.
+func (o *Overlay) Composite(img image.Image, imageFrame int, c color.Color) (*image.RGBA, error) {
+	m, err := o.FrameFor(imageFrame)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	draw.Draw(out, bounds, img, bounds.Min, draw.Src)
+	offset := bounds.Min.Add(image.Pt(o.OriginCol-1, o.OriginRow-1))
+	draw.DrawMask(out, m.Bounds().Add(offset), image.NewUniform(c), image.Point{}, m, image.Point{}, draw.Over)
+	return out, nil
+}
+
+// CompositeFrame is like Composite, but renders the image from the NativeFrame
+// f using its default processing first.
+func (o *Overlay) CompositeFrame(f *frame.NativeFrame, imageFrame int, c color.Color) (*image.RGBA, error) {
+	img, err := f.GetImage()
+	if err != nil {
+		return nil, err
+	}
+	return o.Composite(img, imageFrame, c)
+}
+
+// Mask is a single frame of an overlay plane. It implements image.Image, with
+// overlay pixels that are set being opaque, and all other pixels transparent,
+// so it can be used directly as a mask with image/draw.
+type Mask struct {
+	Rows int
+	Cols int
+	// Data holds the overlay pixels in row-major order, true where the overlay
+	// is set.
+	Data []bool
+}
+
+// IsSet indicates if the overlay pixel at row, col (0-based) is set.
+func (m *Mask) IsSet(row, col int) bool {
+	if row < 0 || row >= m.Rows || col < 0 || col >= m.Cols {
+		return false
+	}
+	return m.Data[row*m.Cols+col]
+}
+
+// ColorModel implements image.Image.
+func (m *Mask) ColorModel() color.Model { return color.AlphaModel }
+
+// Bounds implements image.Image.
+func (m *Mask) Bounds() image.Rectangle { return image.Rect(0, 0, m.Cols, m.Rows) }
+
+// At implements image.Image.
+func (m *Mask) At(x, y int) color.Color {
+	if m.IsSet(y, x) {
+		return color.Alpha{A: 0xFF}
+	}
+	return color.Alpha{}
+}
+
+// GetImage returns a grayscale image.Image of the mask, which is white where
+// the overlay is set and black elsewhere.
+func (m *Mask) GetImage() (image.Image, error) {
+	img := image.NewGray(m.Bounds())
+	for i, set := range m.Data {
+		if set {
+			img.Pix[i] = 0xFF
+		}
+	}
+	return img, nil
+}
+
+// Parse returns the overlay planes in ds, in order of their group. Overlays
+// embedded in unused bits of the PixelData are extracted from the native
+// PixelData frames in ds.
+func Parse(ds *dicom.Dataset) ([]*Overlay, error) {
+	var overlays []*Overlay
+	for group := uint16(FirstGroup); group <= LastGroup; group += 2 {
+		if _, err := ds.FindElementByTag(inGroup(tag.OverlayRows, group)); err != nil {
+			continue
+		}
+		o, err := parseOverlay(ds, group)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %04x: %w", group, err)
+		}
+		overlays = append(overlays, o)
+	}
+	return overlays, nil
+}
+
+func parseOverlay(ds *dicom.Dataset, group uint16) (*Overlay, error) {
+	o := &Overlay{Group: group, BitsAllocated: 1, ImageFrameOrigin: 1}
+	var err error
+	if o.Rows, err = dsvalue.Int(ds, inGroup(tag.OverlayRows, group), 0); err != nil {
+		return nil, err
+	}
+	if o.Cols, err = dsvalue.Int(ds, inGroup(tag.OverlayColumns, group), 0); err != nil {
+		return nil, err
+	}
+	if o.BitsAllocated, err = dsvalue.Int(ds, inGroup(tag.OverlayBitsAllocated, group), 1); err != nil {
+		return nil, err
+	}
+	if o.BitPosition, err = dsvalue.Int(ds, inGroup(tag.OverlayBitPosition, group), 0); err != nil {
+		return nil, err
+	}
+	if o.ImageFrameOrigin, err = dsvalue.Int(ds, inGroup(tag.ImageFrameOrigin, group), 1); err != nil {
+		return nil, err
+	}
+	numFrames, err := dsvalue.Int(ds, inGroup(tag.NumberOfFramesInOverlay, group), 0)
+	if err != nil {
+		return nil, err
+	}
+	if e, err := ds.FindElementByTag(inGroup(tag.OverlayOrigin, group)); err == nil {
+		if e.Value.ValueType() != dicom.Ints || len(dicom.MustGetInts(e.Value)) != 2 {
+			return nil, fmt.Errorf("invalid value for %v: %v", e.Tag, e.Value)
+		}
+		origin := dicom.MustGetInts(e.Value)
+		o.OriginRow, o.OriginCol = origin[0], origin[1]
+	} else {
+		o.OriginRow, o.OriginCol = 1, 1
+	}
+	o.Type = dsvalue.String(ds, inGroup(tag.OverlayType, group))
+	o.Subtype = dsvalue.String(ds, inGroup(tag.OverlaySubtype, group))
+	o.Label = dsvalue.String(ds, inGroup(tag.OverlayLabel, group))
+	o.Description = dsvalue.String(ds, inGroup(tag.OverlayDescription, group))
+	if o.Rows < 0 || o.Cols < 0 || numFrames < 0 || o.ImageFrameOrigin < 1 {
+		return nil, fmt.Errorf("invalid overlay of %d frames of %dx%d pixels starting at image frame %d",
+			numFrames, o.Rows, o.Cols, o.ImageFrameOrigin)
+	}
+
+	if data, err := ds.FindElementByTag(inGroup(tag.OverlayData, group)); err == nil {
+		if numFrames == 0 {
+			numFrames = 1
+		}
+		if data.Value.ValueType() != dicom.Bytes {
+			return nil, fmt.Errorf("unexpected ValueType for %v: %v", data.Tag, data.Value.ValueType())
+		}
+		o.Frames, err = unpackBits(dicom.MustGetBytes(data.Value), o.Rows, o.Cols, numFrames)
+		return o, err
+	}
+	if !o.Embedded() {
+		return nil, ErrorNoOverlayData
+	}
+	o.Frames, err = extractEmbedded(ds, o, numFrames)
+	return o, err
+}
+
+// unpackBits unpacks numFrames frames of rows by cols 1-bit overlay pixels from
+// data. The pixels are packed consecutively (without padding between frames),
+// starting from the least significant bit of each byte. OverlayData is held
+// little endian, so this is also the bit order of OW OverlayData.
+func unpackBits(data []byte, rows, cols, numFrames int) ([]*Mask, error) {
+	pixels := rows * cols
+	if int64(pixels)*int64(numFrames) > int64(len(data))*8 {
+		return nil, fmt.Errorf("%w: %d bytes for %d frames of %dx%d pixels", ErrorOverlayDataTooShort, len(data), numFrames, rows, cols)
+	}
+	frames := make([]*Mask, numFrames)
+	bit := 0
+	for i := range frames {
+		m := &Mask{Rows: rows, Cols: cols, Data: make([]bool, pixels)}
+		for p := range m.Data {
+			m.Data[p] = data[bit/8]&(1<<(bit%8)) != 0
+			bit++
+		}
+		frames[i] = m
+	}
+	return frames, nil
+}
+
+// extractEmbedded extracts the overlay bits embedded in the BitPosition bit of
+// the first sample of each pixel in the native PixelData frames in ds. If the
+// number of overlay frames is not specified, there is an overlay frame for each
+// image frame from ImageFrameOrigin on.
+func extractEmbedded(ds *dicom.Dataset, o *Overlay, numFrames int) ([]*Mask, error) {
+	pd, err := ds.FindElementByTag(tag.PixelData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrorNoOverlayData, err)
+	}
+	if pd.Value.ValueType() != dicom.PixelData {
+		return nil, fmt.Errorf("%w: unexpected PixelData ValueType %v", ErrorNoOverlayData, pd.Value.ValueType())
+	}
+	info := dicom.MustGetPixelDataInfo(pd.Value)
+	if info.IsEncapsulated {
+		return nil, fmt.Errorf("%w: overlay bits cannot be extracted from encapsulated PixelData", ErrorNoOverlayData)
+	}
+	first := o.ImageFrameOrigin - 1
+	if numFrames == 0 {
+		numFrames = info.NumFrames() - first
+	}
+	if numFrames <= 0 || first+numFrames > info.NumFrames() {
+		return nil, fmt.Errorf("%d overlay frames starting at image frame %d do not fit in the %d image frames",
+			numFrames, first, info.NumFrames())
+	}
+	frames := make([]*Mask, numFrames)
+	for i := range frames {
+		f, err := info.Frame(first + i)
+		if err != nil {
+			return nil, err
+		}
+		nf, err := f.GetNativeFrame()
+		if err != nil {
+			return nil, err
+		}
+		if nf.Rows != o.Rows || nf.Cols != o.Cols {
+			return nil, fmt.Errorf("embedded overlay of %dx%d pixels does not match the %dx%d image frame",
+				o.Rows, o.Cols, nf.Rows, nf.Cols)
+		}
+		m := &Mask{Rows: o.Rows, Cols: o.Cols, Data: make([]bool, nf.NumPixels())}
+		if nf.NumSamplesPerPixel() > 0 {
+			for p := range m.Data {
+				m.Data[p] = nf.At(p, 0)&(1<<uint(o.BitPosition)) != 0
+			}
+		}
+		frames[i] = m
+	}
+	return frames, nil
+}
+
+// inGroup returns the tag t in the repeating group.
+func inGroup(t tag.Tag, group uint16) tag.Tag {
+	return tag.Tag{Group: group, Element: t.Element}
+}
@@ -0,0 +1,215 @@
+package overlay_test
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/frame"
+	"github.com/suyashkumar/dicom/pkg/overlay"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		elements []*dicom.Element
+		want     []*overlay.Overlay
+	}{
+		{
+			name: "single frame",
+			elements: []*dicom.Element{
+				mustNewElement(t, inGroup(tag.OverlayRows, 0x6002), []int{2}),
+				mustNewElement(t, inGroup(tag.OverlayColumns, 0x6002), []int{3}),
+				mustNewElement(t, inGroup(tag.OverlayType, 0x6002), []string{"G"}),
+				mustNewElement(t, inGroup(tag.OverlayOrigin, 0x6002), []int{2, -1}),
+				mustNewElement(t, inGroup(tag.OverlayBitsAllocated, 0x6002), []int{1}),
+				mustNewElement(t, inGroup(tag.OverlayBitPosition, 0x6002), []int{0}),
+				// Pixels 0, 2 and 3 are set.
+				mustNewElement(t, inGroup(tag.OverlayData, 0x6002), []byte{0b00001101, 0x00}),
+			},
+			want: []*overlay.Overlay{{
+				Group: 0x6002, Rows: 2, Cols: 3, Type: "G", OriginRow: 2, OriginCol: -1,
+				BitsAllocated: 1, ImageFrameOrigin: 1,
+				Frames: []*overlay.Mask{{Rows: 2, Cols: 3, Data: []bool{true, false, true, true, false, false}}},
+			}},
+		},
+		{
+			name: "multi-frame",
+			elements: []*dicom.Element{
+				mustNewElement(t, inGroup(tag.OverlayRows, 0x6000), []int{1}),
+				mustNewElement(t, inGroup(tag.OverlayColumns, 0x6000), []int{5}),
+				mustNewElement(t, inGroup(tag.NumberOfFramesInOverlay, 0x6000), []string{"2"}),
+				mustNewElement(t, inGroup(tag.ImageFrameOrigin, 0x6000), []int{3}),
+				// Frames are not padded to whole bytes: the second frame
+				// starts at bit 5.
+				mustNewElement(t, inGroup(tag.OverlayData, 0x6000), []byte{0b11100001, 0b00000001}),
+				mustNewElement(t, inGroup(tag.OverlayRows, 0x601E), []int{1}),
+				mustNewElement(t, inGroup(tag.OverlayColumns, 0x601E), []int{1}),
+				mustNewElement(t, inGroup(tag.OverlayData, 0x601E), []byte{0x01, 0x00}),
+			},
+			want: []*overlay.Overlay{
+				{
+					Group: 0x6000, Rows: 1, Cols: 5, OriginRow: 1, OriginCol: 1, BitsAllocated: 1, ImageFrameOrigin: 3,
+					Frames: []*overlay.Mask{
+						{Rows: 1, Cols: 5, Data: []bool{true, false, false, false, false}},
+						{Rows: 1, Cols: 5, Data: []bool{true, true, true, true, false}},
+					},
+				},
+				{
+					Group: 0x601E, Rows: 1, Cols: 1, OriginRow: 1, OriginCol: 1, BitsAllocated: 1, ImageFrameOrigin: 1,
+					Frames: []*overlay.Mask{{Rows: 1, Cols: 1, Data: []bool{true}}},
+				},
+			},
+		},
+		{
+			name: "embedded in PixelData",
+			elements: []*dicom.Element{
+				mustNewElement(t, inGroup(tag.OverlayRows, 0x6004), []int{2}),
+				mustNewElement(t, inGroup(tag.OverlayColumns, 0x6004), []int{2}),
+				mustNewElement(t, inGroup(tag.OverlayBitsAllocated, 0x6004), []int{16}),
+				mustNewElement(t, inGroup(tag.OverlayBitPosition, 0x6004), []int{12}),
+				mustNewElement(t, tag.PixelData, dicom.PixelDataInfo{Frames: []frame.Frame{
+					nativeFrame(2, 2, 0x1001, 0x0FFF, 0x0000, 0xF000),
+					nativeFrame(2, 2, 0x0000, 0x1000, 0x1000, 0x0000),
+				}}),
+			},
+			want: []*overlay.Overlay{{
+				Group: 0x6004, Rows: 2, Cols: 2, OriginRow: 1, OriginCol: 1, BitsAllocated: 16, BitPosition: 12, ImageFrameOrigin: 1,
+				Frames: []*overlay.Mask{
+					{Rows: 2, Cols: 2, Data: []bool{true, false, false, true}},
+					{Rows: 2, Cols: 2, Data: []bool{false, true, true, false}},
+				},
+			}},
+		},
+		{
+			name: "no overlays",
+			elements: []*dicom.Element{
+				mustNewElement(t, tag.Rows, []int{2}),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := overlay.Parse(&dicom.Dataset{Elements: tc.elements})
+			if err != nil {
+				t.Fatalf("Parse unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Parse returned unexpected overlays. diff: %v", diff)
+			}
+		})
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		elements []*dicom.Element
+		wantErr  error
+	}{
+		{
+			name: "OverlayData too short",
+			elements: []*dicom.Element{
+				mustNewElement(t, inGroup(tag.OverlayRows, 0x6000), []int{4}),
+				mustNewElement(t, inGroup(tag.OverlayColumns, 0x6000), []int{5}),
+				mustNewElement(t, inGroup(tag.OverlayData, 0x6000), []byte{0x00, 0x00}),
+			},
+			wantErr: overlay.ErrorOverlayDataTooShort,
+		},
+		{
+			name: "no OverlayData",
+			elements: []*dicom.Element{
+				mustNewElement(t, inGroup(tag.OverlayRows, 0x6000), []int{4}),
+				mustNewElement(t, inGroup(tag.OverlayColumns, 0x6000), []int{5}),
+			},
+			wantErr: overlay.ErrorNoOverlayData,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := overlay.Parse(&dicom.Dataset{Elements: tc.elements})
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Parse unexpected error. got: %v, want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestOverlay_Composite(t *testing.T) {
+	o := &overlay.Overlay{
+		Group: 0x6000, Rows: 2, Cols: 2, OriginRow: 2, OriginCol: 3, BitsAllocated: 1, ImageFrameOrigin: 2,
+		Frames: []*overlay.Mask{{Rows: 2, Cols: 2, Data: []bool{true, false, false, true}}},
+	}
+	nf := frame.NativeFrame{Rows: 3, Cols: 4, BitsPerSample: 16, Data: make([][]int, 12)}
+	for i := range nf.Data {
+		nf.Data[i] = []int{0x8080}
+	}
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+
+	got, err := o.CompositeFrame(&nf, 1, red)
+	if err != nil {
+		t.Fatalf("CompositeFrame unexpected error: %v", err)
+	}
+	gray := color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			want := gray
+			// The overlay starts at row 2, column 3 (1-based), and only its
+			// top left and bottom right pixels are set.
+			if (x == 2 && y == 1) || (x == 3 && y == 2) {
+				want = red
+			}
+			if c := got.RGBAAt(x, y); c != want {
+				t.Errorf("CompositeFrame unexpected color at (%d, %d). got: %v, want: %v", x, y, c, want)
+			}
+		}
+	}
+
+	if _, err := o.CompositeFrame(&nf, 0, red); !errors.Is(err, overlay.ErrorNoFrame) {
+		t.Errorf("CompositeFrame for an image frame without an overlay frame unexpected error. got: %v, want: %v", err, overlay.ErrorNoFrame)
+	}
+}
+
+func TestMask_GetImage(t *testing.T) {
+	m := &overlay.Mask{Rows: 2, Cols: 2, Data: []bool{false, true, true, false}}
+	img, err := m.GetImage()
+	if err != nil {
+		t.Fatalf("GetImage unexpected error: %v", err)
+	}
+	gray, ok := img.(*image.Gray)
+	if !ok {
+		t.Fatalf("GetImage did not return an image convertible to Gray")
+	}
+	if diff := cmp.Diff([]uint8{0x00, 0xFF, 0xFF, 0x00}, gray.Pix); diff != "" {
+		t.Errorf("GetImage returned unexpected pixels. diff: %v", diff)
+	}
+	if a := m.At(1, 0).(color.Alpha); a.A != 0xFF {
+		t.Errorf("At(1, 0) unexpected alpha. got: %v, want: 0xFF", a.A)
+	}
+}
+
+func inGroup(t tag.Tag, group uint16) tag.Tag {
+	return tag.Tag{Group: group, Element: t.Element}
+}
+
+func nativeFrame(rows, cols int, pixels ...int) frame.Frame {
+	nf := frame.NativeFrame{Rows: rows, Cols: cols, BitsPerSample: 16}
+	for _, p := range pixels {
+		nf.Data = append(nf.Data, []int{p})
+	}
+	return frame.Frame{NativeData: nf}
+}
+
+func mustNewElement(t *testing.T, tg tag.Tag, data interface{}) *dicom.Element {
+	t.Helper()
+	elem, err := dicom.NewElement(tg, data)
+	if err != nil {
+		t.Fatalf("dicom.NewElement(%v, %v) unexpected error: %v", tg, data, err)
+	}
+	return elem
+}
@@ -0,0 +1,150 @@
+package pixel
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/internal/dsvalue"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+// LUT is a lookup table, as used by the Modality LUT and VOI LUT. See PS3.3
+// C.11.1.1.
+type LUT struct {
+	// FirstValue is the first input value mapped by the LUT. Input values
+	// below FirstValue map to the first entry, and input values past the last
+	// entry map to the last entry.
+	FirstValue int
+	// BitsPerEntry is the number of bits in each entry of Data.
+	BitsPerEntry int
+	Data         []int
+	Explanation  string
+}
+
+// Lookup returns the LUT entry for the input value v.
+func (l *LUT) Lookup(v int) int {
+	i := v - l.FirstValue
+	if i < 0 {
+		i = 0
+	} else if i >= len(l.Data) {
+		i = len(l.Data) - 1
+	}
+	return l.Data[i]
+}
+
+// parseLUT parses the LUT in item, which holds LUTDescriptor, LUTData and
+// LUTExplanation. signed indicates if the first value mapped is signed.
+func parseLUT(item *dicom.Dataset, signed bool) (*LUT, error) {
+	desc, err := item.FindElementByTag(tag.LUTDescriptor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrorInvalidLUT, err)
+	}
+	if desc.Value.ValueType() != dicom.Ints || len(dicom.MustGetInts(desc.Value)) != 3 {
+		return nil, fmt.Errorf("%w: invalid LUTDescriptor %v", ErrorInvalidLUT, desc.Value)
+	}
+	d := dicom.MustGetInts(desc.Value)
+	numEntries, firstValue, bits := d[0], d[1], d[2]
+	if numEntries == 0 {
+		// 0 means 2^16 entries, as the number of entries is a US.
+		numEntries = 1 << 16
+	}
+	if signed && firstValue >= 1<<15 {
+		// The first value mapped is SS for signed stored values, but may
+		// have been read as US.
+		firstValue -= 1 << 16
+	}
+	if bits < 1 || bits > 16 {
+		return nil, fmt.Errorf("%w: %d bits per entry", ErrorInvalidLUT, bits)
+	}
+
+	data, err := item.FindElementByTag(tag.LUTData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrorInvalidLUT, err)
+	}
+	lut := &LUT{FirstValue: firstValue, BitsPerEntry: bits, Explanation: dsvalue.String(item, tag.LUTExplanation)}
+	switch data.Value.ValueType() {
+	case dicom.Ints:
+		lut.Data = dicom.MustGetInts(data.Value)
+	case dicom.Bytes:
+		b := dicom.MustGetBytes(data.Value)
+		if bits <= 8 && len(b) == numEntries {
+			// Some implementations pack two 8 bit entries into each OW word.
+			for _, v := range b {
+				lut.Data = append(lut.Data, int(v))
+			}
+			break
+		}
+		// OW values are held little endian.
+		for i := 0; i+1 < len(b); i += 2 {
+			lut.Data = append(lut.Data, int(binary.LittleEndian.Uint16(b[i:])))
+		}
+	default:
+		return nil, fmt.Errorf("%w: unexpected LUTData ValueType %v", ErrorInvalidLUT, data.Value.ValueType())
+	}
+	if len(lut.Data) < numEntries {
+		return nil, fmt.Errorf("%w: LUTData has %d entries, LUTDescriptor has %d", ErrorInvalidLUT, len(lut.Data), numEntries)
+	}
+	lut.Data = lut.Data[:numEntries]
+	mask := 1<<uint(bits) - 1
+	for i, v := range lut.Data {
+		lut.Data[i] = v & mask
+	}
+	return lut, nil
+}
+
+// ModalityLUT is the Modality LUT transformation, which maps stored values to
+// modality specific output units. It is either a linear rescale, or an
+// explicit lookup table (if LUT is set). See PS3.3 C.11.1.
+type ModalityLUT struct {
+	Slope     float64
+	Intercept float64
+	// Type is the RescaleType or ModalityLUTType, describing the output units,
+	// e.g., "HU" for Hounsfield units or "US" for unspecified.
+	Type string
+	LUT  *LUT
+}
+
+// IsIdentity indicates if the Modality LUT leaves stored values unchanged.
+func (m *ModalityLUT) IsIdentity() bool {
+	return m.LUT == nil && m.Slope == 1 && m.Intercept == 0
+}
+
+// Apply returns the output value for the stored value v.
+func (m *ModalityLUT) Apply(v int) float64 {
+	if m.LUT != nil {
+		return float64(m.LUT.Lookup(v))
+	}
+	return float64(v)*m.Slope + m.Intercept
+}
+
+// parseModalityLUT returns the Modality LUT described in ds, which is either a
+// Dataset or an Item of the PixelValueTransformationSequence. An explicit
+// ModalityLUTSequence takes precedence over RescaleSlope and RescaleIntercept.
+// If neither is present, the identity transformation is returned.
+func parseModalityLUT(ds *dicom.Dataset, format Format) (*ModalityLUT, error) {
+	m := &ModalityLUT{Slope: 1, Type: dsvalue.String(ds, tag.RescaleType)}
+	if lutItem, err := item(ds, tag.ModalityLUTSequence, 0); err == nil {
+		if m.LUT, err = parseLUT(lutItem, format.Signed); err != nil {
+			return nil, fmt.Errorf("ModalityLUTSequence: %w", err)
+		}
+		m.Type = dsvalue.String(lutItem, tag.ModalityLUTType)
+		return m, nil
+	}
+
+	slope, err := floatValues(ds, tag.RescaleSlope)
+	if err != nil {
+		return nil, err
+	}
+	if len(slope) > 0 {
+		m.Slope = slope[0]
+	}
+	intercept, err := floatValues(ds, tag.RescaleIntercept)
+	if err != nil {
+		return nil, err
+	}
+	if len(intercept) > 0 {
+		m.Intercept = intercept[0]
+	}
+	return m, nil
+}
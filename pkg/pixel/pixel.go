@@ -0,0 +1,366 @@
+// Package pixel implements the grayscale pixel transformation pipeline, which
+// turns the stored values in native frames into meaningful output values. See
+// PS3.3 C.11 and PS3.4 N.2.
+//
+// The first step of the pipeline is the Modality LUT, which maps stored values
+// to modality specific units (such as Hounsfield units for CT), using either a
+// linear rescale or an explicit lookup table. The VOI LUT then selects the
+// range of values of interest with a window or lookup table, which Render maps
+// to 8-bit display values, inverting them for MONOCHROME1.
+package pixel
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/internal/dsvalue"
+	"github.com/suyashkumar/dicom/pkg/frame"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+var (
+	// ErrorUnsupportedFormat indicates that the pixel format described in the
+	// Dataset is not supported by the pipeline.
+	ErrorUnsupportedFormat = errors.New("unsupported pixel format")
+	// ErrorFrameMismatch indicates that a frame does not match the pixel
+	// format described in the Dataset.
+	ErrorFrameMismatch = errors.New("frame does not match the pixel format")
+	// ErrorInvalidLUT indicates that a lookup table in the Dataset is invalid.
+	ErrorInvalidLUT = errors.New("invalid lookup table")
+	// ErrorInvalidWindow indicates that a VOI window is invalid.
+	ErrorInvalidWindow = errors.New("invalid window")
+)
+
+// Format describes how pixel samples are stored, from the Image Pixel Module.
+// See PS3.3 C.7.6.3.
+type Format struct {
+	SamplesPerPixel int
+	BitsAllocated   int
+	BitsStored      int
+	HighBit         int
+	// Signed indicates that samples are two's complement signed integers
+	// (PixelRepresentation 1).
+	Signed                    bool
+	PhotometricInterpretation string
+}
+
+// ParseFormat returns the pixel Format described in ds. BitsStored defaults to
+// BitsAllocated, and HighBit to BitsStored-1.
+func ParseFormat(ds *dicom.Dataset) (Format, error) {
+	var f Format
+	var err error
+	if f.BitsAllocated, err = dsvalue.Int(ds, tag.BitsAllocated, 0); err != nil {
+		return Format{}, err
+	}
+	if f.SamplesPerPixel, err = dsvalue.Int(ds, tag.SamplesPerPixel, 1); err != nil {
+		return Format{}, err
+	}
+	if f.BitsStored, err = dsvalue.Int(ds, tag.BitsStored, f.BitsAllocated); err != nil {
+		return Format{}, err
+	}
+	if f.HighBit, err = dsvalue.Int(ds, tag.HighBit, f.BitsStored-1); err != nil {
+		return Format{}, err
+	}
+	pixelRepresentation, err := dsvalue.Int(ds, tag.PixelRepresentation, 0)
+	if err != nil {
+		return Format{}, err
+	}
+	f.Signed = pixelRepresentation == 1
+	f.PhotometricInterpretation = dsvalue.String(ds, tag.PhotometricInterpretation)
+
+	if f.BitsAllocated <= 0 || f.BitsAllocated > 32 || f.BitsStored <= 0 || f.BitsStored > f.BitsAllocated ||
+		f.HighBit < f.BitsStored-1 || f.HighBit >= f.BitsAllocated || f.SamplesPerPixel <= 0 {
+		return Format{}, fmt.Errorf("%w: BitsAllocated %d, BitsStored %d, HighBit %d, SamplesPerPixel %d",
+			ErrorUnsupportedFormat, f.BitsAllocated, f.BitsStored, f.HighBit, f.SamplesPerPixel)
+	}
+	return f, nil
+}
+
+// StoredValue returns the stored value held in the raw sample v (as found in
+// NativeFrame.At), which is its BitsStored bits ending at HighBit, sign
+// extended if the Format is Signed. Any other bits, such as embedded overlays,
+// are ignored.
+func (f Format) StoredValue(v int) int {
+	shift := uint(f.HighBit + 1 - f.BitsStored)
+	mask := 1<<uint(f.BitsStored) - 1
+	v = (v >> shift) & mask
+	if f.Signed && v&(1<<uint(f.BitsStored-1)) != 0 {
+		v -= 1 << uint(f.BitsStored)
+	}
+	return v
+}
+
+// MinStoredValue returns the smallest stored value the Format can hold.
+func (f Format) MinStoredValue() int {
+	if f.Signed {
+		return -(1 << uint(f.BitsStored-1))
+	}
+	return 0
+}
+
+// MaxStoredValue returns the largest stored value the Format can hold.
+func (f Format) MaxStoredValue() int {
+	if f.Signed {
+		return 1<<uint(f.BitsStored-1) - 1
+	}
+	return 1<<uint(f.BitsStored) - 1
+}
+
+// RealFrame holds the real-valued output of a pipeline step for a single
+// grayscale frame.
+type RealFrame struct {
+	Rows int
+	Cols int
+	// Data holds one value per pixel, in row-major order.
+	Data []float64
+}
+
+// At returns the value of the pixel at row, col (0-based).
+func (r *RealFrame) At(row, col int) float64 {
+	return r.Data[row*r.Cols+col]
+}
+
+// Pipeline applies the pixel transformations described in a Dataset to its
+// native frames.
+type Pipeline struct {
+	Format Format
+	// Modality is the Modality LUT shared by all frames, unless overridden
+	// for a frame in the PerFrameFunctionalGroupsSequence.
+	Modality *ModalityLUT
+	// VOI is the VOI LUT shared by all frames, unless overridden for a frame
+	// in the PerFrameFunctionalGroupsSequence.
+	VOI *VOILUT
+	// Inverse indicates that minimum output values are displayed as white,
+	// for MONOCHROME1 or an INVERSE PresentationLUTShape.
+	Inverse bool
+	// perFrameModality and perFrameVOI hold the per-frame Modality and VOI
+	// LUTs of enhanced multi-frame images, where set.
+	perFrameModality []*ModalityLUT
+	perFrameVOI      []*VOILUT
+}
+
+// NewPipeline returns a Pipeline for the frames in ds, based on the pixel
+// format and transformation attributes in ds. For enhanced multi-frame images,
+// the Shared and PerFrame functional groups are used.
+func NewPipeline(ds *dicom.Dataset) (*Pipeline, error) {
+	format, err := ParseFormat(ds)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pipeline{
+		Format:  format,
+		Inverse: format.PhotometricInterpretation == "MONOCHROME1" || dsvalue.String(ds, tag.PresentationLUTShape) == "INVERSE",
+	}
+	if p.Modality, err = parseModalityLUT(ds, format); err != nil {
+		return nil, err
+	}
+	if shared, ok := functionalGroup(ds, tag.SharedFunctionalGroupsSequence, 0, tag.PixelValueTransformationSequence); ok {
+		if p.Modality, err = parseModalityLUT(shared, format); err != nil {
+			return nil, err
+		}
+	}
+
+	// The first values mapped by VOI LUTs are signed if the Modality LUT
+	// output may be negative.
+	signed := format.Signed || p.Modality.Apply(format.MinStoredValue()) < 0
+	if p.VOI, err = parseVOILUT(ds, signed); err != nil {
+		return nil, err
+	}
+	if shared, ok := functionalGroup(ds, tag.SharedFunctionalGroupsSequence, 0, tag.FrameVOILUTSequence); ok {
+		if p.VOI, err = parseVOILUT(shared, signed); err != nil {
+			return nil, err
+		}
+	}
+
+	n := numItems(ds, tag.PerFrameFunctionalGroupsSequence)
+	p.perFrameModality = make([]*ModalityLUT, n)
+	p.perFrameVOI = make([]*VOILUT, n)
+	for i := 0; i < n; i++ {
+		if fg, ok := functionalGroup(ds, tag.PerFrameFunctionalGroupsSequence, i, tag.PixelValueTransformationSequence); ok {
+			if p.perFrameModality[i], err = parseModalityLUT(fg, format); err != nil {
+				return nil, fmt.Errorf("frame %d: %w", i, err)
+			}
+		}
+		if fg, ok := functionalGroup(ds, tag.PerFrameFunctionalGroupsSequence, i, tag.FrameVOILUTSequence); ok {
+			if p.perFrameVOI[i], err = parseVOILUT(fg, signed); err != nil {
+				return nil, fmt.Errorf("frame %d: %w", i, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// ModalityLUTFor returns the Modality LUT that applies to the frame with
+// 0-based index frameIndex.
+func (p *Pipeline) ModalityLUTFor(frameIndex int) *ModalityLUT {
+	if frameIndex >= 0 && frameIndex < len(p.perFrameModality) && p.perFrameModality[frameIndex] != nil {
+		return p.perFrameModality[frameIndex]
+	}
+	return p.Modality
+}
+
+// VOILUTFor returns the VOI LUT that applies to the frame with 0-based index
+// frameIndex.
+func (p *Pipeline) VOILUTFor(frameIndex int) *VOILUT {
+	if frameIndex >= 0 && frameIndex < len(p.perFrameVOI) && p.perFrameVOI[frameIndex] != nil {
+		return p.perFrameVOI[frameIndex]
+	}
+	return p.VOI
+}
+
+// ApplyModality applies the Modality LUT to the stored values of the grayscale
+// frame f, which has 0-based index frameIndex, and returns the output values.
+func (p *Pipeline) ApplyModality(f *frame.NativeFrame, frameIndex int) (*RealFrame, error) {
+	if err := p.checkFrame(f); err != nil {
+		return nil, err
+	}
+	m := p.ModalityLUTFor(frameIndex)
+	out := &RealFrame{Rows: f.Rows, Cols: f.Cols, Data: make([]float64, f.NumPixels())}
+	for i := range out.Data {
+		out.Data[i] = m.Apply(p.Format.StoredValue(f.At(i, 0)))
+	}
+	return out, nil
+}
+
+// checkFrame returns an error if f cannot be transformed as a grayscale frame
+// of the Pipeline's Format.
+func (p *Pipeline) checkFrame(f *frame.NativeFrame) error {
+	if p.Format.SamplesPerPixel != 1 {
+		return fmt.Errorf("%w: the grayscale pipeline does not apply to %d samples per pixel",
+			ErrorUnsupportedFormat, p.Format.SamplesPerPixel)
+	}
+	switch p.Format.PhotometricInterpretation {
+	case "", "MONOCHROME1", "MONOCHROME2":
+	default:
+		return fmt.Errorf("%w: the grayscale pipeline does not apply to %s", ErrorUnsupportedFormat, p.Format.PhotometricInterpretation)
+	}
+	if f.NumPixels() != f.Rows*f.Cols {
+		return fmt.Errorf("%w: %d pixels for %dx%d frame", ErrorFrameMismatch, f.NumPixels(), f.Rows, f.Cols)
+	}
+	if f.NumPixels() > 0 && f.NumSamplesPerPixel() < 1 {
+		return fmt.Errorf("%w: pixels with no samples", ErrorFrameMismatch)
+	}
+	return nil
+}
+
+// ModalityFrames applies the Modality LUT to all of the native PixelData
+// frames in ds, returning real-valued output for each frame.
+func ModalityFrames(ds *dicom.Dataset) ([]*RealFrame, error) {
+	var out []*RealFrame
+	err := forEachFrame(ds, func(p *Pipeline, f *frame.NativeFrame, i int) error {
+		r, err := p.ApplyModality(f, i)
+		out = append(out, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// forEachFrame calls fn with a Pipeline for ds and each of the native
+// PixelData frames in ds, in order, stopping at the first error.
+func forEachFrame(ds *dicom.Dataset, fn func(p *Pipeline, f *frame.NativeFrame, i int) error) error {
+	p, err := NewPipeline(ds)
+	if err != nil {
+		return err
+	}
+	info, err := pixelDataInfo(ds)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < info.NumFrames(); i++ {
+		f, err := nativeFrame(info, i)
+		if err != nil {
+			return err
+		}
+		if err := fn(p, f, i); err != nil {
+			return fmt.Errorf("frame %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// pixelDataInfo returns the PixelDataInfo of the PixelData in ds.
+func pixelDataInfo(ds *dicom.Dataset) (dicom.PixelDataInfo, error) {
+	pd, err := ds.FindElementByTag(tag.PixelData)
+	if err != nil {
+		return dicom.PixelDataInfo{}, err
+	}
+	if pd.Value.ValueType() != dicom.PixelData {
+		return dicom.PixelDataInfo{}, fmt.Errorf("unexpected PixelData ValueType %v", pd.Value.ValueType())
+	}
+	return dicom.MustGetPixelDataInfo(pd.Value), nil
+}
+
+// nativeFrame returns the native frame with index i in info.
+func nativeFrame(info dicom.PixelDataInfo, i int) (*frame.NativeFrame, error) {
+	f, err := info.Frame(i)
+	if err != nil {
+		return nil, err
+	}
+	return f.GetNativeFrame()
+}
+
+// numItems returns the number of Items in the Sequence with tag t in ds.
+func numItems(ds *dicom.Dataset, t tag.Tag) int {
+	e, err := ds.FindElementByTag(t)
+	if err != nil || e.Value.ValueType() != dicom.Sequences {
+		return 0
+	}
+	return len(e.Value.GetValue().([]*dicom.SequenceItemValue))
+}
+
+// item returns the elements of Item i of the Sequence with tag t in ds.
+func item(ds *dicom.Dataset, t tag.Tag, i int) (*dicom.Dataset, error) {
+	e, err := ds.FindElementByTag(t)
+	if err != nil {
+		return nil, err
+	}
+	if e.Value.ValueType() != dicom.Sequences {
+		return nil, fmt.Errorf("unexpected ValueType for %v: %v", t, e.Value.ValueType())
+	}
+	items := e.Value.GetValue().([]*dicom.SequenceItemValue)
+	if i < 0 || i >= len(items) {
+		return nil, fmt.Errorf("%v has %d items, want item %d", t, len(items), i)
+	}
+	return &dicom.Dataset{Elements: items[i].GetValue().([]*dicom.Element)}, nil
+}
+
+// functionalGroup returns the first Item of the functional group macro with tag
+// macro, in Item i of the functional groups Sequence with tag groups.
+func functionalGroup(ds *dicom.Dataset, groups tag.Tag, i int, macro tag.Tag) (*dicom.Dataset, bool) {
+	fg, err := item(ds, groups, i)
+	if err != nil {
+		return nil, false
+	}
+	m, err := item(fg, macro, 0)
+	if err != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+// floatValues returns the values of the DS element with tag t in ds, or nil if
+// ds has no such element.
+func floatValues(ds *dicom.Dataset, t tag.Tag) ([]float64, error) {
+	e, err := ds.FindElementByTag(t)
+	if err != nil {
+		return nil, nil
+	}
+	if e.Value.ValueType() != dicom.Strings {
+		return nil, fmt.Errorf("invalid value for %v: %v", t, e.Value)
+	}
+	var values []float64
+	for _, s := range dicom.MustGetStrings(e.Value) {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %v: %w", t, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
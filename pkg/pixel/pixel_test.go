@@ -0,0 +1,399 @@
+package pixel_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/frame"
+	"github.com/suyashkumar/dicom/pkg/pixel"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+func TestFormat_StoredValue(t *testing.T) {
+	cases := []struct {
+		name   string
+		format pixel.Format
+		raw    int
+		want   int
+	}{
+		{
+			name:   "unsigned, ignoring unused high bits",
+			format: pixel.Format{BitsAllocated: 16, BitsStored: 12, HighBit: 11},
+			raw:    0x8FFF,
+			want:   0x0FFF,
+		},
+		{
+			name:   "signed 16 bits",
+			format: pixel.Format{BitsAllocated: 16, BitsStored: 16, HighBit: 15, Signed: true},
+			raw:    0xFC00,
+			want:   -1024,
+		},
+		{
+			name:   "signed 12 bits",
+			format: pixel.Format{BitsAllocated: 16, BitsStored: 12, HighBit: 11, Signed: true},
+			raw:    0x0FFF,
+			want:   -1,
+		},
+		{
+			name:   "high bit not at BitsStored-1",
+			format: pixel.Format{BitsAllocated: 16, BitsStored: 12, HighBit: 15},
+			raw:    0x1235,
+			want:   0x0123,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.format.StoredValue(tc.raw); got != tc.want {
+				t.Errorf("StoredValue(%#x) = %d, want %d", tc.raw, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPipeline_ApplyModality(t *testing.T) {
+	format := []*dicom.Element{
+		mustNewElement(t, tag.SamplesPerPixel, []int{1}),
+		mustNewElement(t, tag.PhotometricInterpretation, []string{"MONOCHROME2"}),
+		mustNewElement(t, tag.BitsAllocated, []int{16}),
+		mustNewElement(t, tag.BitsStored, []int{12}),
+		mustNewElement(t, tag.HighBit, []int{11}),
+		mustNewElement(t, tag.PixelRepresentation, []int{1}),
+	}
+	// Stored values 0, 1, -1 and 2047.
+	f := nativeFrame(2, 2, 0x0000, 0x0001, 0x0FFF, 0x07FF)
+
+	cases := []struct {
+		name     string
+		elements []*dicom.Element
+		frames   [][]float64
+	}{
+		{
+			name:     "identity",
+			elements: format,
+			frames:   [][]float64{{0, 1, -1, 2047}},
+		},
+		{
+			name: "rescale",
+			elements: append(format,
+				mustNewElement(t, tag.RescaleSlope, []string{"2"}),
+				mustNewElement(t, tag.RescaleIntercept, []string{"-1024"}),
+				mustNewElement(t, tag.RescaleType, []string{"HU"}),
+			),
+			frames: [][]float64{{-1024, -1022, -1026, 3070}},
+		},
+		{
+			name: "ModalityLUTSequence",
+			elements: append(format,
+				mustNewElement(t, tag.RescaleSlope, []string{"2"}),
+				mustNewElement(t, tag.ModalityLUTSequence, [][]*dicom.Element{{
+					// 3 entries, starting at -1.
+					mustNewElement(t, tag.LUTDescriptor, []int{3, 0xFFFF, 16}),
+					mustNewElement(t, tag.ModalityLUTType, []string{"OD"}),
+					mustNewElement(t, tag.LUTData, []byte{10, 0, 20, 0, 30, 0}),
+				}}),
+			),
+			// -1 maps to the first entry, and 2047 past the last entry maps
+			// to the last entry.
+			frames: [][]float64{{20, 30, 10, 30}},
+		},
+		{
+			name: "functional groups",
+			elements: append(format,
+				mustNewElement(t, tag.SharedFunctionalGroupsSequence, [][]*dicom.Element{{
+					mustNewElement(t, tag.PixelValueTransformationSequence, [][]*dicom.Element{{
+						mustNewElement(t, tag.RescaleSlope, []string{"1"}),
+						mustNewElement(t, tag.RescaleIntercept, []string{"100"}),
+					}}),
+				}}),
+				mustNewElement(t, tag.PerFrameFunctionalGroupsSequence, [][]*dicom.Element{
+					{},
+					{
+						mustNewElement(t, tag.PixelValueTransformationSequence, [][]*dicom.Element{{
+							mustNewElement(t, tag.RescaleSlope, []string{"0.5"}),
+							mustNewElement(t, tag.RescaleIntercept, []string{"0"}),
+						}}),
+					},
+				}),
+			),
+			frames: [][]float64{{100, 101, 99, 2147}, {0, 0.5, -0.5, 1023.5}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := pixel.NewPipeline(&dicom.Dataset{Elements: tc.elements})
+			if err != nil {
+				t.Fatalf("NewPipeline unexpected error: %v", err)
+			}
+			for i, want := range tc.frames {
+				got, err := p.ApplyModality(&f, i)
+				if err != nil {
+					t.Fatalf("ApplyModality(frame %d) unexpected error: %v", i, err)
+				}
+				if diff := cmp.Diff(&pixel.RealFrame{Rows: 2, Cols: 2, Data: want}, got); diff != "" {
+					t.Errorf("ApplyModality(frame %d) unexpected output. diff: %v", i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPipeline_errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		elements []*dicom.Element
+		wantErr  error
+	}{
+		{
+			name: "BitsStored larger than BitsAllocated",
+			elements: []*dicom.Element{
+				mustNewElement(t, tag.BitsAllocated, []int{8}),
+				mustNewElement(t, tag.BitsStored, []int{12}),
+			},
+			wantErr: pixel.ErrorUnsupportedFormat,
+		},
+		{
+			name: "LUTData shorter than LUTDescriptor",
+			elements: []*dicom.Element{
+				mustNewElement(t, tag.BitsAllocated, []int{8}),
+				mustNewElement(t, tag.ModalityLUTSequence, [][]*dicom.Element{{
+					mustNewElement(t, tag.LUTDescriptor, []int{4, 0, 16}),
+					mustNewElement(t, tag.LUTData, []byte{10, 0, 20, 0}),
+				}}),
+			},
+			wantErr: pixel.ErrorInvalidLUT,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := pixel.NewPipeline(&dicom.Dataset{Elements: tc.elements})
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("NewPipeline unexpected error. got: %v, want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestModalityFrames(t *testing.T) {
+	// 5.dcm is an enhanced CT, with the rescale in the shared functional
+	// groups.
+	ds, err := dicom.ParseFile("../../testdata/5.dcm")
+	if err != nil {
+		t.Fatalf("unable to parse test file: %v", err)
+	}
+	frames, err := pixel.ModalityFrames(&ds)
+	if err != nil {
+		t.Fatalf("ModalityFrames unexpected error: %v", err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("ModalityFrames returned %d frames, want 2", len(frames))
+	}
+	pd, err := ds.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	raw := dicom.MustGetPixelDataInfo(pd.Value).Frames[0].NativeData
+	for i, v := range frames[0].Data {
+		if want := float64(raw.At(i, 0)) - 1024; v != want {
+			t.Fatalf("ModalityFrames unexpected value at pixel %d. got: %v, want: %v", i, v, want)
+		}
+	}
+}
+
+func TestWindow_Apply(t *testing.T) {
+	cases := []struct {
+		window pixel.Window
+		x      float64
+		want   float64
+	}{
+		{window: pixel.Window{Center: 100, Width: 201}, x: -0.5, want: 0},
+		{window: pixel.Window{Center: 100, Width: 201}, x: 99.5, want: 100},
+		{window: pixel.Window{Center: 100, Width: 201}, x: 149.5, want: 150},
+		{window: pixel.Window{Center: 100, Width: 201}, x: 199.6, want: 200},
+		{window: pixel.Window{Center: 100, Width: 200, Function: pixel.FunctionLinearExact}, x: 0, want: 0},
+		{window: pixel.Window{Center: 100, Width: 200, Function: pixel.FunctionLinearExact}, x: 50, want: 50},
+		{window: pixel.Window{Center: 100, Width: 200, Function: pixel.FunctionLinearExact}, x: 200, want: 200},
+		{window: pixel.Window{Center: 100, Width: 200, Function: pixel.FunctionLinearExact}, x: 300, want: 200},
+		{window: pixel.Window{Center: 100, Width: 200, Function: pixel.FunctionSigmoid}, x: 100, want: 100},
+		{window: pixel.Window{Center: 100, Width: 200, Function: pixel.FunctionSigmoid}, x: 150, want: 200 / (1 + math.Exp(-1))},
+	}
+	for _, tc := range cases {
+		if err := tc.window.Validate(); err != nil {
+			t.Fatalf("%+v.Validate() unexpected error: %v", tc.window, err)
+		}
+		if got := tc.window.Apply(tc.x, 0, 200); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%+v.Apply(%v, 0, 200) = %v, want %v", tc.window, tc.x, got, tc.want)
+		}
+	}
+
+	for _, w := range []pixel.Window{
+		{Center: 0, Width: 0.5},
+		{Center: 0, Width: 0, Function: pixel.FunctionLinearExact},
+		{Center: 0, Width: 1, Function: "CUBIC"},
+	} {
+		if err := w.Validate(); !errors.Is(err, pixel.ErrorInvalidWindow) {
+			t.Errorf("%+v.Validate() unexpected error. got: %v, want: %v", w, err, pixel.ErrorInvalidWindow)
+		}
+	}
+}
+
+func TestPipeline_Render(t *testing.T) {
+	format := func(photometric string) []*dicom.Element {
+		return []*dicom.Element{
+			mustNewElement(t, tag.PhotometricInterpretation, []string{photometric}),
+			mustNewElement(t, tag.BitsAllocated, []int{16}),
+			mustNewElement(t, tag.BitsStored, []int{12}),
+			mustNewElement(t, tag.RescaleIntercept, []string{"-1000"}),
+		}
+	}
+	// Output values -1000, 0, 1000 and 3095 after the Modality LUT.
+	f := nativeFrame(2, 2, 0, 1000, 2000, 4095)
+
+	cases := []struct {
+		name     string
+		elements []*dicom.Element
+		opts     []pixel.RenderOption
+		want     []uint8
+	}{
+		{
+			name: "dataset window",
+			elements: append(format("MONOCHROME2"),
+				mustNewElement(t, tag.WindowCenter, []string{"500", "0"}),
+				mustNewElement(t, tag.WindowWidth, []string{"1000", "2000"}),
+				mustNewElement(t, tag.VOILUTFunction, []string{"LINEAR_EXACT"}),
+			),
+			want: []uint8{0, 0, 255, 255},
+		},
+		{
+			name: "second dataset window",
+			elements: append(format("MONOCHROME2"),
+				mustNewElement(t, tag.WindowCenter, []string{"500", "0"}),
+				mustNewElement(t, tag.WindowWidth, []string{"1000", "2000"}),
+				mustNewElement(t, tag.VOILUTFunction, []string{"LINEAR_EXACT"}),
+			),
+			opts: []pixel.RenderOption{pixel.WithVOIIndex(1)},
+			want: []uint8{0, 128, 255, 255},
+		},
+		{
+			name:     "MONOCHROME1",
+			elements: format("MONOCHROME1"),
+			opts:     []pixel.RenderOption{pixel.WithWindow(pixel.Window{Center: 0, Width: 2000, Function: pixel.FunctionLinearExact})},
+			want:     []uint8{255, 127, 0, 0},
+		},
+		{
+			name:     "auto window",
+			elements: format("MONOCHROME2"),
+			want:     []uint8{0, 62, 125, 255},
+		},
+		{
+			name: "auto window overrides dataset window",
+			elements: append(format("MONOCHROME2"),
+				mustNewElement(t, tag.WindowCenter, []string{"500"}),
+				mustNewElement(t, tag.WindowWidth, []string{"1000"}),
+			),
+			opts: []pixel.RenderOption{pixel.WithAutoWindow()},
+			want: []uint8{0, 62, 125, 255},
+		},
+		{
+			name: "VOILUTSequence",
+			elements: append(format("MONOCHROME2"),
+				mustNewElement(t, tag.VOILUTSequence, [][]*dicom.Element{{
+					// 3 8-bit entries, starting at -1 (0xFFFF).
+					mustNewElement(t, tag.LUTDescriptor, []int{3, 0xFFFF, 8}),
+					mustNewElement(t, tag.LUTData, []byte{0, 0, 51, 0, 255, 0}),
+				}}),
+			),
+			want: []uint8{0, 51, 255, 255},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := pixel.NewPipeline(&dicom.Dataset{Elements: tc.elements})
+			if err != nil {
+				t.Fatalf("NewPipeline unexpected error: %v", err)
+			}
+			img, err := p.Render(&f, 0, tc.opts...)
+			if err != nil {
+				t.Fatalf("Render unexpected error: %v", err)
+			}
+			if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 2 {
+				t.Errorf("Render unexpected bounds: %v", img.Bounds())
+			}
+			if diff := cmp.Diff(tc.want, img.Pix); diff != "" {
+				t.Errorf("Render unexpected pixels. diff: %v", diff)
+			}
+		})
+	}
+}
+
+func TestPipeline_Render_errors(t *testing.T) {
+	p, err := pixel.NewPipeline(&dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.BitsAllocated, []int{8}),
+		mustNewElement(t, tag.WindowCenter, []string{"128"}),
+		mustNewElement(t, tag.WindowWidth, []string{"0"}),
+	}})
+	if err != nil {
+		t.Fatalf("NewPipeline unexpected error: %v", err)
+	}
+	f := nativeFrame(1, 1, 0)
+	if _, err := p.Render(&f, 0); !errors.Is(err, pixel.ErrorInvalidWindow) {
+		t.Errorf("Render with a zero width window unexpected error. got: %v, want: %v", err, pixel.ErrorInvalidWindow)
+	}
+	if _, err := p.Render(&f, 0, pixel.WithVOIIndex(1)); !errors.Is(err, pixel.ErrorInvalidWindow) {
+		t.Errorf("Render with an out of range VOI index unexpected error. got: %v, want: %v", err, pixel.ErrorInvalidWindow)
+	}
+
+	p, err = pixel.NewPipeline(&dicom.Dataset{Elements: []*dicom.Element{
+		mustNewElement(t, tag.PhotometricInterpretation, []string{"PALETTE COLOR"}),
+		mustNewElement(t, tag.BitsAllocated, []int{8}),
+	}})
+	if err != nil {
+		t.Fatalf("NewPipeline unexpected error: %v", err)
+	}
+	if _, err := p.Render(&f, 0); !errors.Is(err, pixel.ErrorUnsupportedFormat) {
+		t.Errorf("Render of a PALETTE COLOR frame unexpected error. got: %v, want: %v", err, pixel.ErrorUnsupportedFormat)
+	}
+}
+
+func TestRenderFrames(t *testing.T) {
+	ds, err := dicom.ParseFile("../../testdata/5.dcm")
+	if err != nil {
+		t.Fatalf("unable to parse test file: %v", err)
+	}
+	imgs, err := pixel.RenderFrames(&ds)
+	if err != nil {
+		t.Fatalf("RenderFrames unexpected error: %v", err)
+	}
+	if len(imgs) != 2 {
+		t.Fatalf("RenderFrames returned %d images, want 2", len(imgs))
+	}
+	// The dataset's window spans only part of the values in the frames, so
+	// both black and white pixels are expected.
+	var black, white bool
+	for _, v := range imgs[0].Pix {
+		black = black || v == 0
+		white = white || v == 255
+	}
+	if !black || !white {
+		t.Errorf("RenderFrames did not use the full display range. black: %v, white: %v", black, white)
+	}
+}
+
+func nativeFrame(rows, cols int, pixels ...int) frame.NativeFrame {
+	nf := frame.NativeFrame{Rows: rows, Cols: cols, BitsPerSample: 16}
+	for _, p := range pixels {
+		nf.Data = append(nf.Data, []int{p})
+	}
+	return nf
+}
+
+func mustNewElement(t *testing.T, tg tag.Tag, data interface{}) *dicom.Element {
+	t.Helper()
+	elem, err := dicom.NewElement(tg, data)
+	if err != nil {
+		t.Fatalf("dicom.NewElement(%v, %v) unexpected error: %v", tg, data, err)
+	}
+	return elem
+}
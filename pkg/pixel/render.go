@@ -0,0 +1,133 @@
+package pixel
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/pkg/frame"
+)
+
+// RenderOption represents an option that can be passed to Render. Later
+// options override earlier ones.
+type RenderOption func(*renderOptSet)
+
+// WithWindow returns a RenderOption that applies the Window w, instead of the
+// VOI LUT described in the Dataset.
+func WithWindow(w Window) RenderOption {
+	return func(set *renderOptSet) {
+		set.window = &w
+		set.autoWindow = false
+	}
+}
+
+// WithAutoWindow returns a RenderOption that applies a linear Window spanning
+// the minimum to the maximum value in each frame, instead of the VOI LUT
+// described in the Dataset.
+func WithAutoWindow() RenderOption {
+	return func(set *renderOptSet) {
+		set.window = nil
+		set.autoWindow = true
+	}
+}
+
+// WithVOIIndex returns a RenderOption that selects which of the Windows (or if
+// there are none, which of the LUTs) described in the Dataset is applied. It
+// defaults to 0.
+func WithVOIIndex(i int) RenderOption {
+	return func(set *renderOptSet) {
+		set.voiIndex = i
+	}
+}
+
+// renderOptSet represents the flattened option set after all RenderOptions
+// have been applied.
+type renderOptSet struct {
+	window     *Window
+	autoWindow bool
+	voiIndex   int
+}
+
+// Render applies the Modality LUT, the VOI LUT and the presentation inversion
+// (for MONOCHROME1) to the grayscale frame f, which has 0-based index
+// frameIndex, and returns an 8-bit image for display.
+//
+// By default, the first Window described in the Dataset is applied, or if
+// there are none, the first VOI LUT. If the Dataset describes neither, a
+// Window spanning the minimum to the maximum value in the frame is applied.
+func (p *Pipeline) Render(f *frame.NativeFrame, frameIndex int, opts ...RenderOption) (*image.Gray, error) {
+	var set renderOptSet
+	for _, opt := range opts {
+		opt(&set)
+	}
+	r, err := p.ApplyModality(f, frameIndex)
+	if err != nil {
+		return nil, err
+	}
+	voi, err := p.selectVOI(r, frameIndex, set)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewGray(image.Rect(0, 0, r.Cols, r.Rows))
+	for i, x := range r.Data {
+		y := uint8(math.Round(voi(x)))
+		if p.Inverse {
+			y = math.MaxUint8 - y
+		}
+		img.Pix[i] = y
+	}
+	return img, nil
+}
+
+// selectVOI returns the VOI transformation to apply to r, which maps its
+// values to the range [0, 255].
+func (p *Pipeline) selectVOI(r *RealFrame, frameIndex int, set renderOptSet) (func(float64) float64, error) {
+	w := set.window
+	if w == nil && !set.autoWindow {
+		voi := p.VOILUTFor(frameIndex)
+		switch {
+		case len(voi.Windows) > 0:
+			if set.voiIndex < 0 || set.voiIndex >= len(voi.Windows) {
+				return nil, fmt.Errorf("%w: index %d, but %d windows", ErrorInvalidWindow, set.voiIndex, len(voi.Windows))
+			}
+			w = &voi.Windows[set.voiIndex]
+		case len(voi.LUTs) > 0:
+			if set.voiIndex < 0 || set.voiIndex >= len(voi.LUTs) {
+				return nil, fmt.Errorf("%w: index %d, but %d LUTs", ErrorInvalidLUT, set.voiIndex, len(voi.LUTs))
+			}
+			lut := voi.LUTs[set.voiIndex]
+			scale := math.MaxUint8 / float64(int(1)<<uint(lut.BitsPerEntry)-1)
+			return func(x float64) float64 {
+				return float64(lut.Lookup(int(math.Round(x)))) * scale
+			}, nil
+		}
+	}
+	if w == nil {
+		auto := autoWindow(r)
+		w = &auto
+	}
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	window := *w
+	return func(x float64) float64 {
+		return window.Apply(x, 0, math.MaxUint8)
+	}, nil
+}
+
+// RenderFrames renders all of the native PixelData frames in ds as 8-bit
+// images for display. See Pipeline.Render.
+func RenderFrames(ds *dicom.Dataset, opts ...RenderOption) ([]*image.Gray, error) {
+	var out []*image.Gray
+	err := forEachFrame(ds, func(p *Pipeline, f *frame.NativeFrame, i int) error {
+		img, err := p.Render(f, i, opts...)
+		out = append(out, img)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,141 @@
+package pixel
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/suyashkumar/dicom"
+	"github.com/suyashkumar/dicom/internal/dsvalue"
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+// VOI LUT functions, which define how a Window maps input values to output
+// values. See PS3.3 C.11.2.1.3.
+const (
+	FunctionLinear      = "LINEAR"
+	FunctionLinearExact = "LINEAR_EXACT"
+	FunctionSigmoid     = "SIGMOID"
+)
+
+// Window is a window center and width, which selects the range of input
+// values of interest (VOI) to display. See PS3.3 C.11.2.1.2.
+type Window struct {
+	Center float64
+	Width  float64
+	// Function is the VOI LUT function used with the window, one of
+	// FunctionLinear, FunctionLinearExact or FunctionSigmoid. It defaults to
+	// FunctionLinear if empty.
+	Function    string
+	Explanation string
+}
+
+// Validate returns an error if the Window's width is not valid for its
+// Function, or if the Function is unknown.
+func (w Window) Validate() error {
+	switch w.Function {
+	case "", FunctionLinear:
+		if w.Width < 1 {
+			return fmt.Errorf("%w: width %v is less than 1", ErrorInvalidWindow, w.Width)
+		}
+	case FunctionLinearExact, FunctionSigmoid:
+		if w.Width <= 0 {
+			return fmt.Errorf("%w: width %v is not positive", ErrorInvalidWindow, w.Width)
+		}
+	default:
+		return fmt.Errorf("%w: unknown VOILUTFunction %q", ErrorInvalidWindow, w.Function)
+	}
+	return nil
+}
+
+// Apply maps the input value x to an output value in the range [yMin, yMax],
+// using the Window's Function. The Window must be valid.
+func (w Window) Apply(x, yMin, yMax float64) float64 {
+	c, width := w.Center, w.Width
+	switch w.Function {
+	case FunctionLinearExact:
+		switch {
+		case x <= c-width/2:
+			return yMin
+		case x > c+width/2:
+			return yMax
+		}
+		return ((x-c)/width+0.5)*(yMax-yMin) + yMin
+	case FunctionSigmoid:
+		return (yMax-yMin)/(1+math.Exp(-4*(x-c)/width)) + yMin
+	}
+	switch {
+	case x <= c-0.5-(width-1)/2:
+		return yMin
+	case x > c-0.5+(width-1)/2:
+		return yMax
+	}
+	return ((x-(c-0.5))/(width-1)+0.5)*(yMax-yMin) + yMin
+}
+
+// autoWindow returns a FunctionLinearExact Window spanning the minimum to the
+// maximum value in r.
+func autoWindow(r *RealFrame) Window {
+	if len(r.Data) == 0 {
+		return Window{Center: 0, Width: 1, Function: FunctionLinearExact}
+	}
+	lo, hi := r.Data[0], r.Data[0]
+	for _, v := range r.Data[1:] {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	w := Window{Center: (lo + hi) / 2, Width: hi - lo, Function: FunctionLinearExact, Explanation: "AUTO"}
+	if w.Width == 0 {
+		w.Width = 1
+	}
+	return w
+}
+
+// VOILUT is the VOI LUT transformation, which selects the range of Modality
+// LUT output values to display. The Dataset may offer several alternative
+// Windows and explicit lookup tables (LUTs), of which one is applied. See PS3.3
+// C.11.2.
+type VOILUT struct {
+	Windows []Window
+	LUTs    []*LUT
+}
+
+// parseVOILUT returns the VOI LUT described in ds, which is either a Dataset
+// or an Item of the FrameVOILUTSequence. signed indicates if the Modality LUT
+// output may be negative, in which case the first values mapped by the LUTs are
+// signed.
+func parseVOILUT(ds *dicom.Dataset, signed bool) (*VOILUT, error) {
+	v := &VOILUT{}
+	centers, err := floatValues(ds, tag.WindowCenter)
+	if err != nil {
+		return nil, err
+	}
+	widths, err := floatValues(ds, tag.WindowWidth)
+	if err != nil {
+		return nil, err
+	}
+	var explanations []string
+	if e, err := ds.FindElementByTag(tag.WindowCenterWidthExplanation); err == nil && e.Value.ValueType() == dicom.Strings {
+		explanations = dicom.MustGetStrings(e.Value)
+	}
+	function := dsvalue.String(ds, tag.VOILUTFunction)
+	for i := 0; i < len(centers) && i < len(widths); i++ {
+		w := Window{Center: centers[i], Width: widths[i], Function: function}
+		if i < len(explanations) {
+			w.Explanation = explanations[i]
+		}
+		v.Windows = append(v.Windows, w)
+	}
+
+	for i := 0; i < numItems(ds, tag.VOILUTSequence); i++ {
+		lutItem, err := item(ds, tag.VOILUTSequence, i)
+		if err != nil {
+			return nil, err
+		}
+		lut, err := parseLUT(lutItem, signed)
+		if err != nil {
+			return nil, fmt.Errorf("VOILUTSequence item %d: %w", i, err)
+		}
+		v.LUTs = append(v.LUTs, lut)
+	}
+	return v, nil
+}
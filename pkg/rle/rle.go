@@ -0,0 +1,198 @@
+// Package rle implements the RLE Lossless compression of the RLE Lossless
+// transfer syntax (1.2.840.10008.1.2.5), see PS3.5 Annex G.
+//
+// Each frame is split into byte planes ("segments"): one for each byte of each
+// sample, from the most significant byte of the first sample to the least
+// significant byte of the last. Each segment is compressed with a PackBits
+// style byte run length encoding, row by row.
+package rle
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrorInvalidData indicates that the data is not a valid RLE Lossless
+	// frame with the given layout.
+	ErrorInvalidData = errors.New("invalid RLE data")
+	// ErrorUnsupportedLayout indicates that frames with the given layout can
+	// not be RLE Lossless encoded.
+	ErrorUnsupportedLayout = errors.New("unsupported RLE frame layout")
+)
+
+const (
+	// headerSize is the size of the RLE Header, which holds the number of
+	// segments followed by the offset of each segment.
+	headerSize = 64
+	// maxSegments is the maximum number of segments in a frame.
+	maxSegments = 15
+	// maxRun is the maximum length of a literal or replicate run.
+	maxRun = 128
+)
+
+// numSegments returns the number of segments of a frame with samplesPerPixel
+// samples of bitsAllocated bits.
+func numSegments(rows, cols, samplesPerPixel, bitsAllocated int) (int, error) {
+	if rows <= 0 || cols <= 0 || samplesPerPixel <= 0 || bitsAllocated <= 0 || bitsAllocated%8 != 0 {
+		return 0, fmt.Errorf("%w: %dx%d pixels of %d samples of %d bits", ErrorUnsupportedLayout, cols, rows, samplesPerPixel, bitsAllocated)
+	}
+	n := samplesPerPixel * bitsAllocated / 8
+	if n > maxSegments {
+		return 0, fmt.Errorf("%w: %d segments are needed, at most %d are allowed", ErrorUnsupportedLayout, n, maxSegments)
+	}
+	return n, nil
+}
+
+// Decode decodes the RLE Lossless frame in data, of rows x cols pixels with
+// samplesPerPixel samples of bitsAllocated bits. The samples are returned as
+// native little endian PixelData, with the samples of each pixel together.
+func Decode(data []byte, rows, cols, samplesPerPixel, bitsAllocated int) ([]byte, error) {
+	n, err := numSegments(rows, cols, samplesPerPixel, bitsAllocated)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("%w: %d bytes is too short for the RLE Header", ErrorInvalidData, len(data))
+	}
+	if got := int(binary.LittleEndian.Uint32(data)); got != n {
+		return nil, fmt.Errorf("%w: got %d segments, want %d", ErrorInvalidData, got, n)
+	}
+	offsets := make([]int, n+1)
+	for i := 0; i < n; i++ {
+		offsets[i] = int(binary.LittleEndian.Uint32(data[4+4*i:]))
+	}
+	offsets[n] = len(data)
+
+	numPixels := rows * cols
+	bytesPerSample := bitsAllocated / 8
+	out := make([]byte, numPixels*n)
+	segment := make([]byte, numPixels)
+	for i := 0; i < n; i++ {
+		start, end := offsets[i], offsets[i+1]
+		if start < headerSize || start > end || end > len(data) {
+			return nil, fmt.Errorf("%w: invalid offset %d of segment %d", ErrorInvalidData, start, i)
+		}
+		if err := decodeSegment(segment, data[start:end]); err != nil {
+			return nil, fmt.Errorf("segment %d: %w", i, err)
+		}
+		// Segment i holds byte b (from the most significant) of sample s.
+		s, b := i/bytesPerSample, i%bytesPerSample
+		pos := s*bytesPerSample + bytesPerSample - 1 - b
+		for _, v := range segment {
+			out[pos] = v
+			pos += n
+		}
+	}
+	return out, nil
+}
+
+// decodeSegment decodes the segment in data into dst, which it must fill.
+// Data past the end of dst (e.g. padding) is ignored.
+func decodeSegment(dst, data []byte) error {
+	i, j := 0, 0
+	for j < len(dst) {
+		if i >= len(data) {
+			return fmt.Errorf("%w: segment decodes to %d bytes, want %d", ErrorInvalidData, j, len(dst))
+		}
+		h := int(int8(data[i]))
+		i++
+		switch {
+		case h >= 0:
+			// Copy the next h+1 bytes literally.
+			if i+h+1 > len(data) || j+h+1 > len(dst) {
+				return fmt.Errorf("%w: literal run past the end of the segment", ErrorInvalidData)
+			}
+			j += copy(dst[j:], data[i:i+h+1])
+			i += h + 1
+		case h > -128:
+			// Replicate the next byte 1-h times.
+			if i >= len(data) || j+1-h > len(dst) {
+				return fmt.Errorf("%w: replicate run past the end of the segment", ErrorInvalidData)
+			}
+			for k := 0; k < 1-h; k++ {
+				dst[j+k] = data[i]
+			}
+			j += 1 - h
+			i++
+		}
+		// A header of -128 is a no-op.
+	}
+	return nil
+}
+
+// Encode encodes the native little endian PixelData in data, holding rows x
+// cols pixels with samplesPerPixel samples of bitsAllocated bits (with the
+// samples of each pixel together), as an RLE Lossless frame.
+func Encode(data []byte, rows, cols, samplesPerPixel, bitsAllocated int) ([]byte, error) {
+	n, err := numSegments(rows, cols, samplesPerPixel, bitsAllocated)
+	if err != nil {
+		return nil, err
+	}
+	numPixels := rows * cols
+	if len(data) != numPixels*n {
+		return nil, fmt.Errorf("%w: got %d bytes of PixelData, want %d", ErrorInvalidData, len(data), numPixels*n)
+	}
+
+	bytesPerSample := bitsAllocated / 8
+	out := make([]byte, headerSize, headerSize+len(data))
+	binary.LittleEndian.PutUint32(out, uint32(n))
+	segment := make([]byte, numPixels)
+	for i := 0; i < n; i++ {
+		binary.LittleEndian.PutUint32(out[4+4*i:], uint32(len(out)))
+		s, b := i/bytesPerSample, i%bytesPerSample
+		pos := s*bytesPerSample + bytesPerSample - 1 - b
+		for p := range segment {
+			segment[p] = data[pos]
+			pos += n
+		}
+		// Runs must not cross rows.
+		for r := 0; r < rows; r++ {
+			out = encodeRow(out, segment[r*cols:(r+1)*cols])
+		}
+		if len(out)%2 != 0 {
+			// Each segment is padded to an even length.
+			out = append(out, 0)
+		}
+	}
+	return out, nil
+}
+
+// encodeRow appends the run length encoding of row to dst. Runs of three or
+// more equal bytes are replicated, and other bytes are copied literally.
+func encodeRow(dst, row []byte) []byte {
+	literal := 0
+	for i := 0; i < len(row); {
+		run := 1
+		for i+run < len(row) && run < maxRun && row[i+run] == row[i] {
+			run++
+		}
+		if run < 3 {
+			i += run
+			literal += run
+			// Flush full literal runs, so that a literal run never exceeds
+			// maxRun bytes.
+			for literal >= maxRun {
+				dst = appendLiteral(dst, row[i-literal:i-literal+maxRun])
+				literal -= maxRun
+			}
+			continue
+		}
+		if literal > 0 {
+			dst = appendLiteral(dst, row[i-literal:i])
+			literal = 0
+		}
+		dst = append(dst, byte(1-run), row[i])
+		i += run
+	}
+	if literal > 0 {
+		dst = appendLiteral(dst, row[len(row)-literal:])
+	}
+	return dst
+}
+
+// appendLiteral appends a literal run of the bytes b (at most maxRun) to dst.
+func appendLiteral(dst, b []byte) []byte {
+	return append(append(dst, byte(len(b)-1)), b...)
+}
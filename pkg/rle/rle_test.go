@@ -0,0 +1,198 @@
+package rle_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/suyashkumar/dicom/pkg/rle"
+)
+
+// header returns an RLE Header with the given segment offsets.
+func header(offsets ...int) []byte {
+	h := make([]byte, 64)
+	h[0] = byte(len(offsets))
+	for i, o := range offsets {
+		h[4+4*i] = byte(o)
+	}
+	return h
+}
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		name                               string
+		data                               []byte
+		rows, cols, samples, bitsAllocated int
+		want                               []byte
+	}{
+		{
+			name: "8 bits",
+			// A replicate run of four 1s, and a literal run of 2, 3, padded
+			// to an even length.
+			data:          append(header(64), 0xFD, 1, 0x01, 2, 3, 0),
+			rows:          1,
+			cols:          6,
+			samples:       1,
+			bitsAllocated: 8,
+			want:          []byte{1, 1, 1, 1, 2, 3},
+		},
+		{
+			name: "16 bits, most significant byte first",
+			data: append(header(64, 68),
+				0xFF, 0x12, 0x00, 0x34, // 0x12, 0x12, 0x34
+				0x02, 0x01, 0x02, 0x03, // 0x01, 0x02, 0x03
+			),
+			rows:          1,
+			cols:          3,
+			samples:       1,
+			bitsAllocated: 16,
+			want:          []byte{0x01, 0x12, 0x02, 0x12, 0x03, 0x34},
+		},
+		{
+			name: "3 samples, no-op header",
+			data: append(header(64, 68, 72),
+				0x80, 0xFF, 0x0A, 0, // 10, 10
+				0xFF, 0x14, 0x80, 0, // 20, 20
+				0x01, 0x1E, 0x1F, 0, // 30, 31
+			),
+			rows:          1,
+			cols:          2,
+			samples:       3,
+			bitsAllocated: 8,
+			want:          []byte{10, 20, 30, 10, 20, 31},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rle.Decode(tc.data, tc.rows, tc.cols, tc.samples, tc.bitsAllocated)
+			if err != nil {
+				t.Fatalf("Decode unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Decode unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEncode_roundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, layout := range []struct{ rows, cols, samples, bitsAllocated int }{
+		{1, 1, 1, 8},
+		{4, 300, 1, 8},
+		{5, 7, 3, 8},
+		{6, 200, 1, 16},
+		{3, 5, 3, 16},
+		{2, 9, 1, 32},
+		{2, 9, 3, 32},
+	} {
+		t.Run(fmt.Sprintf("%+v", layout), func(t *testing.T) {
+			data := make([]byte, layout.rows*layout.cols*layout.samples*layout.bitsAllocated/8)
+			// Mix long runs, short runs and literal bytes.
+			for i := 0; i < len(data); {
+				run := r.Intn(300)
+				if r.Intn(2) == 0 {
+					run = r.Intn(3)
+				}
+				v := byte(r.Intn(256))
+				for j := 0; j <= run && i < len(data); j++ {
+					data[i] = v
+					if r.Intn(4) == 0 {
+						v = byte(r.Intn(256))
+					}
+					i++
+				}
+			}
+			encoded, err := rle.Encode(data, layout.rows, layout.cols, layout.samples, layout.bitsAllocated)
+			if err != nil {
+				t.Fatalf("Encode unexpected error: %v", err)
+			}
+			if len(encoded)%2 != 0 {
+				t.Errorf("Encode returned %d bytes, want an even length", len(encoded))
+			}
+			got, err := rle.Decode(encoded, layout.rows, layout.cols, layout.samples, layout.bitsAllocated)
+			if err != nil {
+				t.Fatalf("Decode unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(data, got); diff != "" {
+				t.Errorf("Decode(Encode(data)) unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	got, err := rle.Encode([]byte{1, 1, 1, 1, 2, 3}, 2, 3, 1, 8)
+	if err != nil {
+		t.Fatalf("Encode unexpected error: %v", err)
+	}
+	// Each row is encoded separately.
+	want := append(header(64), 0xFE, 1, 0x02, 1, 2, 3)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Encode unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecode_errors(t *testing.T) {
+	cases := []struct {
+		name                               string
+		data                               []byte
+		rows, cols, samples, bitsAllocated int
+		wantErr                            error
+	}{
+		{
+			name:          "short header",
+			data:          header(64)[:60],
+			rows:          1,
+			cols:          1,
+			samples:       1,
+			bitsAllocated: 8,
+			wantErr:       rle.ErrorInvalidData,
+		},
+		{
+			name:          "wrong number of segments",
+			data:          append(header(64), 0x00, 1),
+			rows:          1,
+			cols:          1,
+			samples:       1,
+			bitsAllocated: 16,
+			wantErr:       rle.ErrorInvalidData,
+		},
+		{
+			name:          "truncated segment",
+			data:          append(header(64), 0xFE, 1),
+			rows:          1,
+			cols:          4,
+			samples:       1,
+			bitsAllocated: 8,
+			wantErr:       rle.ErrorInvalidData,
+		},
+		{
+			name:          "invalid offset",
+			data:          append(header(32), 0x00, 1),
+			rows:          1,
+			cols:          1,
+			samples:       1,
+			bitsAllocated: 8,
+			wantErr:       rle.ErrorInvalidData,
+		},
+		{
+			name:          "too many segments",
+			data:          header(),
+			rows:          1,
+			cols:          1,
+			samples:       4,
+			bitsAllocated: 32,
+			wantErr:       rle.ErrorUnsupportedLayout,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := rle.Decode(tc.data, tc.rows, tc.cols, tc.samples, tc.bitsAllocated); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Decode unexpected error. got: %v, want: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
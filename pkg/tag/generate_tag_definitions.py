@@ -11,7 +11,18 @@ Tag = NamedTuple('Tag', [
     ('elem', int),
     ('vr', str),
     ('name', str),
-    ('vm', str)])
+    ('vm', str),
+    # last_group is the last group of a repeating group range
+    # (gggg-gggg,eeee), in which case group is the first group of the range.
+    # It is None for a single group.
+    ('last_group', str)])
+
+# Names of the GroupRange variables generated for the repeating group ranges.
+REPEATING_GROUP_NAMES = {
+    ('5000', '50FF'): 'CurveGroups',
+    ('6000', '60FF'): 'OverlayGroups',
+    ('7F00', '7FFF'): 'VariablePixelDataGroups',
+}
 
 def list_tags() -> List[Tag]:
     global DATA
@@ -37,12 +48,25 @@ def list_tags() -> List[Tag]:
 	    # TODO(saito) I'm less sure about the OX rule. Where is
 	    # this crap defined in the standard??
             vr = "OW"
+        elif m.group(3) == "lt":
+            # Lowercase "lt" is DCMTK's notation for LUT data, which is US or
+            # OW (and not LT). See PS3.6 6.
+            vr = "OW"
 
-        tag = Tag(group=m.group(1),
+        group, last_group = m.group(1), None
+        # Repeating groups (gggg-gggg,eeee) only include the even groups in
+        # the range. Ranges that include odd groups (gggg-u-gggg and
+        # gggg-o-gggg) are generic or private, and are skipped.
+        r = re.match('^([0-9A-Fa-f]{4})-([0-9A-Fa-f]{4})$', group)
+        if r:
+            group, last_group = r.group(1).upper(), r.group(2).upper()
+
+        tag = Tag(group=group,
                   elem=m.group(2),
                   vr=vr,
                   name=m.group(4),
-                  vm=m.group(5))
+                  vm=m.group(5),
+                  last_group=last_group)
 
 
         if not re.match('^[0-9A-Fa-f]+$', tag.group) or not re.match('^[0-9A-Fa-f]+$', tag.elem):
@@ -63,6 +87,16 @@ def generate(out: IO[str]):
         if t.name.find("RETIRED") >= 0:
             continue
         print(f'var {t.name} = Tag{{0x{t.group}, 0x{t.elem}}}', file=out)
+    ranges = sorted(set((t.group, t.last_group) for t in tags if t.last_group))
+    for first, last in ranges:
+        print(f'var {REPEATING_GROUP_NAMES[(first, last)]} = GroupRange{{0x{first}, 0x{last}}}', file=out)
+    print("", file=out)
+    print("// repeatingGroups are the ranges of repeating groups in repeatingTagDict.", file=out)
+    print("var repeatingGroups = []GroupRange{" + ", ".join(REPEATING_GROUP_NAMES[r] for r in ranges) + "}", file=out)
+    print("", file=out)
+    print("// repeatingTagDict holds the tags in repeating groups, keyed by the tag in", file=out)
+    print("// the first group of the range.", file=out)
+    print("var repeatingTagDict map[Tag]TagInfo", file=out)
 
     print("var tagDict map[Tag]TagInfo", file=out)
     print("", file=out)
@@ -75,7 +109,21 @@ def generate(out: IO[str]):
     print("	}", file=out)
     print("	tagDict = make(map[Tag]TagInfo)", file=out)
     for t in tags:
+        if t.last_group:
+            continue
         print(f'	tagDict[Tag{{0x{t.group}, 0x{t.elem}}}] = TagInfo{{Tag{{0x{t.group}, 0x{t.elem}}}, "{t.vr}", "{t.name}", "{t.vm}"}}', file=out)
+    print("	repeatingTagDict = make(map[Tag]TagInfo)", file=out)
+    repeating = [t for t in tags if t.last_group]
+    # Later entries override earlier ones for the same tag (e.g. the DICOM
+    # standard entries override the ACR-NEMA ones), so only the last entry for
+    # each tag is generated. Tags that the base dictionary defines are left out.
+    base = set((t.group.upper(), t.elem.upper()) for t in tags if not t.last_group)
+    last = {(t.group, t.elem.upper()): i for i, t in enumerate(repeating)}
+    for i, t in enumerate(repeating):
+        key = (t.group, t.elem.upper())
+        if last[key] != i or key in base:
+            continue
+        print(f'	repeatingTagDict[Tag{{0x{t.group}, 0x{t.elem}}}] = TagInfo{{Tag{{0x{t.group}, 0x{t.elem}}}, "{t.vr}", "{t.name}", "{t.vm}"}}', file=out)
     print("}", file=out)
 
 
@@ -3347,6 +3395,8 @@ DATA = """#
 (6000-60FF,1303)	DS	ROIStandardDeviation	1	DICOM_2011
 (6000-60FF,1500)	LO	OverlayLabel	1	DICOM_2011
 (6000-60FF,3000)	ox	OverlayData	1	DICOM_2011
+(7FE0,0001)	OV	ExtendedOffsetTable	1	DICOM
+(7FE0,0002)	OV	ExtendedOffsetTableLengths	1	DICOM
 (7FE0,0010)	ox	PixelData	1	DICOM_2011
 (FFFA,FFFA)	SQ	DigitalSignaturesSequence	1	DICOM_2011
 (FFFC,FFFC)	OB	DataSetTrailingPadding	1	DICOM_2011
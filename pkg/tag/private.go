@@ -0,0 +1,102 @@
+package tag
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// PrivateInfo stores detailed information about a private tag. Private tags
+// are identified by the private creator that reserved the block of elements
+// they are in, their group, and their offset within the block (see PS3.5
+// 7.8.1). For example, if the creator "SIEMENS MR HEADER" reserves block 0x10
+// of group 0x0019 using the element (0019,0010), its element with offset 0x0C
+// is (0019,100C).
+type PrivateInfo struct {
+	// Creator is the private creator string, e.g., "SIEMENS MR HEADER".
+	Creator string
+	Group   uint16
+	// Offset is the element offset within the block, i.e., the low byte of
+	// the element.
+	Offset uint8
+	// Data encoding "UL", "CS", etc.
+	VR string
+	// Human-readable name of the tag, e.g., "NumberOfImagesInMosaic"
+	Name string
+	// Cardinality (# of values expected in the element)
+	VM string
+}
+
+type privateKey struct {
+	creator string
+	group   uint16
+	offset  uint8
+}
+
+var (
+	privateDictMu   sync.RWMutex
+	privateDict     map[privateKey]PrivateInfo
+	privateDictOnce sync.Once
+)
+
+func maybeInitPrivateDict() {
+	privateDictOnce.Do(func() {
+		privateDict = make(map[privateKey]PrivateInfo, len(bundledPrivateDict))
+		for _, info := range bundledPrivateDict {
+			privateDict[privateKeyOf(info)] = info
+		}
+	})
+}
+
+func privateKeyOf(info PrivateInfo) privateKey {
+	return privateKey{creator: strings.TrimSpace(info.Creator), group: info.Group, offset: info.Offset}
+}
+
+// RegisterPrivate adds entries to the private dictionary, replacing any
+// existing entries (including bundled ones) with the same creator, group and
+// offset. It is safe to call concurrently with FindPrivate.
+func RegisterPrivate(entries ...PrivateInfo) {
+	maybeInitPrivateDict()
+	privateDictMu.Lock()
+	defer privateDictMu.Unlock()
+	for _, info := range entries {
+		privateDict[privateKeyOf(info)] = info
+	}
+}
+
+// IsPrivateCreator indicates if t is a private creator element, (gggg,0010)
+// through (gggg,00FF), which reserves a block of private elements in its group.
+func IsPrivateCreator(t Tag) bool {
+	return IsPrivate(t.Group) && t.Element >= 0x0010 && t.Element <= 0x00FF
+}
+
+// PrivateCreatorTag returns the tag of the private creator element that
+// reserves the block of private elements t is in. It returns false if t is not
+// in a block of private elements.
+func PrivateCreatorTag(t Tag) (Tag, bool) {
+	if !IsPrivate(t.Group) || t.Element < 0x1000 {
+		return Tag{}, false
+	}
+	return Tag{Group: t.Group, Element: t.Element >> 8}, true
+}
+
+// FindPrivate finds information about the private tag t, in the block of
+// private elements reserved by creator. Private creator elements themselves
+// are always found, with VR LO. If the tag is not in the private dictionary, it
+// returns an error.
+func FindPrivate(t Tag, creator string) (Info, error) {
+	if IsPrivateCreator(t) {
+		return Info{t, "LO", "PrivateCreator", "1"}, nil
+	}
+	if _, ok := PrivateCreatorTag(t); !ok {
+		return Info{}, fmt.Errorf("tag (0x%x, 0x%x) is not a private tag in a reserved block", t.Group, t.Element)
+	}
+	maybeInitPrivateDict()
+	privateDictMu.RLock()
+	info, ok := privateDict[privateKey{creator: strings.TrimSpace(creator), group: t.Group, offset: uint8(t.Element)}]
+	privateDictMu.RUnlock()
+	if !ok {
+		return Info{}, fmt.Errorf("Could not find private tag (0x%x, 0x%x) of creator %q in dictionary", t.Group, t.Element, creator)
+	}
+	return Info{t, info.VR, info.Name, info.VM}, nil
+}
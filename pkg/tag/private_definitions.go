@@ -0,0 +1,265 @@
+package tag
+
+// Private creator strings used by the bundled private dictionary.
+const (
+	CreatorSiemensCSAHeader    = "SIEMENS CSA HEADER"
+	CreatorSiemensMRHeader     = "SIEMENS MR HEADER"
+	CreatorSiemensCTVA0Coat    = "SIEMENS CT VA0  COAT"
+	CreatorGEIdentification    = "GEMS_IDEN_01"
+	CreatorGEAcquisition       = "GEMS_ACQU_01"
+	CreatorGERelationship      = "GEMS_RELA_01"
+	CreatorGEStudy             = "GEMS_STDY_01"
+	CreatorGESeries            = "GEMS_SERS_01"
+	CreatorGEImage             = "GEMS_IMAG_01"
+	CreatorGEParameters        = "GEMS_PARM_01"
+	CreatorPhilipsImaging      = "Philips Imaging DD 001"
+	CreatorPhilipsMRImaging    = "Philips MR Imaging DD 001"
+	CreatorPhilipsMRImagingDD5 = "Philips MR Imaging DD 005"
+)
+
+// bundledPrivateDict holds commonly used private tags of GE, Siemens and
+// Philips devices. Entries can be added or replaced using RegisterPrivate.
+var bundledPrivateDict = []PrivateInfo{
+	// Siemens CSA header.
+	{CreatorSiemensCSAHeader, 0x0029, 0x08, "CS", "CSAImageHeaderType", "1"},
+	{CreatorSiemensCSAHeader, 0x0029, 0x09, "LO", "CSAImageHeaderVersion", "1"},
+	{CreatorSiemensCSAHeader, 0x0029, 0x10, "OB", "CSAImageHeaderInfo", "1"},
+	{CreatorSiemensCSAHeader, 0x0029, 0x18, "CS", "CSASeriesHeaderType", "1"},
+	{CreatorSiemensCSAHeader, 0x0029, 0x19, "LO", "CSASeriesHeaderVersion", "1"},
+	{CreatorSiemensCSAHeader, 0x0029, 0x20, "OB", "CSASeriesHeaderInfo", "1"},
+
+	// Siemens MR.
+	{CreatorSiemensMRHeader, 0x0019, 0x08, "CS", "CSAImageHeaderType", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x09, "LO", "CSAImageHeaderVersion", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x0A, "US", "NumberOfImagesInMosaic", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x0B, "DS", "SliceMeasurementDuration", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x0C, "IS", "BValue", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x0D, "CS", "DiffusionDirectionality", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x0E, "FD", "DiffusionGradientDirection", "3"},
+	{CreatorSiemensMRHeader, 0x0019, 0x0F, "SH", "GradientMode", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x11, "SH", "FlowCompensation", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x12, "SL", "TablePositionOrigin", "3"},
+	{CreatorSiemensMRHeader, 0x0019, 0x13, "SL", "ImaAbsTablePosition", "3"},
+	{CreatorSiemensMRHeader, 0x0019, 0x14, "IS", "ImaRelTablePosition", "3"},
+	{CreatorSiemensMRHeader, 0x0019, 0x15, "FD", "SlicePosition_PCS", "3"},
+	{CreatorSiemensMRHeader, 0x0019, 0x16, "DS", "TimeAfterStart", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x17, "DS", "SliceResolution", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x18, "DS", "RealDwellTime", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x27, "FD", "BMatrix", "6"},
+	{CreatorSiemensMRHeader, 0x0019, 0x28, "FD", "BandwidthPerPixelPhaseEncode", "1"},
+	{CreatorSiemensMRHeader, 0x0019, 0x29, "FD", "MosaicRefAcqTimes", "1-n"},
+	{CreatorSiemensMRHeader, 0x0051, 0x08, "CS", "CSAImageHeaderType", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x09, "LO", "CSAImageHeaderVersion", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x0A, "LO", "TimeOfAcquisition", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x0B, "LO", "AcquisitionMatrixText", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x0C, "LO", "FieldOfView", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x0D, "SH", "SlicePositionText", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x0E, "LO", "ImageOrientation", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x0F, "LO", "CoilString", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x11, "LO", "PATModeText", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x12, "SH", "TablePositionText", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x13, "SH", "PositivePCSDirections", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x16, "LO", "ImageTypeText", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x17, "SH", "SliceThicknessText", "1"},
+	{CreatorSiemensMRHeader, 0x0051, 0x19, "LO", "ScanOptionsText", "1"},
+
+	// Siemens CT.
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x10, "DS", "DistanceSourceToSourceSideCollimator", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x11, "DS", "DistanceSourceToDetectorSideCollimator", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x20, "IS", "NumberOfPossibleChannels", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x21, "IS", "MeanChannelNumber", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x22, "DS", "DetectorSpacing", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x23, "DS", "DetectorCenter", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x24, "DS", "ReadingIntegrationTime", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x50, "DS", "DetectorAlignment", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x60, "DS", "FocusAlignment", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x65, "UL", "FocalSpotDeflectionAmplitude", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x66, "UL", "FocalSpotDeflectionPhase", "1"},
+	{CreatorSiemensCTVA0Coat, 0x0019, 0x67, "UL", "FocalSpotDeflectionOffset", "1"},
+
+	// GE identification.
+	{CreatorGEIdentification, 0x0009, 0x01, "LO", "FullFidelity", "1"},
+	{CreatorGEIdentification, 0x0009, 0x02, "SH", "SuiteID", "1"},
+	{CreatorGEIdentification, 0x0009, 0x04, "SH", "ProductID", "1"},
+	{CreatorGEIdentification, 0x0009, 0x27, "SL", "ImageActualDate", "1"},
+	{CreatorGEIdentification, 0x0009, 0x30, "SH", "ServiceID", "1"},
+	{CreatorGEIdentification, 0x0009, 0x31, "SH", "MobileLocationNumber", "1"},
+	{CreatorGEIdentification, 0x0009, 0xE3, "UI", "EquipmentUID", "1"},
+	{CreatorGEIdentification, 0x0009, 0xE6, "SH", "GenesisVersionNow", "1"},
+	{CreatorGEIdentification, 0x0009, 0xE7, "UL", "ExamRecordChecksum", "1"},
+	{CreatorGEIdentification, 0x0009, 0xE9, "SL", "ActualSeriesDataTimeStamp", "1"},
+
+	// GE acquisition.
+	{CreatorGEAcquisition, 0x0019, 0x02, "SL", "NumberOfCellsInDetector", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x03, "DS", "CellNumberAtTheta", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x04, "DS", "CellSpacing", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x0F, "DS", "HorizontalFrameOfReference", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x11, "SS", "SeriesContrast", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x18, "LO", "FirstScanRAS", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x1A, "LO", "LastScanRAS", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x23, "DS", "TableSpeed", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x24, "DS", "MidScanTime", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x27, "DS", "RotationSpeed", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x39, "SS", "ScanFOVType", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x84, "DS", "AutoPrescanCenterFrequency", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x87, "DS", "PulseSequenceMode", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x8F, "SS", "NumberOfShots", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x9B, "SS", "PulseSequenceModeFlag", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x9C, "LO", "PulseSequenceName", "1"},
+	{CreatorGEAcquisition, 0x0019, 0x9E, "SH", "InternalPulseSequenceName", "1"},
+	{CreatorGEAcquisition, 0x0019, 0xA7, "DS", "UserData0", "1"},
+	{CreatorGEAcquisition, 0x0019, 0xBB, "DS", "UserData20", "1"},
+	{CreatorGEAcquisition, 0x0019, 0xBC, "DS", "UserData21", "1"},
+	{CreatorGEAcquisition, 0x0019, 0xBD, "DS", "UserData22", "1"},
+	{CreatorGEAcquisition, 0x0019, 0xE0, "DS", "DiffusionGradientDirection", "1"},
+
+	// GE relationship.
+	{CreatorGERelationship, 0x0021, 0x03, "SS", "SeriesFromWhichPrescribed", "1"},
+	{CreatorGERelationship, 0x0021, 0x05, "SH", "GenesisVersionNow", "1"},
+	{CreatorGERelationship, 0x0021, 0x07, "UL", "SeriesRecordChecksum", "1"},
+	{CreatorGERelationship, 0x0021, 0x18, "SH", "GenesisVersionNow", "1"},
+	{CreatorGERelationship, 0x0021, 0x19, "UL", "AcqReconRecordChecksum", "1"},
+	{CreatorGERelationship, 0x0021, 0x20, "DS", "TableStartLocation", "1"},
+	{CreatorGERelationship, 0x0021, 0x35, "SS", "SeriesFromWhichPrescribed", "1"},
+	{CreatorGERelationship, 0x0021, 0x36, "SS", "ImageFromWhichPrescribed", "1"},
+	{CreatorGERelationship, 0x0021, 0x37, "SS", "ScreenFormat", "1"},
+	{CreatorGERelationship, 0x0021, 0x4A, "LO", "AnatomicalReferenceForScout", "1"},
+	{CreatorGERelationship, 0x0021, 0x4E, "US", "LocationsInAcquisition", "1"},
+	{CreatorGERelationship, 0x0021, 0x92, "SL", "Ordering", "1"},
+
+	// GE study.
+	{CreatorGEStudy, 0x0023, 0x01, "SL", "NumberOfSeriesInStudy", "1"},
+	{CreatorGEStudy, 0x0023, 0x02, "SL", "NumberOfUnarchivedSeries", "1"},
+	{CreatorGEStudy, 0x0023, 0x10, "SS", "ReferenceImageField", "1"},
+	{CreatorGEStudy, 0x0023, 0x50, "SS", "SummaryImage", "1"},
+	{CreatorGEStudy, 0x0023, 0x70, "FD", "StartTimeSecsInFirstAxial", "1"},
+	{CreatorGEStudy, 0x0023, 0x74, "SL", "NumberOfUpdatesToHeader", "1"},
+	{CreatorGEStudy, 0x0023, 0x7D, "SS", "IndicatesIfStudyHasCompleteInfo", "1"},
+
+	// GE series.
+	{CreatorGESeries, 0x0025, 0x06, "SS", "LastPulseSequenceUsed", "1"},
+	{CreatorGESeries, 0x0025, 0x07, "SL", "ImagesInSeries", "1"},
+	{CreatorGESeries, 0x0025, 0x10, "SL", "LandmarkCounter", "1"},
+	{CreatorGESeries, 0x0025, 0x11, "SS", "NumberOfAcquisitions", "1"},
+	{CreatorGESeries, 0x0025, 0x14, "SL", "IndicatesNumberOfUpdatesToHeader", "1"},
+	{CreatorGESeries, 0x0025, 0x17, "SL", "SeriesCompleteFlag", "1"},
+	{CreatorGESeries, 0x0025, 0x18, "SL", "NumberOfImagesArchived", "1"},
+	{CreatorGESeries, 0x0025, 0x19, "SL", "LastImageNumberUsed", "1"},
+	{CreatorGESeries, 0x0025, 0x1A, "SH", "PrimaryReceiverSuiteAndHost", "1"},
+
+	// GE image.
+	{CreatorGEImage, 0x0027, 0x06, "SL", "ImageArchiveFlag", "1"},
+	{CreatorGEImage, 0x0027, 0x10, "SS", "ScoutType", "1"},
+	{CreatorGEImage, 0x0027, 0x1C, "SL", "VmaMamp", "1"},
+	{CreatorGEImage, 0x0027, 0x1D, "SS", "VmaPhase", "1"},
+	{CreatorGEImage, 0x0027, 0x1E, "SL", "VmaMod", "1"},
+	{CreatorGEImage, 0x0027, 0x1F, "SL", "VmaClip", "1"},
+	{CreatorGEImage, 0x0027, 0x20, "SS", "SmartScanOnOffFlag", "1"},
+	{CreatorGEImage, 0x0027, 0x30, "SH", "ForeignImageRevision", "1"},
+	{CreatorGEImage, 0x0027, 0x31, "SS", "ImagingMode", "1"},
+	{CreatorGEImage, 0x0027, 0x32, "SS", "PulseSequence", "1"},
+	{CreatorGEImage, 0x0027, 0x33, "SL", "ImagingOptions", "1"},
+	{CreatorGEImage, 0x0027, 0x35, "SS", "PlaneType", "1"},
+	{CreatorGEImage, 0x0027, 0x36, "SL", "ObliquePlane", "1"},
+	{CreatorGEImage, 0x0027, 0x40, "SH", "RASLetterOfImageLocation", "1"},
+	{CreatorGEImage, 0x0027, 0x41, "FL", "ImageLocation", "1"},
+	{CreatorGEImage, 0x0027, 0x60, "FL", "ImageDimensionX", "1"},
+	{CreatorGEImage, 0x0027, 0x61, "FL", "ImageDimensionY", "1"},
+	{CreatorGEImage, 0x0027, 0x62, "FL", "NumberOfExcitations", "1"},
+
+	// GE parameters.
+	{CreatorGEParameters, 0x0043, 0x01, "SS", "BitmapOfPrescanOptions", "1"},
+	{CreatorGEParameters, 0x0043, 0x02, "SS", "GradientOffsetInX", "1"},
+	{CreatorGEParameters, 0x0043, 0x03, "SS", "GradientOffsetInY", "1"},
+	{CreatorGEParameters, 0x0043, 0x04, "SS", "GradientOffsetInZ", "1"},
+	{CreatorGEParameters, 0x0043, 0x06, "SS", "NumberOfEPIShots", "1"},
+	{CreatorGEParameters, 0x0043, 0x07, "SS", "ViewsPerSegment", "1"},
+	{CreatorGEParameters, 0x0043, 0x08, "SS", "RespiratoryRateBpm", "1"},
+	{CreatorGEParameters, 0x0043, 0x09, "SS", "RespiratoryTriggerPoint", "1"},
+	{CreatorGEParameters, 0x0043, 0x0A, "SS", "TypeOfReceiverUsed", "1"},
+	{CreatorGEParameters, 0x0043, 0x27, "SH", "ScanPitchRatio", "1"},
+	{CreatorGEParameters, 0x0043, 0x2C, "SS", "EffectiveEchoSpacing", "1"},
+	{CreatorGEParameters, 0x0043, 0x39, "IS", "SliceGroupOffsets", "4"},
+	{CreatorGEParameters, 0x0043, 0x6F, "DS", "ScannerTableEntry", "3-4"},
+	{CreatorGEParameters, 0x0043, 0x88, "UI", "PUREAcquisitionCalibrationSeriesUID", "1"},
+
+	// Philips imaging.
+	{CreatorPhilipsImaging, 0x2001, 0x01, "FL", "ChemicalShift", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x02, "IS", "ChemicalShiftNumberMR", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x03, "FL", "DiffusionBFactor", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x04, "CS", "DiffusionDirection", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x06, "CS", "ImageEnhanced", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x07, "CS", "ImageTypeEDES", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x08, "IS", "PhaseNumber", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x09, "FL", "ImagePrepulseDelay", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x0A, "IS", "SliceNumberMR", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x0B, "CS", "SliceOrientation", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x0C, "CS", "ArrhythmiaRejection", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x0E, "CS", "CardiacCycled", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x0F, "SS", "CardiacGateWidth", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x10, "CS", "CardiacSync", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x11, "FL", "DiffusionEchoTime", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x12, "CS", "DynamicSeries", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x13, "SL", "EPIFactor", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x14, "SL", "NumberOfEchoes", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x15, "SS", "NumberOfLocations", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x16, "SS", "NumberOfPCDirections", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x17, "SL", "NumberOfPhases", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x18, "SL", "NumberOfSlices", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x19, "CS", "PartialMatrixScanned", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x1A, "FL", "PCVelocity", "1-n"},
+	{CreatorPhilipsImaging, 0x2001, 0x1B, "FL", "PrepulseDelay", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x1C, "CS", "PrepulseType", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x1D, "IS", "ReconstructionNumberMR", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x1F, "CS", "RespirationSync", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x20, "LO", "ScanningTechnique", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x21, "CS", "SPIR", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x22, "FL", "WaterFatShift", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x23, "DS", "FlipAnglePhilips", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x24, "CS", "SeriesIsInteractive", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x25, "SH", "EchoTimeDisplayMR", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x2D, "SS", "StackNumberOfSlices", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x32, "FL", "StackRadialAngle", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x33, "CS", "StackRadialAxis", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x35, "SS", "StackSliceNumber", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x36, "CS", "StackType", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x5F, "SQ", "StackSequence", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x60, "SL", "NumberOfStacks", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x63, "CS", "ExaminationSource", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x81, "IS", "NumberOfDynamicScans", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x82, "IS", "EchoTrainLength", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x83, "DS", "ImagingFrequency", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x84, "DS", "InversionTime", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x85, "DS", "MagneticFieldStrength", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x86, "IS", "NrOfPhaseEncodingSteps", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x87, "SH", "ImagedNucleus", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x88, "DS", "NumberOfAverages", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x89, "DS", "PhaseFOVPercent", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x8A, "DS", "SamplingPercent", "1"},
+	{CreatorPhilipsImaging, 0x2001, 0x8B, "SH", "TransmittingCoil", "1"},
+
+	// Philips MR imaging.
+	{CreatorPhilipsMRImaging, 0x2005, 0x00, "FL", "ImageAngulationAP", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x01, "FL", "ImageAngulationFH", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x02, "FL", "ImageAngulationRL", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x08, "FL", "ImageOffCentreAP", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x09, "FL", "ImageOffCentreFH", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x0A, "FL", "ImageOffCentreRL", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x0B, "FL", "MaxFP", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x0C, "FL", "MinFP", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x0D, "FL", "ScaleIntercept", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x0E, "FL", "ScaleSlope", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x0F, "DS", "WindowCenter", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x10, "DS", "WindowWidth", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x11, "CS", "ImageType", "1-n"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x20, "SL", "NumberOfChemicalShift", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0x80, "SQ", "SpectroExamcard", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0xA0, "FL", "ContrastBolusStartTime", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0xB0, "FL", "DiffusionDirectionRL", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0xB1, "FL", "DiffusionDirectionAP", "1"},
+	{CreatorPhilipsMRImaging, 0x2005, 0xB2, "FL", "DiffusionDirectionFH", "1"},
+
+	// Philips MR imaging, block 5.
+	{CreatorPhilipsMRImagingDD5, 0x2005, 0x53, "FL", "DiffusionBValue", "1"},
+	{CreatorPhilipsMRImagingDD5, 0x2005, 0x54, "FL", "DiffusionGradientOrientation", "3"},
+}
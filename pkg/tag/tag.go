@@ -78,6 +78,19 @@ type Info struct {
 	VM string
 }
 
+// GroupRange is a range of repeating groups, such as the overlay groups
+// (6000-60FF,eeee), in which the same elements are defined for each group.
+// Only the even groups in the range are included, as odd groups are private.
+type GroupRange struct {
+	First uint16
+	Last  uint16
+}
+
+// Contains indicates if group is one of the repeating groups in the range.
+func (r GroupRange) Contains(group uint16) bool {
+	return group >= r.First && group <= r.Last && (group-r.First)%2 == 0
+}
+
 // MetadataGroup is the value of Tag.Group for metadata tags.
 const MetadataGroup = 2
 
@@ -114,6 +127,10 @@ const (
 	VRDate
 	// VRPixelData means the element stores a PixelDataInfo
 	VRPixelData
+	// VRInt64List element stores a list of int64s
+	VRInt64List
+	// VRUInt64List element stores a list of uint64s
+	VRUInt64List
 )
 
 // GetVRKind returns the golang value encoding of an element with <tag, vr>.
@@ -128,9 +145,9 @@ func GetVRKind(tag Tag, vr string) VRKind {
 		return VRDate
 	case "AT":
 		return VRTagList
-	case "OW", "OB":
+	case "OW", "OB", "OL", "OV":
 		return VRBytes
-	case "LT", "UT":
+	case "LT", "UT", "UR":
 		return VRString
 	case "UL":
 		return VRUInt32List
@@ -140,9 +157,13 @@ func GetVRKind(tag Tag, vr string) VRKind {
 		return VRUInt16List
 	case "SS":
 		return VRInt16List
-	case "FL":
+	case "SV":
+		return VRInt64List
+	case "UV":
+		return VRUInt64List
+	case "FL", "OF":
 		return VRFloat32List
-	case "FD":
+	case "FD", "OD":
 		return VRFloat64List
 	case "SQ":
 		return VRSequence
@@ -151,11 +172,16 @@ func GetVRKind(tag Tag, vr string) VRKind {
 	}
 }
 
-// Find finds information about the given tag. If the tag is not part of
-// the DICOM standard, or is retired from the standard, it returns an error.
+// Find finds information about the given tag. Tags in repeating groups, such as
+// the overlay groups 60xx, are found for any group in the range. If the tag is
+// not part of the DICOM standard, or is retired from the standard, it returns
+// an error.
 func Find(tag Tag) (Info, error) {
 	maybeInitTagDict()
 	entry, ok := tagDict[tag]
+	if !ok {
+		entry, ok = findRepeating(tag)
+	}
 	if !ok {
 		// (0000-u-ffff,0000)	UL	GenericGroupLength	1	GENERIC
 		if tag.Group%2 == 0 && tag.Element == 0x0000 {
@@ -167,6 +193,20 @@ func Find(tag Tag) (Info, error) {
 	return entry, nil
 }
 
+// findRepeating finds information about a tag in a repeating group.
+func findRepeating(tag Tag) (Info, bool) {
+	for _, r := range repeatingGroups {
+		if !r.Contains(tag.Group) {
+			continue
+		}
+		if entry, ok := repeatingTagDict[Tag{r.First, tag.Element}]; ok {
+			entry.Tag = tag
+			return entry, true
+		}
+	}
+	return Info{}, false
+}
+
 // MustFind is like FindTag, but panics on error.
 func MustFind(tag Tag) Info {
 	e, err := Find(tag)
@@ -178,7 +218,8 @@ func MustFind(tag Tag) Info {
 
 // FindByName finds information about the tag with the given name. If the tag is
 // not part of the DICOM standard, or is retired from the standard, it returns
-// an error.
+// an error. For tags in repeating groups, the tag in the first group of the
+// range is returned.
 //
 //   Example: FindTagByName("TransferSyntaxUID")
 func FindByName(name string) (Info, error) {
@@ -188,6 +229,11 @@ func FindByName(name string) (Info, error) {
 			return ent, nil
 		}
 	}
+	for _, ent := range repeatingTagDict {
+		if ent.Name == name {
+			return ent, nil
+		}
+	}
 	return Info{}, fmt.Errorf("Could not find tag with name %s", name)
 }
 
@@ -196,6 +242,9 @@ func FindByName(name string) (Info, error) {
 func DebugString(tag Tag) string {
 	e, err := Find(tag)
 	if err != nil {
+		if IsPrivateCreator(tag) {
+			return fmt.Sprintf("(%04x,%04x)[PrivateCreator]", tag.Group, tag.Element)
+		}
 		if IsPrivate(tag.Group) {
 			return fmt.Sprintf("(%04x,%04x)[private]", tag.Group, tag.Element)
 		}
@@ -204,17 +253,33 @@ func DebugString(tag Tag) string {
 	return fmt.Sprintf("(%04x,%04x)[%s]", tag.Group, tag.Element, e.Name)
 }
 
-// Split a tag into a group and element, represented as a hex value
-// TODO: support group ranges (6000-60FF,0803)
-func parseTag(tag string) (Tag, error) {
+// Split a tag into a group and element, represented as a hex value. For a
+// repeating group tag, such as (6000-60FF,0803), the tag in the first group of
+// the range is returned along with the range, which is otherwise zero.
+func parseTag(tag string) (Tag, GroupRange, error) {
 	parts := strings.Split(strings.Trim(tag, "()"), ",")
-	group, err := strconv.ParseInt(parts[0], 16, 0)
+	if len(parts) != 2 {
+		return Tag{}, GroupRange{}, fmt.Errorf("invalid tag %q", tag)
+	}
+	groups := strings.Split(parts[0], "-")
+	if len(groups) > 2 {
+		return Tag{}, GroupRange{}, fmt.Errorf("unsupported group range in tag %q", tag)
+	}
+	group, err := strconv.ParseUint(groups[0], 16, 16)
 	if err != nil {
-		return Tag{}, err
+		return Tag{}, GroupRange{}, err
+	}
+	var r GroupRange
+	if len(groups) == 2 {
+		last, err := strconv.ParseUint(groups[1], 16, 16)
+		if err != nil {
+			return Tag{}, GroupRange{}, err
+		}
+		r = GroupRange{First: uint16(group), Last: uint16(last)}
 	}
-	elem, err := strconv.ParseInt(parts[1], 16, 0)
+	elem, err := strconv.ParseUint(parts[1], 16, 16)
 	if err != nil {
-		return Tag{}, err
+		return Tag{}, GroupRange{}, err
 	}
-	return Tag{Group: uint16(group), Element: uint16(elem)}, nil
+	return Tag{Group: uint16(group), Element: uint16(elem)}, r, nil
 }
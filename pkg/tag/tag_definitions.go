@@ -3199,6 +3199,24 @@ var WaveformPaddingValue = Tag{0x5400, 0x100A}
 var WaveformData = Tag{0x5400, 0x1010}
 var FirstOrderPhaseCorrectionAngle = Tag{0x5600, 0x0010}
 var SpectroscopyData = Tag{0x5600, 0x0020}
+var OverlayRows = Tag{0x6000, 0x0010}
+var OverlayColumns = Tag{0x6000, 0x0011}
+var NumberOfFramesInOverlay = Tag{0x6000, 0x0015}
+var OverlayDescription = Tag{0x6000, 0x0022}
+var OverlayType = Tag{0x6000, 0x0040}
+var OverlaySubtype = Tag{0x6000, 0x0045}
+var OverlayOrigin = Tag{0x6000, 0x0050}
+var ImageFrameOrigin = Tag{0x6000, 0x0051}
+var OverlayBitsAllocated = Tag{0x6000, 0x0100}
+var OverlayBitPosition = Tag{0x6000, 0x0102}
+var OverlayActivationLayer = Tag{0x6000, 0x1001}
+var ROIArea = Tag{0x6000, 0x1301}
+var ROIMean = Tag{0x6000, 0x1302}
+var ROIStandardDeviation = Tag{0x6000, 0x1303}
+var OverlayLabel = Tag{0x6000, 0x1500}
+var OverlayData = Tag{0x6000, 0x3000}
+var ExtendedOffsetTable = Tag{0x7FE0, 0x0001}
+var ExtendedOffsetTableLengths = Tag{0x7FE0, 0x0002}
 var PixelData = Tag{0x7FE0, 0x0010}
 var DigitalSignaturesSequence = Tag{0xFFFA, 0xFFFA}
 var DataSetTrailingPadding = Tag{0xFFFC, 0xFFFC}
@@ -3303,6 +3321,9 @@ var ACR_NEMA_ImagePresentationComments = Tag{0x0028, 0x4000}
 var ACR_NEMA_TextGroupLength = Tag{0x4000, 0x0000}
 var ACR_NEMA_TextArbitrary = Tag{0x4000, 0x0010}
 var ACR_NEMA_TextComments = Tag{0x4000, 0x4000}
+var ACR_NEMA_OverlayFormat = Tag{0x6000, 0x0110}
+var ACR_NEMA_OverlayLocation = Tag{0x6000, 0x0200}
+var ACR_NEMA_OverlayComments = Tag{0x6000, 0x4000}
 var ACR_NEMA_2C_CompressionRecognitionCode = Tag{0x0028, 0x005F}
 var ACR_NEMA_2C_CompressionOriginator = Tag{0x0028, 0x0061}
 var ACR_NEMA_2C_CompressionLabel = Tag{0x0028, 0x0062}
@@ -3353,9 +3374,36 @@ var ACR_NEMA_2C_ShiftTableSize = Tag{0x1000, 0x0014}
 var ACR_NEMA_2C_ShiftTableTriplet = Tag{0x1000, 0x0015}
 var ACR_NEMA_2C_ZonalMapGroupLength = Tag{0x1010, 0x0000}
 var ACR_NEMA_2C_ZonalMap = Tag{0x1010, 0x0004}
+var ACR_NEMA_2C_OverlayCompressionCode = Tag{0x6000, 0x0060}
+var ACR_NEMA_2C_OverlayCompressionOriginator = Tag{0x6000, 0x0061}
+var ACR_NEMA_2C_OverlayCompressionLabel = Tag{0x6000, 0x0062}
+var ACR_NEMA_2C_OverlayCompressionDescription = Tag{0x6000, 0x0063}
+var ACR_NEMA_2C_OverlayCompressionStepPointers = Tag{0x6000, 0x0066}
+var ACR_NEMA_2C_OverlayRepeatInterval = Tag{0x6000, 0x0068}
+var ACR_NEMA_2C_OverlayBitsGrouped = Tag{0x6000, 0x0069}
+var ACR_NEMA_2C_OverlayCodeLabel = Tag{0x6000, 0x0800}
+var ACR_NEMA_2C_OverlayNumberOfTables = Tag{0x6000, 0x0802}
+var ACR_NEMA_2C_OverlayCodeTableLocation = Tag{0x6000, 0x0803}
+var ACR_NEMA_2C_OverlayBitsForCodeWord = Tag{0x6000, 0x0804}
+var ACR_NEMA_2C_VariablePixelDataGroupLength = Tag{0x7F00, 0x0000}
+var ACR_NEMA_2C_VariablePixelData = Tag{0x7F00, 0x0010}
+var ACR_NEMA_2C_VariableNextDataGroup = Tag{0x7F00, 0x0011}
+var ACR_NEMA_2C_VariableCoefficientsSDVN = Tag{0x7F00, 0x0020}
+var ACR_NEMA_2C_VariableCoefficientsSDHN = Tag{0x7F00, 0x0030}
+var ACR_NEMA_2C_VariableCoefficientsSDDN = Tag{0x7F00, 0x0040}
 var ACR_NEMA_2C_CoefficientsSDVN = Tag{0x7FE0, 0x0020}
 var ACR_NEMA_2C_CoefficientsSDHN = Tag{0x7FE0, 0x0030}
 var ACR_NEMA_2C_CoefficientsSDDN = Tag{0x7FE0, 0x0040}
+var CurveGroups = GroupRange{0x5000, 0x50FF}
+var OverlayGroups = GroupRange{0x6000, 0x60FF}
+var VariablePixelDataGroups = GroupRange{0x7F00, 0x7FFF}
+
+// repeatingGroups are the ranges of repeating groups in repeatingTagDict.
+var repeatingGroups = []GroupRange{CurveGroups, OverlayGroups, VariablePixelDataGroups}
+
+// repeatingTagDict holds the tags in repeating groups, keyed by the tag in
+// the first group of the range.
+var repeatingTagDict map[Tag]Info
 var tagDict map[Tag]Info
 
 func init() {
@@ -4808,7 +4856,7 @@ func maybeInitTagDict() {
 	tagDict[Tag{0x0028, 0x3002}] = Info{Tag{0x0028, 0x3002}, "US", "LUTDescriptor", "3"}
 	tagDict[Tag{0x0028, 0x3003}] = Info{Tag{0x0028, 0x3003}, "LO", "LUTExplanation", "1"}
 	tagDict[Tag{0x0028, 0x3004}] = Info{Tag{0x0028, 0x3004}, "LO", "ModalityLUTType", "1"}
-	tagDict[Tag{0x0028, 0x3006}] = Info{Tag{0x0028, 0x3006}, "LT", "LUTData", "1-n"}
+	tagDict[Tag{0x0028, 0x3006}] = Info{Tag{0x0028, 0x3006}, "OW", "LUTData", "1-n"}
 	tagDict[Tag{0x0028, 0x3010}] = Info{Tag{0x0028, 0x3010}, "SQ", "VOILUTSequence", "1"}
 	tagDict[Tag{0x0028, 0x3110}] = Info{Tag{0x0028, 0x3110}, "SQ", "SoftcopyVOILUTSequence", "1"}
 	tagDict[Tag{0x0028, 0x6010}] = Info{Tag{0x0028, 0x6010}, "US", "RepresentativeFrameNumber", "1"}
@@ -6564,6 +6612,8 @@ func maybeInitTagDict() {
 	tagDict[Tag{0x5400, 0x1010}] = Info{Tag{0x5400, 0x1010}, "OW", "WaveformData", "1"}
 	tagDict[Tag{0x5600, 0x0010}] = Info{Tag{0x5600, 0x0010}, "OF", "FirstOrderPhaseCorrectionAngle", "1"}
 	tagDict[Tag{0x5600, 0x0020}] = Info{Tag{0x5600, 0x0020}, "OF", "SpectroscopyData", "1"}
+	tagDict[Tag{0x7FE0, 0x0001}] = Info{Tag{0x7FE0, 0x0001}, "OV", "ExtendedOffsetTable", "1"}
+	tagDict[Tag{0x7FE0, 0x0002}] = Info{Tag{0x7FE0, 0x0002}, "OV", "ExtendedOffsetTableLengths", "1"}
 	tagDict[Tag{0x7FE0, 0x0010}] = Info{Tag{0x7FE0, 0x0010}, "OW", "PixelData", "1"}
 	tagDict[Tag{0xFFFA, 0xFFFA}] = Info{Tag{0xFFFA, 0xFFFA}, "SQ", "DigitalSignaturesSequence", "1"}
 	tagDict[Tag{0xFFFC, 0xFFFC}] = Info{Tag{0xFFFC, 0xFFFC}, "OB", "DataSetTrailingPadding", "1"}
@@ -6895,7 +6945,7 @@ func maybeInitTagDict() {
 	tagDict[Tag{0x0028, 0x1111}] = Info{Tag{0x0028, 0x1111}, "US", "RETIRED_LargeRedPaletteColorLookupTableDescriptor", "4"}
 	tagDict[Tag{0x0028, 0x1112}] = Info{Tag{0x0028, 0x1112}, "US", "RETIRED_LargeGreenPaletteColorLookupTableDescriptor", "4"}
 	tagDict[Tag{0x0028, 0x1113}] = Info{Tag{0x0028, 0x1113}, "US", "RETIRED_LargeBluePaletteColorLookupTableDescriptor", "4"}
-	tagDict[Tag{0x0028, 0x1200}] = Info{Tag{0x0028, 0x1200}, "LT", "RETIRED_GrayLookupTableData", "1-n"}
+	tagDict[Tag{0x0028, 0x1200}] = Info{Tag{0x0028, 0x1200}, "OW", "RETIRED_GrayLookupTableData", "1-n"}
 	tagDict[Tag{0x0028, 0x1211}] = Info{Tag{0x0028, 0x1211}, "OW", "RETIRED_LargeRedPaletteColorLookupTableData", "1"}
 	tagDict[Tag{0x0028, 0x1212}] = Info{Tag{0x0028, 0x1212}, "OW", "RETIRED_LargeGreenPaletteColorLookupTableData", "1"}
 	tagDict[Tag{0x0028, 0x1213}] = Info{Tag{0x0028, 0x1213}, "OW", "RETIRED_LargeBluePaletteColorLookupTableData", "1"}
@@ -7096,4 +7146,77 @@ func maybeInitTagDict() {
 	tagDict[Tag{0x7FE0, 0x0020}] = Info{Tag{0x7FE0, 0x0020}, "OW", "RETIRED_CoefficientsSDVN", "1"}
 	tagDict[Tag{0x7FE0, 0x0030}] = Info{Tag{0x7FE0, 0x0030}, "OW", "RETIRED_CoefficientsSDHN", "1"}
 	tagDict[Tag{0x7FE0, 0x0040}] = Info{Tag{0x7FE0, 0x0040}, "OW", "RETIRED_CoefficientsSDDN", "1"}
+	repeatingTagDict = make(map[Tag]Info)
+	repeatingTagDict[Tag{0x6000, 0x0010}] = Info{Tag{0x6000, 0x0010}, "US", "OverlayRows", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0011}] = Info{Tag{0x6000, 0x0011}, "US", "OverlayColumns", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0015}] = Info{Tag{0x6000, 0x0015}, "IS", "NumberOfFramesInOverlay", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0022}] = Info{Tag{0x6000, 0x0022}, "LO", "OverlayDescription", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0040}] = Info{Tag{0x6000, 0x0040}, "CS", "OverlayType", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0045}] = Info{Tag{0x6000, 0x0045}, "LO", "OverlaySubtype", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0050}] = Info{Tag{0x6000, 0x0050}, "SS", "OverlayOrigin", "2"}
+	repeatingTagDict[Tag{0x6000, 0x0051}] = Info{Tag{0x6000, 0x0051}, "US", "ImageFrameOrigin", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0100}] = Info{Tag{0x6000, 0x0100}, "US", "OverlayBitsAllocated", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0102}] = Info{Tag{0x6000, 0x0102}, "US", "OverlayBitPosition", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1001}] = Info{Tag{0x6000, 0x1001}, "CS", "OverlayActivationLayer", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1301}] = Info{Tag{0x6000, 0x1301}, "IS", "ROIArea", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1302}] = Info{Tag{0x6000, 0x1302}, "DS", "ROIMean", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1303}] = Info{Tag{0x6000, 0x1303}, "DS", "ROIStandardDeviation", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1500}] = Info{Tag{0x6000, 0x1500}, "LO", "OverlayLabel", "1"}
+	repeatingTagDict[Tag{0x6000, 0x3000}] = Info{Tag{0x6000, 0x3000}, "OW", "OverlayData", "1"}
+	repeatingTagDict[Tag{0x7F00, 0x0000}] = Info{Tag{0x7F00, 0x0000}, "UL", "ACR_NEMA_2C_VariablePixelDataGroupLength", "1"}
+	repeatingTagDict[Tag{0x5000, 0x0005}] = Info{Tag{0x5000, 0x0005}, "US", "RETIRED_CurveDimensions", "1"}
+	repeatingTagDict[Tag{0x5000, 0x0010}] = Info{Tag{0x5000, 0x0010}, "US", "RETIRED_NumberOfPoints", "1"}
+	repeatingTagDict[Tag{0x5000, 0x0020}] = Info{Tag{0x5000, 0x0020}, "CS", "RETIRED_TypeOfData", "1"}
+	repeatingTagDict[Tag{0x5000, 0x0022}] = Info{Tag{0x5000, 0x0022}, "LO", "RETIRED_CurveDescription", "1"}
+	repeatingTagDict[Tag{0x5000, 0x0030}] = Info{Tag{0x5000, 0x0030}, "SH", "RETIRED_AxisUnits", "1-n"}
+	repeatingTagDict[Tag{0x5000, 0x0040}] = Info{Tag{0x5000, 0x0040}, "SH", "RETIRED_AxisLabels", "1-n"}
+	repeatingTagDict[Tag{0x5000, 0x0103}] = Info{Tag{0x5000, 0x0103}, "US", "RETIRED_DataValueRepresentation", "1"}
+	repeatingTagDict[Tag{0x5000, 0x0104}] = Info{Tag{0x5000, 0x0104}, "US", "RETIRED_MinimumCoordinateValue", "1-n"}
+	repeatingTagDict[Tag{0x5000, 0x0105}] = Info{Tag{0x5000, 0x0105}, "US", "RETIRED_MaximumCoordinateValue", "1-n"}
+	repeatingTagDict[Tag{0x5000, 0x0106}] = Info{Tag{0x5000, 0x0106}, "SH", "RETIRED_CurveRange", "1-n"}
+	repeatingTagDict[Tag{0x5000, 0x0110}] = Info{Tag{0x5000, 0x0110}, "US", "RETIRED_CurveDataDescriptor", "1-n"}
+	repeatingTagDict[Tag{0x5000, 0x0112}] = Info{Tag{0x5000, 0x0112}, "US", "RETIRED_CoordinateStartValue", "1-n"}
+	repeatingTagDict[Tag{0x5000, 0x0114}] = Info{Tag{0x5000, 0x0114}, "US", "RETIRED_CoordinateStepValue", "1-n"}
+	repeatingTagDict[Tag{0x5000, 0x1001}] = Info{Tag{0x5000, 0x1001}, "CS", "RETIRED_CurveActivationLayer", "1"}
+	repeatingTagDict[Tag{0x5000, 0x2000}] = Info{Tag{0x5000, 0x2000}, "US", "RETIRED_AudioType", "1"}
+	repeatingTagDict[Tag{0x5000, 0x2002}] = Info{Tag{0x5000, 0x2002}, "US", "RETIRED_AudioSampleFormat", "1"}
+	repeatingTagDict[Tag{0x5000, 0x2004}] = Info{Tag{0x5000, 0x2004}, "US", "RETIRED_NumberOfChannels", "1"}
+	repeatingTagDict[Tag{0x5000, 0x2006}] = Info{Tag{0x5000, 0x2006}, "UL", "RETIRED_NumberOfSamples", "1"}
+	repeatingTagDict[Tag{0x5000, 0x2008}] = Info{Tag{0x5000, 0x2008}, "UL", "RETIRED_SampleRate", "1"}
+	repeatingTagDict[Tag{0x5000, 0x200A}] = Info{Tag{0x5000, 0x200A}, "UL", "RETIRED_TotalTime", "1"}
+	repeatingTagDict[Tag{0x5000, 0x200C}] = Info{Tag{0x5000, 0x200C}, "OW", "RETIRED_AudioSampleData", "1"}
+	repeatingTagDict[Tag{0x5000, 0x200E}] = Info{Tag{0x5000, 0x200E}, "LT", "RETIRED_AudioComments", "1"}
+	repeatingTagDict[Tag{0x5000, 0x2500}] = Info{Tag{0x5000, 0x2500}, "LO", "RETIRED_CurveLabel", "1"}
+	repeatingTagDict[Tag{0x5000, 0x2600}] = Info{Tag{0x5000, 0x2600}, "SQ", "RETIRED_CurveReferencedOverlaySequence", "1"}
+	repeatingTagDict[Tag{0x5000, 0x2610}] = Info{Tag{0x5000, 0x2610}, "US", "RETIRED_CurveReferencedOverlayGroup", "1"}
+	repeatingTagDict[Tag{0x5000, 0x3000}] = Info{Tag{0x5000, 0x3000}, "OW", "RETIRED_CurveData", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0012}] = Info{Tag{0x6000, 0x0012}, "US", "RETIRED_OverlayPlanes", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0052}] = Info{Tag{0x6000, 0x0052}, "US", "RETIRED_OverlayPlaneOrigin", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0060}] = Info{Tag{0x6000, 0x0060}, "CS", "RETIRED_OverlayCompressionCode", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0061}] = Info{Tag{0x6000, 0x0061}, "SH", "RETIRED_OverlayCompressionOriginator", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0062}] = Info{Tag{0x6000, 0x0062}, "SH", "RETIRED_OverlayCompressionLabel", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0063}] = Info{Tag{0x6000, 0x0063}, "CS", "RETIRED_OverlayCompressionDescription", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0066}] = Info{Tag{0x6000, 0x0066}, "AT", "RETIRED_OverlayCompressionStepPointers", "1-n"}
+	repeatingTagDict[Tag{0x6000, 0x0068}] = Info{Tag{0x6000, 0x0068}, "US", "RETIRED_OverlayRepeatInterval", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0069}] = Info{Tag{0x6000, 0x0069}, "US", "RETIRED_OverlayBitsGrouped", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0110}] = Info{Tag{0x6000, 0x0110}, "CS", "RETIRED_OverlayFormat", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0200}] = Info{Tag{0x6000, 0x0200}, "US", "RETIRED_OverlayLocation", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0800}] = Info{Tag{0x6000, 0x0800}, "CS", "RETIRED_OverlayCodeLabel", "1-n"}
+	repeatingTagDict[Tag{0x6000, 0x0802}] = Info{Tag{0x6000, 0x0802}, "US", "RETIRED_OverlayNumberOfTables", "1"}
+	repeatingTagDict[Tag{0x6000, 0x0803}] = Info{Tag{0x6000, 0x0803}, "AT", "RETIRED_OverlayCodeTableLocation", "1-n"}
+	repeatingTagDict[Tag{0x6000, 0x0804}] = Info{Tag{0x6000, 0x0804}, "US", "RETIRED_OverlayBitsForCodeWord", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1100}] = Info{Tag{0x6000, 0x1100}, "US", "RETIRED_OverlayDescriptorGray", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1101}] = Info{Tag{0x6000, 0x1101}, "US", "RETIRED_OverlayDescriptorRed", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1102}] = Info{Tag{0x6000, 0x1102}, "US", "RETIRED_OverlayDescriptorGreen", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1103}] = Info{Tag{0x6000, 0x1103}, "US", "RETIRED_OverlayDescriptorBlue", "1"}
+	repeatingTagDict[Tag{0x6000, 0x1200}] = Info{Tag{0x6000, 0x1200}, "US", "RETIRED_OverlaysGray", "1-n"}
+	repeatingTagDict[Tag{0x6000, 0x1201}] = Info{Tag{0x6000, 0x1201}, "US", "RETIRED_OverlaysRed", "1-n"}
+	repeatingTagDict[Tag{0x6000, 0x1202}] = Info{Tag{0x6000, 0x1202}, "US", "RETIRED_OverlaysGreen", "1-n"}
+	repeatingTagDict[Tag{0x6000, 0x1203}] = Info{Tag{0x6000, 0x1203}, "US", "RETIRED_OverlaysBlue", "1-n"}
+	repeatingTagDict[Tag{0x6000, 0x4000}] = Info{Tag{0x6000, 0x4000}, "LT", "RETIRED_OverlayComments", "1"}
+	repeatingTagDict[Tag{0x7F00, 0x0010}] = Info{Tag{0x7F00, 0x0010}, "OW", "RETIRED_VariablePixelData", "1"}
+	repeatingTagDict[Tag{0x7F00, 0x0011}] = Info{Tag{0x7F00, 0x0011}, "US", "RETIRED_VariableNextDataGroup", "1"}
+	repeatingTagDict[Tag{0x7F00, 0x0020}] = Info{Tag{0x7F00, 0x0020}, "OW", "RETIRED_VariableCoefficientsSDVN", "1"}
+	repeatingTagDict[Tag{0x7F00, 0x0030}] = Info{Tag{0x7F00, 0x0030}, "OW", "RETIRED_VariableCoefficientsSDHN", "1"}
+	repeatingTagDict[Tag{0x7F00, 0x0040}] = Info{Tag{0x7F00, 0x0040}, "OW", "RETIRED_VariableCoefficientsSDDN", "1"}
 }
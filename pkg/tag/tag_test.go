@@ -30,9 +30,8 @@ func TestFind(t *testing.T) {
 	}
 }
 
-// TODO: add a test for correctly splitting ranges
 func TestSplitTag(t *testing.T) {
-	tag, err := parseTag("(7FE0,0010)")
+	tag, r, err := parseTag("(7FE0,0010)")
 	if err != nil {
 		t.Error(err)
 	}
@@ -42,7 +41,80 @@ func TestSplitTag(t *testing.T) {
 	if tag.Element != 0x0010 {
 		t.Errorf("Error splitting tag. Wrong element: %#x", tag.Element)
 	}
+	if (r != GroupRange{}) {
+		t.Errorf("Error splitting tag. Unexpected group range: %v", r)
+	}
+
+}
+
+func TestSplitTag_groupRange(t *testing.T) {
+	tag, r, err := parseTag("(6000-60FF,0803)")
+	if err != nil {
+		t.Error(err)
+	}
+	if (tag != Tag{0x6000, 0x0803}) {
+		t.Errorf("Error splitting tag. Wrong tag: %v", tag)
+	}
+	if r != OverlayGroups {
+		t.Errorf("Error splitting tag. Wrong group range: %v", r)
+	}
 
+	if _, _, err := parseTag("(0009-o-ffff,0000)"); err == nil {
+		t.Error("Expected an error splitting a tag with an odd group range")
+	}
+}
+
+func TestFind_repeatingGroups(t *testing.T) {
+	cases := []struct {
+		tag     Tag
+		vr      string
+		name    string
+		vrKind  VRKind
+		wantErr bool
+	}{
+		{tag: Tag{0x6000, 0x0010}, vr: "US", name: "OverlayRows", vrKind: VRUInt16List},
+		{tag: Tag{0x6002, 0x0010}, vr: "US", name: "OverlayRows", vrKind: VRUInt16List},
+		{tag: Tag{0x601E, 0x0102}, vr: "US", name: "OverlayBitPosition", vrKind: VRUInt16List},
+		{tag: Tag{0x60FE, 0x3000}, vr: "OW", name: "OverlayData", vrKind: VRBytes},
+		{tag: Tag{0x5010, 0x3000}, vr: "OW", name: "RETIRED_CurveData", vrKind: VRBytes},
+		{tag: Tag{0x7F02, 0x0020}, vr: "OW", name: "RETIRED_VariableCoefficientsSDVN", vrKind: VRBytes},
+		// The DICOM standard entries override the ACR-NEMA ones.
+		{tag: Tag{0x7F00, 0x0010}, vr: "OW", name: "RETIRED_VariablePixelData", vrKind: VRBytes},
+		{tag: Tag{0x6002, 0x0110}, vr: "CS", name: "RETIRED_OverlayFormat", vrKind: VRStringList},
+		// Exact entries take precedence over repeating groups.
+		{tag: Tag{0x7FE0, 0x0010}, vr: "OW", name: "PixelData", vrKind: VRPixelData},
+		// Odd groups are private, and groups outside the range are not found.
+		{tag: Tag{0x6001, 0x0010}, wantErr: true},
+		{tag: Tag{0x6100, 0x0010}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.tag.String(), func(t *testing.T) {
+			info, err := Find(tc.tag)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Find(%v) unexpected error: %v", tc.tag, err)
+			}
+			if tc.wantErr {
+				return
+			}
+			want := Info{tc.tag, tc.vr, tc.name, "1"}
+			if info != want {
+				t.Errorf("Find(%v) = %v, want %v", tc.tag, info, want)
+			}
+			if got := GetVRKind(tc.tag, info.VR); got != tc.vrKind {
+				t.Errorf("GetVRKind(%v, %v) = %v, want %v", tc.tag, info.VR, got, tc.vrKind)
+			}
+		})
+	}
+}
+
+func TestFindByName_repeatingGroups(t *testing.T) {
+	info, err := FindByName("OverlayData")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Tag != OverlayData || !OverlayGroups.Contains(info.Tag.Group) {
+		t.Errorf("Wrong element: %v", info)
+	}
 }
 
 func BenchmarkFindMetaGroupLengthTag(b *testing.B) {
@@ -62,3 +134,97 @@ func BenchmarkFindPixelDataTag(b *testing.B) {
 
 	}
 }
+
+func TestFindPrivate(t *testing.T) {
+	cases := []struct {
+		name    string
+		tag     Tag
+		creator string
+		want    Info
+		wantErr bool
+	}{
+		{
+			name:    "bundled",
+			tag:     Tag{0x0019, 0x100A},
+			creator: CreatorSiemensMRHeader,
+			want:    Info{Tag{0x0019, 0x100A}, "US", "NumberOfImagesInMosaic", "1"},
+		},
+		{
+			name:    "block other than 0x10",
+			tag:     Tag{0x2001, 0x1203},
+			creator: "Philips Imaging DD 001 ",
+			want:    Info{Tag{0x2001, 0x1203}, "FL", "DiffusionBFactor", "1"},
+		},
+		{
+			name: "private creator",
+			tag:  Tag{0x0029, 0x0011},
+			want: Info{Tag{0x0029, 0x0011}, "LO", "PrivateCreator", "1"},
+		},
+		{
+			name:    "unknown creator",
+			tag:     Tag{0x0019, 0x100A},
+			creator: "UNKNOWN",
+			wantErr: true,
+		},
+		{
+			name:    "not private",
+			tag:     Tag{0x0018, 0x100A},
+			creator: CreatorSiemensMRHeader,
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FindPrivate(tc.tag, tc.creator)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("FindPrivate(%v, %q) unexpected error: %v", tc.tag, tc.creator, err)
+			}
+			if got != tc.want {
+				t.Errorf("FindPrivate(%v, %q) = %v, want %v", tc.tag, tc.creator, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegisterPrivate(t *testing.T) {
+	RegisterPrivate(
+		PrivateInfo{Creator: "ACME 1.0", Group: 0x0041, Offset: 0x01, VR: "DS", Name: "AcmeValue", VM: "1"},
+		PrivateInfo{Creator: CreatorGEAcquisition, Group: 0x0019, Offset: 0xBB, VR: "DS", Name: "AcmeUserData", VM: "1"},
+	)
+	defer RegisterPrivate(PrivateInfo{Creator: CreatorGEAcquisition, Group: 0x0019, Offset: 0xBB, VR: "DS", Name: "UserData20", VM: "1"})
+
+	got, err := FindPrivate(Tag{0x0041, 0x1101}, "ACME 1.0")
+	if err != nil {
+		t.Fatalf("FindPrivate unexpected error: %v", err)
+	}
+	if got.Name != "AcmeValue" || got.VR != "DS" {
+		t.Errorf("FindPrivate returned unexpected Info: %v", got)
+	}
+
+	got, err = FindPrivate(Tag{0x0019, 0x10BB}, CreatorGEAcquisition)
+	if err != nil {
+		t.Fatalf("FindPrivate unexpected error: %v", err)
+	}
+	if got.Name != "AcmeUserData" {
+		t.Errorf("RegisterPrivate did not replace the bundled entry, got: %v", got)
+	}
+}
+
+func TestPrivateCreatorTag(t *testing.T) {
+	cases := []struct {
+		tag    Tag
+		want   Tag
+		wantOK bool
+	}{
+		{Tag{0x0029, 0x1010}, Tag{0x0029, 0x0010}, true},
+		{Tag{0x0029, 0xFF01}, Tag{0x0029, 0x00FF}, true},
+		{Tag{0x0029, 0x0010}, Tag{}, false},
+		{Tag{0x0028, 0x1010}, Tag{}, false},
+	}
+	for _, tc := range cases {
+		got, ok := PrivateCreatorTag(tc.tag)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("PrivateCreatorTag(%v) = %v, %v, want %v, %v", tc.tag, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
@@ -33,6 +33,14 @@ var (
 	ExplicitVRLittleEndian         = standardUID("1.2.840.10008.1.2.1")
 	ExplicitVRBigEndian            = standardUID("1.2.840.10008.1.2.2")
 	DeflatedExplicitVRLittleEndian = standardUID("1.2.840.10008.1.2.1.99")
+	JPEGBaseline8Bit               = standardUID("1.2.840.10008.1.2.4.50")
+	JPEGExtended12Bit              = standardUID("1.2.840.10008.1.2.4.51")
+	JPEGLossless                   = standardUID("1.2.840.10008.1.2.4.57")
+	JPEGLosslessSV1                = standardUID("1.2.840.10008.1.2.4.70")
+	JPEGLSLossless                 = standardUID("1.2.840.10008.1.2.4.80")
+	JPEGLSNearLossless             = standardUID("1.2.840.10008.1.2.4.81")
+	JPEG2000                       = standardUID("1.2.840.10008.1.2.4.91")
+	RLELossless                    = standardUID("1.2.840.10008.1.2.5")
 )
 
 // Info holds detailed information about a DICOM UID
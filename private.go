@@ -0,0 +1,49 @@
+package dicom
+
+import (
+	"strings"
+
+	"github.com/suyashkumar/dicom/pkg/tag"
+)
+
+// PrivateCreator returns the private creator that reserved the block of private
+// elements that t is in, from the private creator element (gggg,00xx) in d. It
+// returns ErrorElementNotFound if t is not in a block of private elements, or
+// d has no private creator element for the block. Only the top level elements
+// of d are searched, as private creators are scoped to the Dataset or Sequence
+// Item that contains them.
+func (d *Dataset) PrivateCreator(t tag.Tag) (string, error) {
+	creatorTag, ok := tag.PrivateCreatorTag(t)
+	if !ok {
+		return "", ErrorElementNotFound
+	}
+	elem, err := d.FindElementByTag(creatorTag)
+	if err != nil {
+		return "", err
+	}
+	if elem.Value.ValueType() == Bytes {
+		// The private creator element may itself have been read with VR UN.
+		return strings.TrimRight(string(MustGetBytes(elem.Value)), " \x00"), nil
+	}
+	creator, err := firstString(elem.Value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(creator), nil
+}
+
+// findTagInfo finds information about t in the dictionary, or, if t is a
+// private tag, in the private dictionary using the private creators in d.
+func findTagInfo(d *Dataset, t tag.Tag) (tag.Info, error) {
+	if !tag.IsPrivate(t.Group) {
+		return tag.Find(t)
+	}
+	var creator string
+	if d != nil && !tag.IsPrivateCreator(t) {
+		var err error
+		if creator, err = d.PrivateCreator(t); err != nil {
+			return tag.Info{}, err
+		}
+	}
+	return tag.FindPrivate(t, creator)
+}
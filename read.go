@@ -5,11 +5,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"io"
-	"log"
 	"strconv"
 	"strings"
 	"unicode"
+	"unsafe"
 
 	"github.com/suyashkumar/dicom/pkg/vrraw"
 
@@ -25,16 +24,87 @@ var (
 	// ErrorUnsupportedVR indicates that this VR is not supported.
 	ErrorUnsupportedVR      = errors.New("unsupported VR")
 	errorUnableToParseFloat = errors.New("unable to parse float type")
+
+	errorNonItemInSequence        = errors.New("non item found in sequence")
+	errorMissingDelimitationItem  = errors.New("reached end of data before the delimitation item of an undefined length element")
+	errorImplausibleElementHeader = errors.New("bytes do not look like the start of an element")
+	errorUndefinedLength          = errors.New("undefined length is only allowed for sequences, items and encapsulated PixelData")
+	errorYBRFull422OddColumns     = errors.New("YBR_FULL_422 frames must have an even number of columns")
 )
 
-func readTag(r dicomio.Reader) (*tag.Tag, error) {
-	group, gerr := r.ReadUInt16()
-	element, eerr := r.ReadUInt16()
+// ParseError describes an error encountered while reading an element. It is
+// returned (possibly wrapped) by Parse and Parser.Next, and can be retrieved
+// using errors.As. The underlying cause can be checked using errors.Is.
+type ParseError struct {
+	// Offset is the byte offset in the input at which the error occurred.
+	Offset int64
+	// ElementOffset is the byte offset in the input at which the element being
+	// read starts.
+	ElementOffset int64
+	// Path is the location of the element being read, through any enclosing
+	// Sequences and Items. It does not include the element itself if its tag
+	// could not be read.
+	Path TagPath
+	// VR is the raw VR of the element being read, if it could be read.
+	VR string
+	// VL is the value length of the element being read, if it could be read.
+	VL uint32
+	// Err is the underlying cause of the error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("error reading element %s (VR=%s, VL=%d) at offset %d: %v", e.Path, e.VR, e.VL, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ElementError describes a malformed element that was skipped while parsing
+// in Lenient mode.
+type ElementError struct {
+	// Tag is the tag of the malformed element, if it could be read.
+	Tag tag.Tag
+	// Offset is the byte offset in the input at which the malformed element
+	// starts.
+	Offset int64
+	// Err is the error encountered while reading the element.
+	Err error
+}
 
-	if gerr == nil && eerr == nil {
-		return &tag.Tag{Group: group, Element: element}, nil
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("malformed element %s at offset %d: %v", tag.DebugString(e.Tag), e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// ElementErrors is the list of malformed elements that were skipped while
+// parsing in Lenient mode. It is returned as the error from Parse (alongside
+// the partially parsed Dataset) if any elements were skipped.
+type ElementErrors []*ElementError
+
+func (e ElementErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
 	}
-	return nil, fmt.Errorf("error reading tag: %v %v", gerr, eerr)
+	return fmt.Sprintf("%d malformed elements skipped, first: %v", len(e), e[0])
+}
+
+func readTag(r dicomio.Reader) (*tag.Tag, error) {
+	group, err := r.ReadUInt16()
+	if err != nil {
+		return nil, fmt.Errorf("error reading tag group: %w", err)
+	}
+	element, err := r.ReadUInt16()
+	if err != nil {
+		return nil, fmt.Errorf("error reading tag element: %w", err)
+	}
+	return &tag.Tag{Group: group, Element: element}, nil
 }
 
 func peekTag(r dicomio.Reader) (*tag.Tag, error) {
@@ -80,9 +150,9 @@ func readVL(r dicomio.Reader, isImplicit bool, t tag.Tag, vr string) (uint32, er
 	switch vr {
 	// TODO: Parsed VR should be an enum. Will require refactors of tag pkg.
 	case "NA", vrraw.OtherByte, vrraw.OtherDouble, vrraw.OtherFloat,
-		vrraw.OtherLong, vrraw.OtherWord, vrraw.Sequence, vrraw.Unknown,
+		vrraw.OtherLong, vrraw.OtherVeryLong, vrraw.OtherWord, vrraw.Sequence, vrraw.Unknown,
 		vrraw.UnlimitedCharacters, vrraw.UniversalResourceIdentifier,
-		vrraw.UnlimitedText:
+		vrraw.UnlimitedText, vrraw.SignedVeryLong, vrraw.UnsignedVeryLong:
 		_ = r.Skip(2) // ignore two reserved bytes (0000H)
 		vl, err := r.ReadUInt32()
 		if err != nil {
@@ -110,8 +180,21 @@ func readVL(r dicomio.Reader, isImplicit bool, t tag.Tag, vr string) (uint32, er
 	}
 }
 
-func readValue(r dicomio.Reader, t tag.Tag, vr string, vl uint32, isImplicit bool, d *Dataset, opts *Options) (Value, error) {
+func readValue(r dicomio.Reader, t tag.Tag, vr string, vl uint32, isImplicit bool, d *Dataset, opts *parseState) (Value, error) {
+	if isBulkData(t, vr, vl, opts) {
+		return readBulkDataReference(r, vr, vl, opts)
+	}
+	if t != tag.PixelData && vl != tag.VLUndefinedLength {
+		// PixelData accounts for its own allocations.
+		if err := opts.allocate(int64(vl)); err != nil {
+			return nil, err
+		}
+	}
+
 	vrkind := tag.GetVRKind(t, vr)
+	if vl == tag.VLUndefinedLength && vrkind != tag.VRSequence && vrkind != tag.VRItem && vrkind != tag.VRPixelData {
+		return nil, errorUndefinedLength
+	}
 	// TODO: if we keep consistent function signature, consider a static map of VR to func?
 	switch vrkind {
 	case tag.VRBytes:
@@ -130,12 +213,14 @@ func readValue(r dicomio.Reader, t tag.Tag, vr string, vl uint32, isImplicit boo
 		return readPixelData(r, t, vr, vl, d, opts)
 	case tag.VRFloat32List, tag.VRFloat64List:
 		return readFloat(r, t, vr, vl)
+	case tag.VRInt64List, tag.VRUInt64List:
+		return readVeryLong(r, t, vr, vl)
 	default:
 		return readString(r, t, vr, vl)
 	}
 }
 
-func skipValue(r dicomio.Reader, t tag.Tag, vr string, vl uint32, isImplicit bool, d *Dataset, opts *Options) error {
+func skipValue(r dicomio.Reader, t tag.Tag, vr string, vl uint32, isImplicit bool, d *Dataset, opts *parseState) error {
 	if vl == tag.VLUndefinedLength {
 		// TODO: handle undefined length
 		// log.Println("unable to skip vl of undefined length")
@@ -146,43 +231,10 @@ func skipValue(r dicomio.Reader, t tag.Tag, vr string, vl uint32, isImplicit boo
 	}
 }
 
-func readPixelData(r dicomio.Reader, t tag.Tag, vr string, vl uint32, d *Dataset, opts *Options) (Value,
+func readPixelData(r dicomio.Reader, t tag.Tag, vr string, vl uint32, d *Dataset, opts *parseState) (Value,
 	error) {
 	if vl == tag.VLUndefinedLength {
-		var image PixelDataInfo
-		image.IsEncapsulated = true
-		// The first Item in PixelData is the basic offset table. Skip this for now.
-		// TODO: use basic offset table
-		_, _, err := readRawItem(r)
-		if err != nil {
-			return nil, err
-		}
-
-		for !r.IsLimitExhausted() {
-			data, endOfItems, err := readRawItem(r)
-			if err != nil {
-				break
-			}
-
-			if endOfItems {
-				break
-			}
-
-			f := frame.Frame{
-				Encapsulated: true,
-				EncapsulatedData: frame.EncapsulatedFrame{
-					Data: data,
-				},
-			}
-
-			if opts.FrameChannel != nil {
-				opts.FrameChannel <- &f
-			}
-
-			image.Frames = append(image.Frames, f)
-		}
-
-		return &pixelDataValue{PixelDataInfo: image}, nil
+		return readEncapsulatedPixelData(r, d, opts)
 	}
 
 	// Assume we're reading NativeData data since we have a defined value length as per Part 5 Sec A.4 of DICOM spec.
@@ -191,6 +243,10 @@ func readPixelData(r dicomio.Reader, t tag.Tag, vr string, vl uint32, d *Dataset
 		return nil, errors.New("the Dataset context cannot be nil in order to read Native PixelData")
 	}
 
+	if opts.LazyPixelData && opts.source != nil {
+		return readNativeFramesLazily(r, vl, d, opts)
+	}
+
 	i, _, err := readNativeFrames(r, d, opts)
 
 	if err != nil {
@@ -203,30 +259,140 @@ func readPixelData(r dicomio.Reader, t tag.Tag, vr string, vl uint32, d *Dataset
 
 // readNativeFrames reads NativeData frames from a Decoder based on already parsed pixel information
 // that should be available in parsedData (elements like NumberOfFrames, rows, columns, etc)
-func readNativeFrames(d dicomio.Reader, parsedData *Dataset, opts *Options) (pixelData *PixelDataInfo,
+func readNativeFrames(d dicomio.Reader, parsedData *Dataset, opts *parseState) (pixelData *PixelDataInfo,
 	bytesRead int, err error) {
 	image := PixelDataInfo{
 		IsEncapsulated: false,
 	}
 
+	info, nFrames, err := parseNativeFrameInfo(parsedData, d, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := checkNativeFrames(info, nFrames, d.BytesLeftUntilLimit()); err != nil {
+		return nil, 0, err
+	}
+	// Samples are held in a Buffer of the same size as the encoded frame,
+	// apart from expanded YBR_FULL_422 chrominance.
+	size := int64(nFrames) * info.frameSize() * int64(info.samplesPerPixel) / int64(info.encodedSamplesPerPixel())
+	if !info.compact {
+		// Data holds an int per sample, and a slice per pixel.
+		pixels := int64(nFrames) * int64(info.rows) * int64(info.cols)
+		size = pixels*int64(info.samplesPerPixel)*8 + pixels*24
+	}
+	if err := opts.allocate(size); err != nil {
+		return nil, 0, err
+	}
+
+	// Parse the pixels:
+	image.Frames = make([]frame.Frame, 0, preallocation(d, int64(nFrames), int64(unsafe.Sizeof(frame.Frame{}))))
+	for frameIdx := 0; frameIdx < nFrames; frameIdx++ {
+		if err := opts.ctxErr(); err != nil {
+			return nil, bytesRead, err
+		}
+		currentFrame, err := readNativeFrame(d, info, frameIdx)
+		if err != nil {
+			return nil, bytesRead, err
+		}
+		bytesRead += int(info.frameSize())
+		// write the current frame to the frame channel
+		if err := opts.sendFrame(&currentFrame); err != nil {
+			return nil, bytesRead, err
+		}
+		image.Frames = append(image.Frames, currentFrame)
+	}
+
+	return &image, bytesRead, nil
+}
+
+// readNativeFramesLazily records the location of the NativeData frames in the
+// PixelData value of length vl, and skips over them.
+func readNativeFramesLazily(r dicomio.Reader, vl uint32, parsedData *Dataset, opts *parseState) (Value, error) {
+	info, nFrames, err := parseNativeFrameInfo(parsedData, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNativeFrames(info, nFrames, int64(vl)); err != nil {
+		return nil, err
+	}
+	frameSize := info.frameSize()
+	if int64(nFrames)*frameSize > int64(vl) {
+		return nil, fmt.Errorf("PixelData value length %d is too short for %d frames of %d bytes", vl, nFrames, frameSize)
+	}
+
+	lazy := &lazyFrames{src: opts.source, native: info}
+	start := opts.sourceOffset + r.BytesRead()
+	for i := 0; i < nFrames; i++ {
+		lazy.frames = append(lazy.frames, []byteRange{{offset: start + int64(i)*frameSize, length: frameSize}})
+	}
+	if err := r.Skip(int64(vl)); err != nil {
+		return nil, err
+	}
+	return &pixelDataValue{PixelDataInfo: PixelDataInfo{lazy: lazy}}, nil
+}
+
+// nativeFrameInfo describes the layout of NativeData frames.
+type nativeFrameInfo struct {
+	rows                      int
+	cols                      int
+	bitsAllocated             int
+	samplesPerPixel           int
+	photometricInterpretation string
+	planarConfiguration       int
+	palette                   *frame.PaletteLUT
+	bo                        binary.ByteOrder
+	// compact indicates that the samples are held in Samples instead of
+	// the Data of the frames (see the CompactNativeFrames Option).
+	compact bool
+}
+
+// isYBRFull422 indicates if the frames hold YBR_FULL_422 pixels, which are
+// encoded with horizontally subsampled chrominance: each pair of pixels is
+// encoded as Y1 Y2 Cb Cr. See PS3.3 C.7.6.3.1.2.
+func (n nativeFrameInfo) isYBRFull422() bool {
+	return n.photometricInterpretation == "YBR_FULL_422" && n.samplesPerPixel == 3
+}
+
+// encodedSamplesPerPixel returns the number of samples encoded per pixel, on
+// average.
+func (n nativeFrameInfo) encodedSamplesPerPixel() int {
+	if n.isYBRFull422() {
+		return 2
+	}
+	return n.samplesPerPixel
+}
+
+// frameSize returns the size in bytes of a single frame.
+func (n nativeFrameInfo) frameSize() int64 {
+	return int64(n.rows) * int64(n.cols) * int64(n.encodedSamplesPerPixel()) * int64(n.bitsAllocated/8)
+}
+
+// parseNativeFrameInfo returns the layout and number of NativeData frames based
+// on already parsed pixel information in parsedData, for PixelData about to be
+// read from r.
+func parseNativeFrameInfo(parsedData *Dataset, r dicomio.Reader, opts *parseState) (nativeFrameInfo, int, error) {
 	// Parse information from previously parsed attributes that are needed to parse NativeData Frames:
 	rows, err := parsedData.FindElementByTag(tag.Rows)
 	if err != nil {
-		return nil, 0, err
+		return nativeFrameInfo{}, 0, err
 	}
 
 	cols, err := parsedData.FindElementByTag(tag.Columns)
 	if err != nil {
-		return nil, 0, err
+		return nativeFrameInfo{}, 0, err
 	}
 
 	nof, err := parsedData.FindElementByTag(tag.NumberOfFrames)
 	nFrames := 0
 	if err == nil {
 		// No error, so parse number of frames
-		nFrames, err = strconv.Atoi(MustGetStrings(nof.Value)[0]) // odd that number of frames is encoded as a string...
+		var nofStr string
+		nofStr, err = firstString(nof.Value)
+		if err == nil {
+			nFrames, err = strconv.Atoi(nofStr) // odd that number of frames is encoded as a string...
+		}
 		if err != nil {
-			return nil, 0, err
+			return nativeFrameInfo{}, 0, err
 		}
 	} else {
 		// error fetching NumberOfFrames, so default to 1. TODO: revisit
@@ -235,87 +401,211 @@ func readNativeFrames(d dicomio.Reader, parsedData *Dataset, opts *Options) (pix
 
 	b, err := parsedData.FindElementByTag(tag.BitsAllocated)
 	if err != nil {
-		return nil, 0, err
+		return nativeFrameInfo{}, 0, err
 	}
-	bitsAllocated := MustGetInts(b.Value)[0]
 
 	s, err := parsedData.FindElementByTag(tag.SamplesPerPixel)
 	if err != nil {
-		return nil, 0, err
+		return nativeFrameInfo{}, 0, err
 	}
-	samplesPerPixel := MustGetInts(s.Value)[0]
 
-	pixelsPerFrame := MustGetInts(rows.Value)[0] * MustGetInts(cols.Value)[0]
+	info := nativeFrameInfo{bo: r.ByteOrder(), compact: opts.CompactNativeFrames}
+	for _, f := range []struct {
+		elem *Element
+		dst  *int
+	}{
+		{rows, &info.rows},
+		{cols, &info.cols},
+		{b, &info.bitsAllocated},
+		{s, &info.samplesPerPixel},
+	} {
+		if *f.dst, err = firstInt(f.elem.Value); err != nil {
+			return nativeFrameInfo{}, 0, fmt.Errorf("invalid value for %v: %w", f.elem.Tag, err)
+		}
+	}
 
-	// Parse the pixels:
-	image.Frames = make([]frame.Frame, nFrames)
-	bo := d.ByteOrder()
-	bytesAllocated := bitsAllocated / 8
-	pixelBuf := make([]byte, bytesAllocated)
-	for frameIdx := 0; frameIdx < nFrames; frameIdx++ {
-		// Init current frame
-		currentFrame := frame.Frame{
-			Encapsulated: false,
-			NativeData: frame.NativeFrame{
-				BitsPerSample: bitsAllocated,
-				Rows:          MustGetInts(rows.Value)[0],
-				Cols:          MustGetInts(cols.Value)[0],
-				Data:          make([][]int, int(pixelsPerFrame)),
-			},
-		}
-		buf := make([]int, int(pixelsPerFrame)*samplesPerPixel)
-		for pixel := 0; pixel < int(pixelsPerFrame); pixel++ {
-			for value := 0; value < samplesPerPixel; value++ {
-				_, err := io.ReadFull(d, pixelBuf)
-				if err != nil {
-					return nil, bytesRead,
-						fmt.Errorf("could not read uint%d from input: %w", bitsAllocated, err)
-				}
+	switch info.bitsAllocated {
+	case 8, 16, 32:
+	default:
+		return nativeFrameInfo{}, 0, fmt.Errorf("%w for native PixelData: %d", frame.ErrorUnsupportedBitsAllocated, info.bitsAllocated)
+	}
+	if pc, err := parsedData.FindElementByTag(tag.PlanarConfiguration); err == nil {
+		if info.planarConfiguration, err = firstInt(pc.Value); err != nil {
+			return nativeFrameInfo{}, 0, fmt.Errorf("invalid value for %v: %w", pc.Tag, err)
+		}
+	}
+	if pi, err := parsedData.FindElementByTag(tag.PhotometricInterpretation); err == nil {
+		if s, err := firstString(pi.Value); err == nil {
+			info.photometricInterpretation = strings.TrimSpace(s)
+		}
+	}
+	if info.isYBRFull422() && info.cols%2 != 0 {
+		return nativeFrameInfo{}, 0, fmt.Errorf("%w, got %d", errorYBRFull422OddColumns, info.cols)
+	}
+	if info.photometricInterpretation == "PALETTE COLOR" {
+		// An invalid palette does not prevent reading the frames, only
+		// rendering them.
+		if info.palette, err = parsePaletteLUT(parsedData); err != nil {
+			warn(opts, WarningInvalidPaletteLUT, r.BytesRead(), "ignoring palette color lookup table: %v", err)
+		}
+	}
+	return info, nFrames, nil
+}
 
-				if bitsAllocated == 8 {
-					buf[(pixel*samplesPerPixel)+value] = int(pixelBuf[0])
-				} else if bitsAllocated == 16 {
-					buf[(pixel*samplesPerPixel)+value] = int(bo.Uint16(pixelBuf))
-				} else if bitsAllocated == 32 {
-					buf[(pixel*samplesPerPixel)+value] = int(bo.Uint32(pixelBuf))
-				}
-			}
-			currentFrame.NativeData.Data[pixel] = buf[pixel*samplesPerPixel : (pixel+1)*samplesPerPixel]
+// parsePaletteLUT returns the palette color lookup table described in
+// parsedData, from either the normal or the segmented palette color LUT data.
+func parsePaletteLUT(parsedData *Dataset) (*frame.PaletteLUT, error) {
+	palette := &frame.PaletteLUT{}
+	channels := []struct {
+		descriptor, data, segmented tag.Tag
+		dst                         *[]uint16
+	}{
+		{tag.RedPaletteColorLookupTableDescriptor, tag.RedPaletteColorLookupTableData, tag.SegmentedRedPaletteColorLookupTableData, &palette.Red},
+		{tag.GreenPaletteColorLookupTableDescriptor, tag.GreenPaletteColorLookupTableData, tag.SegmentedGreenPaletteColorLookupTableData, &palette.Green},
+		{tag.BluePaletteColorLookupTableDescriptor, tag.BluePaletteColorLookupTableData, tag.SegmentedBluePaletteColorLookupTableData, &palette.Blue},
+	}
+	for i, c := range channels {
+		desc, err := parsedData.FindElementByTag(c.descriptor)
+		if err != nil {
+			return nil, err
+		}
+		if desc.Value.ValueType() != Ints || len(MustGetInts(desc.Value)) != 3 {
+			return nil, fmt.Errorf("%w: invalid descriptor %v", frame.ErrorInvalidPaletteLUT, desc.Value)
 		}
-		image.Frames[frameIdx] = currentFrame
-		if opts.FrameChannel != nil {
-			opts.FrameChannel <- &currentFrame // write the current frame to the frame channel
+		d := MustGetInts(desc.Value)
+		numEntries, firstValue, bits := d[0], d[1], d[2]
+		if numEntries == 0 {
+			// 0 means 2^16 entries, as the number of entries is a US.
+			numEntries = 1 << 16
+		}
+		if i == 0 {
+			palette.FirstValue, palette.BitsPerEntry = firstValue, bits
+		}
+
+		if data, err := parsedData.FindElementByTag(c.data); err == nil {
+			if data.Value.ValueType() != Bytes {
+				return nil, fmt.Errorf("%w: unexpected ValueType for %v: %v", frame.ErrorInvalidPaletteLUT, c.data, data.Value.ValueType())
+			}
+			if *c.dst, err = frame.DecodePaletteData(MustGetBytes(data.Value), numEntries, bits); err != nil {
+				return nil, err
+			}
+		} else if data, err := parsedData.FindElementByTag(c.segmented); err == nil {
+			if data.Value.ValueType() != Bytes {
+				return nil, fmt.Errorf("%w: unexpected ValueType for %v: %v", frame.ErrorInvalidPaletteLUT, c.segmented, data.Value.ValueType())
+			}
+			if *c.dst, err = frame.ExpandSegmentedPaletteData(MustGetBytes(data.Value)); err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, fmt.Errorf("%w: no data for %v", frame.ErrorInvalidPaletteLUT, c.descriptor)
 		}
 	}
+	return palette, palette.Validate()
+}
 
-	bytesRead = bytesAllocated * samplesPerPixel * pixelsPerFrame * nFrames
+// readNativeFrame reads a single NativeData frame, laid out as described by
+// info, from r. frameIdx is only used to describe errors.
+func readNativeFrame(r dicomio.Reader, info nativeFrameInfo, frameIdx int) (frame.Frame, error) {
+	data, err := dicomio.ReadFull(r, info.frameSize())
+	if err != nil {
+		return frame.Frame{}, fmt.Errorf("could not read %d bytes from input for frame %d: %w", info.frameSize(), frameIdx, err)
+	}
+	return decodeNativeFrame(data, info)
+}
 
-	return &image, bytesRead, nil
+// decodeNativeFrame returns the NativeData frame encoded in data, laid out as
+// described by info. The returned frame may share data.
+func decodeNativeFrame(data []byte, info nativeFrameInfo) (frame.Frame, error) {
+	pixelsPerFrame := info.rows * info.cols
+	samplesPerPixel := info.samplesPerPixel
+	// samples holds the samples in the order they are encoded.
+	samples, err := frame.DecodeBuffer(data, info.bo, info.bitsAllocated)
+	if err != nil {
+		return frame.Frame{}, err
+	}
+
+	// buf holds the samples of each pixel together.
+	buf := samples
+	switch {
+	case info.isYBRFull422():
+		if buf, err = frame.NewBuffer(pixelsPerFrame*samplesPerPixel, info.bitsAllocated); err != nil {
+			return frame.Frame{}, err
+		}
+		for pixel := 0; pixel < pixelsPerFrame; pixel++ {
+			pair := pixel / 2 * 4
+			buf.Set(pixel*3, samples.At(pair+pixel%2))
+			buf.Set(pixel*3+1, samples.At(pair+2))
+			buf.Set(pixel*3+2, samples.At(pair+3))
+		}
+	case info.planarConfiguration == 1 && samplesPerPixel > 1:
+		if buf, err = frame.NewBuffer(pixelsPerFrame*samplesPerPixel, info.bitsAllocated); err != nil {
+			return frame.Frame{}, err
+		}
+		for value := 0; value < samplesPerPixel; value++ {
+			for pixel := 0; pixel < pixelsPerFrame; pixel++ {
+				buf.Set(pixel*samplesPerPixel+value, samples.At(value*pixelsPerFrame+pixel))
+			}
+		}
+	}
+	f := frame.Frame{
+		Encapsulated: false,
+		NativeData: frame.NativeFrame{
+			BitsPerSample:             info.bitsAllocated,
+			Rows:                      info.rows,
+			Cols:                      info.cols,
+			Samples:                   buf,
+			SamplesPerPixel:           samplesPerPixel,
+			PhotometricInterpretation: info.photometricInterpretation,
+			PlanarConfiguration:       info.planarConfiguration,
+			Palette:                   info.palette,
+		},
+	}
+	if !info.compact {
+		// The samples are only held in Data, so that edits to Data are
+		// not shadowed by Samples.
+		f.NativeData.Data = f.NativeData.Ints()
+		f.NativeData.Samples, f.NativeData.SamplesPerPixel = nil, 0
+	}
+	return f, nil
 }
 
 // readSequence reads a sequence element (VR = SQ) that contains a subset of Items. Each item contains
 // a set of Elements.
 // See http://dicom.nema.org/medical/dicom/current/output/chtml/part05/sect_7.5.2.html#table_7.5-1
-func readSequence(r dicomio.Reader, t tag.Tag, vr string, vl uint32, opts *Options) (Value, error) {
+func readSequence(r dicomio.Reader, t tag.Tag, vr string, vl uint32, opts *parseState) (Value, error) {
 	var sequences sequencesValue
+	if err := opts.enterSequence(); err != nil {
+		return nil, err
+	}
+	defer opts.leaveSequence()
 
 	if vl == tag.VLUndefinedLength {
 		for {
+			if opts.Lenient && r.IsLimitExhausted() {
+				recordElementError(&ElementError{Tag: t, Offset: r.BytesRead(), Err: errorMissingDelimitationItem}, opts)
+				break
+			}
+			start := r.BytesRead()
 			subElement, err := readElement(r, nil, opts, true)
 			if err != nil {
 				// Stop reading due to error
-				log.Println("error reading subitem, ", err)
 				return nil, err
 			}
+			if subElement == nil {
+				// A malformed element was skipped in Lenient mode.
+				continue
+			}
 			if subElement.Tag == tag.SequenceDelimitationItem {
 				// Stop reading
 				break
 			}
 			if subElement.Tag != tag.Item || subElement.Value.ValueType() != SequenceItem {
 				// This is an error, should be an Item!
-				// TODO: use error var
-				log.Println("Tag is ", subElement.Tag)
-				return nil, fmt.Errorf("non item found in sequence")
+				if opts.Lenient {
+					recordElementError(&ElementError{Tag: subElement.Tag, Offset: start, Err: errorNonItemInSequence}, opts)
+					continue
+				}
+				return nil, errorNonItemInSequence
 			}
 
 			// Append the Item element's dataset of elements to this Sequence's sequencesValue.
@@ -328,11 +618,24 @@ func readSequence(r dicomio.Reader, t tag.Tag, vr string, vl uint32, opts *Optio
 			return nil, err
 		}
 		for !r.IsLimitExhausted() {
-			subElement, err := readElement(r, nil, nil)
+			start := r.BytesRead()
+			subElement, err := readElement(r, nil, opts, true)
 			if err != nil {
-				// TODO: option to ignore errors parsing subelements?
+				r.PopLimit()
 				return nil, err
 			}
+			if subElement == nil {
+				continue
+			}
+			if subElement.Tag != tag.Item || subElement.Value.ValueType() != SequenceItem {
+				if opts.Lenient {
+					recordElementError(&ElementError{Tag: subElement.Tag, Offset: start, Err: errorNonItemInSequence}, opts)
+				} else {
+					// A sequence only holds Items, so the element is dropped.
+					warn(opts, WarningUnexpectedTag, start, "non item %s found in sequence", subElement.Tag)
+				}
+				continue
+			}
 
 			// Append the Item element's dataset of elements to this Sequence's sequencesValue.
 			sequences.value = append(sequences.value, subElement.Value.(*SequenceItemValue))
@@ -345,7 +648,7 @@ func readSequence(r dicomio.Reader, t tag.Tag, vr string, vl uint32, opts *Optio
 
 // readSequenceItem reads an item component of a sequence dicom element and returns an Element
 // with a SequenceItem value.
-func readSequenceItem(r dicomio.Reader, t tag.Tag, vr string, vl uint32, opts *Options) (Value, error) {
+func readSequenceItem(r dicomio.Reader, t tag.Tag, vr string, vl uint32, opts *parseState) (Value, error) {
 	var sequenceItem SequenceItemValue
 
 	// seqElements holds items read so far.
@@ -354,10 +657,18 @@ func readSequenceItem(r dicomio.Reader, t tag.Tag, vr string, vl uint32, opts *O
 
 	if vl == tag.VLUndefinedLength {
 		for {
+			if opts.Lenient && r.IsLimitExhausted() {
+				recordElementError(&ElementError{Tag: t, Offset: r.BytesRead(), Err: errorMissingDelimitationItem}, opts)
+				break
+			}
 			subElem, err := readElement(r, &seqElements, opts, true)
 			if err != nil {
 				return nil, err
 			}
+			if subElem == nil {
+				// A malformed element was skipped in Lenient mode.
+				continue
+			}
 			if subElem.Tag == tag.ItemDelimitationItem {
 				break
 			}
@@ -372,10 +683,14 @@ func readSequenceItem(r dicomio.Reader, t tag.Tag, vr string, vl uint32, opts *O
 		}
 
 		for !r.IsLimitExhausted() {
-			subElem, err := readElement(r, &seqElements, nil)
+			subElem, err := readElement(r, &seqElements, opts, true)
 			if err != nil {
+				r.PopLimit()
 				return nil, err
 			}
+			if subElem == nil {
+				continue
+			}
 
 			sequenceItem.elements = append(sequenceItem.elements, subElem)
 			seqElements.Elements = append(seqElements.Elements, subElem)
@@ -388,33 +703,25 @@ func readSequenceItem(r dicomio.Reader, t tag.Tag, vr string, vl uint32, opts *O
 
 func readBytes(r dicomio.Reader, t tag.Tag, vr string, vl uint32) (Value, error) {
 	// TODO: add special handling of PixelData
-	if vr == vrraw.OtherByte {
-		data := make([]byte, vl)
-		_, err := io.ReadFull(r, data)
-		return &bytesValue{value: data}, err
-	} else if vr == vrraw.OtherWord {
-		// OW -> stream of 16 bit words
-		if vl%2 != 0 {
+	size := otherWordSize(vr)
+	if size == 0 {
+		return nil, ErrorUnsupportedVR
+	}
+	if vl%uint32(size) != 0 {
+		if vr == vrraw.OtherWord {
 			return nil, ErrorOWRequiresEvenVL
 		}
-
-		buf := bytes.NewBuffer(make([]byte, 0, vl))
-		numWords := int(vl / 2)
-		for i := 0; i < numWords; i++ {
-			word, err := r.ReadUInt16()
-			if err != nil {
-				return nil, err
-			}
-			// TODO: support bytes.BigEndian byte ordering
-			err = binary.Write(buf, binary.LittleEndian, word)
-			if err != nil {
-				return nil, err
-			}
-		}
-		return &bytesValue{value: buf.Bytes()}, nil
+		return nil, fmt.Errorf("vr of %s requires a value length that is a multiple of %d, got: %d", vr, size, vl)
 	}
 
-	return nil, ErrorUnsupportedVR
+	data, err := dicomio.ReadFull(r, int64(vl))
+	if err != nil {
+		return nil, err
+	}
+	// OW, OL and OV are streams of words in the byte order of the
+	// transfer syntax, which are always held in little endian byte order.
+	convertWords(data, size, r.ByteOrder(), binary.LittleEndian)
+	return &bytesValue{value: data}, nil
 }
 
 func readString(r dicomio.Reader, t tag.Tag, vr string, vl uint32) (Value, error) {
@@ -430,6 +737,11 @@ func readString(r dicomio.Reader, t tag.Tag, vr string, vl uint32) (Value, error
 		str = strings.Trim(str, " \000")
 	}
 
+	if vr == vrraw.UniversalResourceIdentifier {
+		// UR holds a single value, which may contain backslashes.
+		return &stringsValue{value: []string{str}}, err
+	}
+
 	// Split multiple strings
 	strs := strings.Split(str, "\\")
 
@@ -441,10 +753,11 @@ func readFloat(r dicomio.Reader, t tag.Tag, vr string, vl uint32) (Value, error)
 	if err != nil {
 		return nil, err
 	}
-	retVal := &floatsValue{value: make([]float64, 0, vl/2)}
+	defer r.PopLimit()
+	retVal := &floatsValue{value: make([]float64, 0, preallocation(r, int64(vl/4), 8))}
 	for !r.IsLimitExhausted() {
 		switch vr {
-		case vrraw.FloatingPointSingle:
+		case vrraw.FloatingPointSingle, vrraw.OtherFloat:
 			val, err := r.ReadFloat32()
 			if err != nil {
 				return nil, err
@@ -458,7 +771,7 @@ func readFloat(r dicomio.Reader, t tag.Tag, vr string, vl uint32) (Value, error)
 			}
 			retVal.value = append(retVal.value, pval)
 			break
-		case vrraw.FloatingPointDouble:
+		case vrraw.FloatingPointDouble, vrraw.OtherDouble:
 			val, err := r.ReadFloat64()
 			if err != nil {
 				return nil, err
@@ -469,7 +782,6 @@ func readFloat(r dicomio.Reader, t tag.Tag, vr string, vl uint32) (Value, error)
 			return nil, errorUnableToParseFloat
 		}
 	}
-	r.PopLimit()
 	return retVal, nil
 }
 
@@ -490,7 +802,8 @@ func readInt(r dicomio.Reader, t tag.Tag, vr string, vl uint32) (Value, error) {
 	if err != nil {
 		return nil, err
 	}
-	retVal := &intsValue{value: make([]int, 0, vl/2)}
+	defer r.PopLimit()
+	retVal := &intsValue{value: make([]int, 0, preallocation(r, int64(vl/2), 8))}
 	for !r.IsLimitExhausted() {
 		switch vr {
 		case vrraw.UnsignedShort, vrraw.AttributeTag:
@@ -521,19 +834,68 @@ func readInt(r dicomio.Reader, t tag.Tag, vr string, vl uint32) (Value, error) {
 			return nil, errors.New("unable to parse integer type")
 		}
 	}
-	r.PopLimit()
 	return retVal, err
 }
 
+// readVeryLong reads a value of 64-bit integers (VR = SV or UV).
+func readVeryLong(r dicomio.Reader, t tag.Tag, vr string, vl uint32) (Value, error) {
+	if vl%8 != 0 {
+		return nil, fmt.Errorf("vr of %s requires a value length that is a multiple of 8, got: %d", vr, vl)
+	}
+	switch vr {
+	case vrraw.SignedVeryLong:
+		values := make([]int64, 0, preallocation(r, int64(vl/8), 8))
+		for i := uint32(0); i < vl/8; i++ {
+			val, err := r.ReadInt64()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, val)
+		}
+		return &int64sValue{value: values}, nil
+	case vrraw.UnsignedVeryLong:
+		values := make([]uint64, 0, preallocation(r, int64(vl/8), 8))
+		for i := uint32(0); i < vl/8; i++ {
+			val, err := r.ReadUInt64()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, val)
+		}
+		return &uint64sValue{value: values}, nil
+	}
+	return nil, errors.New("unable to parse very long integer type")
+}
+
 // readElement reads the next element. If the next element is a sequence element,
 // it may result in a collection of Elements. It takes a pointer to the Dataset of
 // elements read so far, since previously read elements may be needed to parse
 // certain Elements (like native PixelData). If the Dataset is nil, it is
 // treated as an empty Dataset.
-func readElement(r dicomio.Reader, d *Dataset, opts *Options, force ...bool) (*Element, error) {
+//
+// In Lenient mode, a malformed element is recorded and skipped, in which case
+// a nil Element and nil error are returned.
+func readElement(r dicomio.Reader, d *Dataset, opts *parseState, force ...bool) (*Element, error) {
+	if err := opts.ctxErr(); err != nil {
+		return nil, err
+	}
+	start := r.BytesRead()
+	if opts.Lenient {
+		// Check the element header looks reasonable before consuming it, so
+		// that we don't interpret garbage as an element.
+		if b, err := r.Peek(8); err == nil && !isPlausibleElementStart(b, r.ByteOrder(), r.IsImplicit(), r.BytesLeftUntilLimit(), false) {
+			return nil, recoverElement(r, start, -1, nil, newParseError(r, start, "", 0, errorImplausibleElementHeader, opts), opts)
+		}
+	}
+
 	t, err := readTag(r)
 	if err != nil {
-		return nil, err
+		return nil, recoverElement(r, start, -1, nil, newParseError(r, start, "", 0, err, opts), opts)
+	}
+	opts.tagPath = append(opts.tagPath, *t)
+	defer func() { opts.tagPath = opts.tagPath[:len(opts.tagPath)-1] }()
+	if err := opts.countElement(); err != nil {
+		return nil, newParseError(r, start, "", 0, err, opts)
 	}
 
 	readImplicit := r.IsImplicit()
@@ -544,12 +906,36 @@ func readElement(r dicomio.Reader, d *Dataset, opts *Options, force ...bool) (*E
 
 	vr, err := readVR(r, readImplicit, *t)
 	if err != nil {
-		return nil, err
+		return nil, recoverElement(r, start, -1, t, newParseError(r, start, "", 0, err, opts), opts)
+	}
+	if readImplicit && vr == tag.UnknownVR && tag.IsPrivate(t.Group) {
+		// Private tags are not in the dictionary, but may be in the private
+		// dictionary under the private creator that reserved them in d.
+		if info, err := findTagInfo(d, *t); err == nil {
+			vr = info.VR
+		}
 	}
 
 	vl, err := readVL(r, readImplicit, *t, vr)
 	if err != nil {
-		return nil, err
+		return nil, recoverElement(r, start, -1, t, newParseError(r, start, vr, 0, err, opts), opts)
+	}
+
+	valueEnd := int64(-1)
+	if vl != tag.VLUndefinedLength {
+		valueEnd = r.BytesRead() + int64(vl)
+	}
+	if err := checkValueLength(r, vl, opts); err != nil {
+		return nil, recoverElement(r, start, valueEnd, t, newParseError(r, start, vr, vl, err, opts), opts)
+	}
+
+	reinterpreted := false
+	if vr == vrraw.Unknown && opts.ReinterpretUN {
+		vr, reinterpreted = reinterpretUN(d, *t, vl)
+	}
+	restoreTransferSyntax := func() {}
+	if reinterpreted {
+		restoreTransferSyntax = setUNTransferSyntax(r)
 	}
 
 	skip := false
@@ -565,64 +951,231 @@ func readElement(r dicomio.Reader, d *Dataset, opts *Options, force ...bool) (*E
 	}
 
 	if skip {
-		if err := skipValue(r, *t, vr, vl, readImplicit, d, opts); err != nil {
-			return nil, err
+		err := skipValue(r, *t, vr, vl, readImplicit, d, opts)
+		restoreTransferSyntax()
+		if err != nil {
+			return nil, recoverElement(r, start, valueEnd, t, newParseError(r, start, vr, vl, err, opts), opts)
 		}
 
 		return nil, nil
 	}
 
 	val, err := readValue(r, *t, vr, vl, readImplicit, d, opts)
+	restoreTransferSyntax()
 	if err != nil {
-		log.Println("error reading value ", err)
-		return nil, err
+		return nil, recoverElement(r, start, valueEnd, t, newParseError(r, start, vr, vl, err, opts), opts)
 	}
 
-	return &Element{Tag: *t, ValueRepresentation: tag.GetVRKind(*t, vr), RawValueRepresentation: vr, ValueLength: vl, Value: val}, nil
+	return &Element{Tag: *t, ValueRepresentation: tag.GetVRKind(*t, vr), RawValueRepresentation: vr, ValueLength: vl, Value: val, ReinterpretedFromUN: reinterpreted}, nil
 
 }
 
+// reinterpretUN returns the VR to parse the value of an element with tag t,
+// VR UN and value length vl as, and whether it should be reinterpreted at all.
+// The VR is looked up in the dictionary (or the private dictionary, using the
+// private creators in d), and an undefined length UN element that is not in
+// the dictionary is a Sequence (see PS3.5 6.2.2).
+func reinterpretUN(d *Dataset, t tag.Tag, vl uint32) (string, bool) {
+	if info, err := findTagInfo(d, t); err == nil && info.VR != vrraw.Unknown {
+		return info.VR, true
+	}
+	if vl == tag.VLUndefinedLength {
+		return vrraw.Sequence, true
+	}
+	return vrraw.Unknown, false
+}
+
+// setUNTransferSyntax switches r to Implicit VR Little Endian, which the value
+// of a reinterpreted UN element is encoded in regardless of the transfer
+// syntax (see PS3.5 6.2.2). It returns a func that restores the transfer
+// syntax r had before.
+func setUNTransferSyntax(r dicomio.Reader) func() {
+	bo, implicit := r.ByteOrder(), r.IsImplicit()
+	r.SetTransferSyntax(binary.LittleEndian, true)
+	return func() {
+		r.SetTransferSyntax(bo, implicit)
+	}
+}
+
+// newParseError returns a ParseError describing err, which occurred while
+// reading the element that starts at offset start. If err already is a
+// ParseError from a nested element, it is returned as is.
+func newParseError(r dicomio.Reader, start int64, vr string, vl uint32, err error, opts *parseState) error {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return err
+	}
+	return &ParseError{
+		Offset:        r.BytesRead(),
+		ElementOffset: start,
+		Path:          append(TagPath(nil), opts.tagPath...),
+		VR:            vr,
+		VL:            vl,
+		Err:           err,
+	}
+}
+
 // Read an Item object as raw bytes, useful when parsing encapsulated PixelData.
 // This returns the read raw item, an indication if this is the end of the set
 // of items, and a possible error.
-func readRawItem(r dicomio.Reader) ([]byte, bool, error) {
+func readRawItem(r dicomio.Reader, opts *parseState) ([]byte, bool, error) {
+	vl, hasValue, endOfItems, err := readRawItemHeader(r, opts)
+	if err != nil || !hasValue {
+		return nil, endOfItems, err
+	}
+	if err := checkValueLength(r, vl, opts); err != nil {
+		return nil, false, err
+	}
+	if err := opts.allocate(int64(vl)); err != nil {
+		return nil, false, err
+	}
+
+	data, err := dicomio.ReadFull(r, int64(vl))
+	if err != nil {
+		return nil, false, err
+	}
+	return data, false, nil
+}
+
+// readRawItemHeader reads the header of an Item in encapsulated PixelData. It
+// returns the length of the Item's value, an indication if the Item has a value
+// that should be read next, an indication if this is the end of the set of
+// items, and a possible error.
+func readRawItemHeader(r dicomio.Reader, opts *parseState) (uint32, bool, bool, error) {
+	start := r.BytesRead()
 	t, err := readTag(r)
 	if err != nil {
-		return nil, true, err
+		return 0, false, true, err
 	}
 	// Item is always encoded implicit. PS3.6 7.5
 	vr, err := readVR(r, true, *t)
 	if err != nil {
-		return nil, true, err
+		return 0, false, true, err
 	}
 	vl, err := readVL(r, true, *t, vr)
 	if err != nil {
-		return nil, true, err
+		return 0, false, true, err
 	}
 
 	if *t == tag.SequenceDelimitationItem {
 		if vl != 0 {
-			log.Printf("SequenceDelimitationItem's VL != 0: %d", vl)
+			warn(opts, WarningInvalidDelimiter, start, "SequenceDelimitationItem's VL != 0: %d", vl)
 		}
-		return nil, true, nil
+		return 0, false, true, nil
 	}
 	if *t != tag.Item {
-		log.Printf("Expect Item in pixeldata but found tag %s", tag.DebugString(*t))
-		return nil, false, nil
+		warn(opts, WarningUnexpectedTag, start, "expected Item in pixeldata but found tag %s", tag.DebugString(*t))
+		return 0, false, false, nil
 	}
 	if vl == tag.VLUndefinedLength {
-		log.Println("Expect defined-length item in pixeldata")
-		return nil, false, nil
+		warn(opts, WarningInvalidDelimiter, start, "expected defined-length item in pixeldata")
+		return 0, false, false, nil
 	}
 	if vr != "NA" {
-		return nil, true, fmt.Errorf("readRawItem: expected VR=NA, got VR=%s", vr)
+		return 0, false, true, fmt.Errorf("readRawItem: expected VR=NA, got VR=%s", vr)
 	}
+	return vl, true, false, nil
+}
 
-	data := make([]byte, vl)
-	_, err = io.ReadFull(r, data)
-	if err != nil {
-		log.Println(err)
-		return nil, false, err
+// recoverElement is called after reading the element that starts at offset
+// start failed with err. Outside of Lenient mode, once parsing has been
+// cancelled or if a resource limit was exceeded, it just returns err. In Lenient mode it records the error and
+// advances r past the malformed element: to valueEnd if the length of the
+// element's value is known (valueEnd >= 0), or else to the next plausible
+// element boundary or the end of the current limit.
+// If r cannot be advanced past the start of the element, err is returned so
+// that the caller can handle it.
+func recoverElement(r dicomio.Reader, start, valueEnd int64, t *tag.Tag, err error, opts *parseState) error {
+	if !opts.Lenient || opts.ctxErr() != nil || isLimitError(err) {
+		return err
 	}
-	return data, false, nil
+
+	if pos := r.BytesRead(); valueEnd > pos {
+		n := valueEnd - pos
+		if left := r.BytesLeftUntilLimit(); n > left {
+			n = left
+		}
+		_ = r.Skip(n)
+	} else if valueEnd < 0 {
+		if pos == start && !r.IsLimitExhausted() {
+			// Always make some progress past the start of the element.
+			_ = r.Skip(1)
+		}
+		skipToPlausibleElement(r)
+	}
+
+	if r.BytesRead() == start {
+		return err
+	}
+
+	elemErr := &ElementError{Offset: start, Err: err}
+	if t != nil {
+		elemErr.Tag = *t
+	}
+	recordElementError(elemErr, opts)
+	return nil
+}
+
+func recordElementError(e *ElementError, opts *parseState) {
+	opts.elementErrors = append(opts.elementErrors, e)
+	warn(opts, WarningMalformedElement, e.Offset, "skipped malformed element %s: %v", tag.DebugString(e.Tag), e.Err)
+}
+
+// skipToPlausibleElement advances r until the next bytes look like the start
+// of an element, or until the current limit is reached.
+func skipToPlausibleElement(r dicomio.Reader) {
+	for r.BytesLeftUntilLimit() >= 8 {
+		b, err := r.Peek(8)
+		if err != nil {
+			break
+		}
+		if isPlausibleElementStart(b, r.ByteOrder(), r.IsImplicit(), r.BytesLeftUntilLimit(), true) {
+			return
+		}
+		if err := r.Skip(1); err != nil {
+			return
+		}
+	}
+	_ = r.Skip(r.BytesLeftUntilLimit())
+}
+
+// isPlausibleElementStart indicates if the 8 bytes in b look like the start of
+// an element header, given that there are bytesLeft bytes left until the
+// current limit. When searching for the next element after a malformed one,
+// strict should be set so that implicit VR elements are only accepted if their
+// tag is in the dictionary.
+func isPlausibleElementStart(b []byte, bo binary.ByteOrder, implicit bool, bytesLeft int64, strict bool) bool {
+	t := tag.Tag{Group: bo.Uint16(b[0:2]), Element: bo.Uint16(b[2:4])}
+	switch t {
+	case tag.Item, tag.ItemDelimitationItem, tag.SequenceDelimitationItem:
+		return true
+	}
+	if t.Group == 0x0000 || t.Group == tag.GroupSeqItem || t.Group == 0xFFFF {
+		return false
+	}
+	if !implicit {
+		_, ok := knownVRs[string(b[4:6])]
+		return ok
+	}
+	if vl := bo.Uint32(b[4:8]); vl != tag.VLUndefinedLength && int64(vl) > bytesLeft-8 {
+		return false
+	}
+	if strict {
+		_, err := tag.Find(t)
+		return err == nil
+	}
+	return true
+}
+
+// knownVRs is the set of VRs that may appear in an explicit VR element header.
+var knownVRs = map[string]struct{}{
+	vrraw.ApplicationEntity: {}, vrraw.AgeString: {}, vrraw.AttributeTag: {}, vrraw.CodeString: {},
+	vrraw.Date: {}, vrraw.DecimalString: {}, vrraw.DateTime: {}, vrraw.FloatingPointSingle: {},
+	vrraw.FloatingPointDouble: {}, vrraw.IntegerString: {}, vrraw.LongString: {}, vrraw.LongText: {},
+	vrraw.OtherByte: {}, vrraw.OtherDouble: {}, vrraw.OtherFloat: {}, vrraw.OtherLong: {},
+	vrraw.OtherVeryLong: {}, vrraw.OtherWord: {}, vrraw.PersonName: {}, vrraw.ShortString: {},
+	vrraw.SignedLong: {}, vrraw.Sequence: {}, vrraw.SignedShort: {}, vrraw.ShortText: {},
+	vrraw.SignedVeryLong: {}, vrraw.Time: {}, vrraw.UnlimitedCharacters: {}, vrraw.UniqueIdentifier: {},
+	vrraw.UnsignedLong: {}, vrraw.Unknown: {}, vrraw.UniversalResourceIdentifier: {}, vrraw.UnsignedShort: {},
+	vrraw.UnlimitedText: {}, vrraw.UnsignedVeryLong: {},
 }
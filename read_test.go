@@ -76,6 +76,13 @@ func TestReadFloat_float64(t *testing.T) {
 			want:        &floatsValue{value: []float64{20.1, 32.22}},
 			expectedErr: nil,
 		},
+		{
+			name:        "OD",
+			floats:      []float64{20.1, 32.22},
+			VR:          vrraw.OtherDouble,
+			want:        &floatsValue{value: []float64{20.1, 32.22}},
+			expectedErr: nil,
+		},
 		{
 			name:        "float64 with wrong VR",
 			floats:      []float64{20.1, 32.22},
@@ -125,6 +132,13 @@ func TestReadFloat_float32(t *testing.T) {
 			want:        &floatsValue{value: []float64{20.1001, 32.22}},
 			expectedErr: nil,
 		},
+		{
+			name:        "OF",
+			floats:      []float32{20.1001, 32.22},
+			VR:          vrraw.OtherFloat,
+			want:        &floatsValue{value: []float64{20.1001, 32.22}},
+			expectedErr: nil,
+		},
 		{
 			name:        "float32 with wrong VR",
 			floats:      []float32{20.1001, 32.22},
@@ -159,11 +173,118 @@ func TestReadFloat_float32(t *testing.T) {
 	}
 }
 
+func TestReadVeryLong(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    interface{}
+		VR      string
+		bo      binary.ByteOrder
+		want    Value
+		wantErr bool
+	}{
+		{
+			name: "SV",
+			data: []int64{-1, 1 << 40},
+			VR:   vrraw.SignedVeryLong,
+			bo:   binary.LittleEndian,
+			want: &int64sValue{value: []int64{-1, 1 << 40}},
+		},
+		{
+			name: "UV",
+			data: []uint64{1, 1<<64 - 1},
+			VR:   vrraw.UnsignedVeryLong,
+			bo:   binary.LittleEndian,
+			want: &uint64sValue{value: []uint64{1, 1<<64 - 1}},
+		},
+		{
+			name: "UV big endian",
+			data: []uint64{0x0102030405060708},
+			VR:   vrraw.UnsignedVeryLong,
+			bo:   binary.BigEndian,
+			want: &uint64sValue{value: []uint64{0x0102030405060708}},
+		},
+		{
+			name:    "VL not a multiple of 8",
+			data:    []uint32{1},
+			VR:      vrraw.SignedVeryLong,
+			bo:      binary.LittleEndian,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := bytes.Buffer{}
+			if err := binary.Write(&data, tc.bo, tc.data); err != nil {
+				t.Fatalf("TestReadVeryLong: Unable to setup test buffer")
+			}
+
+			r, err := dicomio.NewReader(bufio.NewReader(&data), tc.bo, int64(data.Len()))
+			if err != nil {
+				t.Fatalf("TestReadVeryLong: unable to create new dicomio.Reader")
+			}
+
+			got, err := readVeryLong(r, tag.Tag{}, tc.VR, uint32(data.Len()))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("readVeryLong(r, tg, %s, %d) got unexpected error: %v", tc.VR, data.Len(), err)
+			}
+			if diff := cmp.Diff(got, tc.want, cmp.AllowUnexported(allValues...)); diff != "" {
+				t.Errorf("readVeryLong(r, tg, %s, %d) unexpected diff: %s", tc.VR, data.Len(), diff)
+			}
+		})
+	}
+}
+
+func TestReadString(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		VR   string
+		want Value
+	}{
+		{
+			name: "LO",
+			data: `a\b `,
+			VR:   vrraw.LongString,
+			want: &stringsValue{value: []string{"a", "b"}},
+		},
+		{
+			name: "LT",
+			data: `a\b `,
+			VR:   vrraw.LongText,
+			want: &stringsValue{value: []string{"a", "b"}},
+		},
+		{
+			name: "UR",
+			data: `http://host/a\b `,
+			VR:   vrraw.UniversalResourceIdentifier,
+			want: &stringsValue{value: []string{`http://host/a\b`}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := dicomio.NewReader(bufio.NewReader(bytes.NewBufferString(tc.data)), binary.LittleEndian, int64(len(tc.data)))
+			if err != nil {
+				t.Fatalf("TestReadString: unable to create new dicomio.Reader")
+			}
+			got, err := readString(r, tag.Tag{}, tc.VR, uint32(len(tc.data)))
+			if err != nil {
+				t.Fatalf("readString(r, tg, %s, %d) got unexpected error: %v", tc.VR, len(tc.data), err)
+			}
+			if diff := cmp.Diff(got, tc.want, cmp.AllowUnexported(stringsValue{})); diff != "" {
+				t.Errorf("readString(r, tg, %s, %d) unexpected diff: %s", tc.VR, len(tc.data), diff)
+			}
+		})
+	}
+}
+
 func TestReadOWBytes(t *testing.T) {
 	cases := []struct {
 		name        string
 		bytes       []byte
 		VR          string
+		bo          binary.ByteOrder
 		want        Value
 		expectedErr error
 	}{
@@ -171,6 +292,7 @@ func TestReadOWBytes(t *testing.T) {
 			name:        "even-number bytes",
 			bytes:       []byte{0x1, 0x2, 0x3, 0x4},
 			VR:          vrraw.OtherWord,
+			bo:          binary.LittleEndian,
 			want:        &bytesValue{value: []byte{0x1, 0x2, 0x3, 0x4}},
 			expectedErr: nil,
 		},
@@ -178,9 +300,42 @@ func TestReadOWBytes(t *testing.T) {
 			name:        "error on odd-number bytes",
 			bytes:       []byte{0x1, 0x2, 0x3},
 			VR:          vrraw.OtherWord,
+			bo:          binary.LittleEndian,
 			want:        nil,
 			expectedErr: ErrorOWRequiresEvenVL,
 		},
+		{
+			name:        "big endian OW",
+			bytes:       []byte{0x1, 0x2, 0x3, 0x4},
+			VR:          vrraw.OtherWord,
+			bo:          binary.BigEndian,
+			want:        &bytesValue{value: []byte{0x2, 0x1, 0x4, 0x3}},
+			expectedErr: nil,
+		},
+		{
+			name:        "big endian OB",
+			bytes:       []byte{0x1, 0x2, 0x3, 0x4},
+			VR:          vrraw.OtherByte,
+			bo:          binary.BigEndian,
+			want:        &bytesValue{value: []byte{0x1, 0x2, 0x3, 0x4}},
+			expectedErr: nil,
+		},
+		{
+			name:        "big endian OL",
+			bytes:       []byte{0x1, 0x2, 0x3, 0x4},
+			VR:          vrraw.OtherLong,
+			bo:          binary.BigEndian,
+			want:        &bytesValue{value: []byte{0x4, 0x3, 0x2, 0x1}},
+			expectedErr: nil,
+		},
+		{
+			name:        "big endian OV",
+			bytes:       []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8},
+			VR:          vrraw.OtherVeryLong,
+			bo:          binary.BigEndian,
+			want:        &bytesValue{value: []byte{0x8, 0x7, 0x6, 0x5, 0x4, 0x3, 0x2, 0x1}},
+			expectedErr: nil,
+		},
 	}
 
 	for _, tc := range cases {
@@ -190,7 +345,7 @@ func TestReadOWBytes(t *testing.T) {
 				t.Errorf("TestReadOWBytes: Unable to setup test buffer")
 			}
 
-			r, err := dicomio.NewReader(bufio.NewReader(&data), binary.LittleEndian, int64(data.Len()))
+			r, err := dicomio.NewReader(bufio.NewReader(&data), tc.bo, int64(data.Len()))
 			if err != nil {
 				t.Errorf("TestReadOWBytes: unable to create new dicomio.Reader")
 			}
@@ -211,9 +366,37 @@ func TestReadNativeFrames(t *testing.T) {
 		Name              string
 		existingData      Dataset
 		data              []uint16
+		bo                binary.ByteOrder
 		expectedPixelData *PixelDataInfo
 		expectedError     error
 	}{
+		{
+			Name: "2x1, 1 frame, 1 samples/pixel, big endian",
+			existingData: Dataset{Elements: []*Element{
+				mustNewElement(tag.Rows, []int{2}),
+				mustNewElement(tag.Columns, []int{1}),
+				mustNewElement(tag.NumberOfFrames, []string{"1"}),
+				mustNewElement(tag.BitsAllocated, []int{16}),
+				mustNewElement(tag.SamplesPerPixel, []int{1}),
+			}},
+			data: []uint16{0x0102, 0xFFFE},
+			bo:   binary.BigEndian,
+			expectedPixelData: &PixelDataInfo{
+				IsEncapsulated: false,
+				Frames: []frame.Frame{
+					{
+						Encapsulated: false,
+						NativeData: frame.NativeFrame{
+							BitsPerSample: 16,
+							Rows:          2,
+							Cols:          1,
+							Data:          [][]int{{0x0102}, {0xFFFE}},
+						},
+					},
+				},
+			},
+			expectedError: nil,
+		},
 		{
 			Name: "5x5, 1 frame, 1 samples/pixel",
 			existingData: Dataset{Elements: []*Element{
@@ -319,6 +502,157 @@ func TestReadNativeFrames(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			Name: "2x2, 1 frame, planar RGB",
+			existingData: Dataset{Elements: []*Element{
+				mustNewElement(tag.SamplesPerPixel, []int{3}),
+				mustNewElement(tag.PhotometricInterpretation, []string{"RGB"}),
+				mustNewElement(tag.PlanarConfiguration, []int{1}),
+				mustNewElement(tag.Rows, []int{2}),
+				mustNewElement(tag.Columns, []int{2}),
+				mustNewElement(tag.BitsAllocated, []int{16}),
+			}},
+			// The red, green and blue planes.
+			data: []uint16{1, 2, 3, 4, 10, 20, 30, 40, 100, 200, 300, 400},
+			expectedPixelData: &PixelDataInfo{
+				IsEncapsulated: false,
+				Frames: []frame.Frame{
+					{
+						Encapsulated: false,
+						NativeData: frame.NativeFrame{
+							BitsPerSample:             16,
+							Rows:                      2,
+							Cols:                      2,
+							Data:                      [][]int{{1, 10, 100}, {2, 20, 200}, {3, 30, 300}, {4, 40, 400}},
+							PhotometricInterpretation: "RGB",
+							PlanarConfiguration:       1,
+						},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			Name: "2x2, 1 frame, YBR_FULL_422",
+			existingData: Dataset{Elements: []*Element{
+				mustNewElement(tag.SamplesPerPixel, []int{3}),
+				mustNewElement(tag.PhotometricInterpretation, []string{"YBR_FULL_422"}),
+				mustNewElement(tag.Rows, []int{2}),
+				mustNewElement(tag.Columns, []int{2}),
+				mustNewElement(tag.BitsAllocated, []int{16}),
+			}},
+			// Each pair of pixels is encoded as Y1 Y2 Cb Cr.
+			data: []uint16{1, 2, 100, 200, 3, 4, 300, 400},
+			expectedPixelData: &PixelDataInfo{
+				IsEncapsulated: false,
+				Frames: []frame.Frame{
+					{
+						Encapsulated: false,
+						NativeData: frame.NativeFrame{
+							BitsPerSample:             16,
+							Rows:                      2,
+							Cols:                      2,
+							Data:                      [][]int{{1, 100, 200}, {2, 100, 200}, {3, 300, 400}, {4, 300, 400}},
+							PhotometricInterpretation: "YBR_FULL_422",
+						},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			Name: "2x1, 1 frame, PALETTE COLOR",
+			existingData: Dataset{Elements: []*Element{
+				mustNewElement(tag.SamplesPerPixel, []int{1}),
+				mustNewElement(tag.PhotometricInterpretation, []string{"PALETTE COLOR"}),
+				mustNewElement(tag.Rows, []int{2}),
+				mustNewElement(tag.Columns, []int{1}),
+				mustNewElement(tag.BitsAllocated, []int{16}),
+				mustNewElement(tag.RedPaletteColorLookupTableDescriptor, []int{2, 1, 16}),
+				mustNewElement(tag.GreenPaletteColorLookupTableDescriptor, []int{2, 1, 16}),
+				mustNewElement(tag.BluePaletteColorLookupTableDescriptor, []int{2, 1, 16}),
+				mustNewElement(tag.RedPaletteColorLookupTableData, []byte{0x00, 0x00, 0xFF, 0xFF}),
+				mustNewElement(tag.GreenPaletteColorLookupTableData, []byte{0x00, 0x10, 0x00, 0x20}),
+				// A discrete segment with 1 entry, followed by a linear
+				// segment with 1 entry.
+				mustNewElement(tag.SegmentedBluePaletteColorLookupTableData, []byte{0, 0, 1, 0, 0x34, 0x12, 1, 0, 1, 0, 0x78, 0x56}),
+			}},
+			data: []uint16{1, 2},
+			expectedPixelData: &PixelDataInfo{
+				IsEncapsulated: false,
+				Frames: []frame.Frame{
+					{
+						Encapsulated: false,
+						NativeData: frame.NativeFrame{
+							BitsPerSample:             16,
+							Rows:                      2,
+							Cols:                      1,
+							Data:                      [][]int{{1}, {2}},
+							PhotometricInterpretation: "PALETTE COLOR",
+							Palette: &frame.PaletteLUT{
+								FirstValue:   1,
+								BitsPerEntry: 16,
+								Red:          []uint16{0x0000, 0xFFFF},
+								Green:        []uint16{0x1000, 0x2000},
+								Blue:         []uint16{0x1234, 0x5678},
+							},
+						},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			Name: "YBR_FULL_422 with an odd number of columns",
+			existingData: Dataset{Elements: []*Element{
+				mustNewElement(tag.SamplesPerPixel, []int{3}),
+				mustNewElement(tag.PhotometricInterpretation, []string{"YBR_FULL_422"}),
+				mustNewElement(tag.Rows, []int{2}),
+				mustNewElement(tag.Columns, []int{1}),
+				mustNewElement(tag.BitsAllocated, []int{16}),
+			}},
+			data:              []uint16{1, 2, 100, 200},
+			expectedPixelData: nil,
+			expectedError:     errorYBRFull422OddColumns,
+		},
+		{
+			Name: "2x1, 1 frame, signed samples are held unsigned",
+			existingData: Dataset{Elements: []*Element{
+				mustNewElement(tag.SamplesPerPixel, []int{1}),
+				mustNewElement(tag.Rows, []int{2}),
+				mustNewElement(tag.Columns, []int{1}),
+				mustNewElement(tag.BitsAllocated, []int{16}),
+				mustNewElement(tag.PixelRepresentation, []int{1}),
+			}},
+			data: []uint16{0xFC00, 0x0400},
+			expectedPixelData: &PixelDataInfo{
+				IsEncapsulated: false,
+				Frames: []frame.Frame{
+					{
+						Encapsulated: false,
+						NativeData: frame.NativeFrame{
+							BitsPerSample: 16,
+							Rows:          2,
+							Cols:          1,
+							Data:          [][]int{{0xFC00}, {1024}},
+						},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			Name: "unsupported BitsAllocated",
+			existingData: Dataset{Elements: []*Element{
+				mustNewElement(tag.SamplesPerPixel, []int{1}),
+				mustNewElement(tag.Rows, []int{2}),
+				mustNewElement(tag.Columns, []int{2}),
+				mustNewElement(tag.BitsAllocated, []int{12}),
+			}},
+			data:              []uint16{1, 2, 3, 4},
+			expectedPixelData: nil,
+			expectedError:     frame.ErrorUnsupportedBitsAllocated,
+		},
 		{
 			Name: "insufficient bytes, uint32",
 			existingData: Dataset{Elements: []*Element{
@@ -348,30 +682,69 @@ func TestReadNativeFrames(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.Name, func(t *testing.T) {
+			bo := tc.bo
+			if bo == nil {
+				bo = binary.LittleEndian
+			}
 			dcmdata := bytes.Buffer{}
 			for _, item := range tc.data {
-				if err := binary.Write(&dcmdata, binary.LittleEndian, item); err != nil {
+				if err := binary.Write(&dcmdata, bo, item); err != nil {
 					t.Errorf("TestReadNativeFrames: Unable to setup test buffer")
 				}
 			}
 
-			r, err := dicomio.NewReader(bufio.NewReader(&dcmdata), binary.LittleEndian, int64(dcmdata.Len()))
+			r, err := dicomio.NewReader(bufio.NewReader(&dcmdata), bo, int64(dcmdata.Len()))
 			if err != nil {
 				t.Errorf("TestReadFloat: unable to create new dicomio.Reader")
 			}
 
-			pixelData, _, err := readNativeFrames(r, &tc.existingData, DefaultOptions())
+			pixelData, _, err := readNativeFrames(r, &tc.existingData, &parseState{Options: *DefaultOptions()})
 			if !errors.Is(err, tc.expectedError) {
 				t.Errorf("TestReadNativeFrames(%v): did not get expected error. got: %v, want: %v", tc.data, err, tc.expectedError)
 			}
 
-			if diff := cmp.Diff(tc.expectedPixelData, pixelData); diff != "" {
+			if diff := cmp.Diff(tc.expectedPixelData, pixelData, cmp.AllowUnexported(allValues...)); diff != "" {
 				t.Errorf("TestReadNativeFrames(%v): unexpected diff: %v", tc.data, diff)
 			}
 		})
 	}
 }
 
+func TestReadNativeFrames_compact(t *testing.T) {
+	existingData := Dataset{Elements: []*Element{
+		mustNewElement(tag.Rows, []int{2}),
+		mustNewElement(tag.Columns, []int{1}),
+		mustNewElement(tag.NumberOfFrames, []string{"1"}),
+		mustNewElement(tag.BitsAllocated, []int{16}),
+		mustNewElement(tag.SamplesPerPixel, []int{1}),
+	}}
+	data := []byte{0x02, 0x01, 0xFE, 0xFF}
+	r, err := dicomio.NewReader(bufio.NewReader(bytes.NewReader(data)), binary.LittleEndian, int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to create new dicomio.Reader: %v", err)
+	}
+	opts := &parseState{Options: *NewOptions(CompactNativeFrames())}
+
+	pixelData, _, err := readNativeFrames(r, &existingData, opts)
+	if err != nil {
+		t.Fatalf("readNativeFrames unexpected error: %v", err)
+	}
+	want := &PixelDataInfo{
+		Frames: []frame.Frame{{
+			NativeData: frame.NativeFrame{
+				BitsPerSample:   16,
+				Rows:            2,
+				Cols:            1,
+				Samples:         frame.Uint16Buffer{0x0102, 0xFFFE},
+				SamplesPerPixel: 1,
+			},
+		}},
+	}
+	if diff := cmp.Diff(want, pixelData, cmp.AllowUnexported(allValues...)); diff != "" {
+		t.Errorf("readNativeFrames with CompactNativeFrames unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
 func BenchmarkReadNativeFrames(b *testing.B) {
 	cases := []struct {
 		Name            string
@@ -414,7 +787,7 @@ func BenchmarkReadNativeFrames(b *testing.B) {
 			dataset, r := buildReadNativeFramesInput(c.Rows, c.Cols, c.NumFrames, c.SamplesPerPixel, b)
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				_, _, _ = readNativeFrames(r, dataset, DefaultOptions())
+				_, _, _ = readNativeFrames(r, dataset, &parseState{Options: *DefaultOptions()})
 			}
 		})
 	}
@@ -463,3 +836,193 @@ func buildTagData(t *testing.T, tg tag.Tag) []byte {
 
 	return data.Bytes()
 }
+
+func TestReadPixelData_encapsulated(t *testing.T) {
+	eot := make([]byte, 16)
+	binary.LittleEndian.PutUint64(eot[8:], 12)
+
+	cases := []struct {
+		name         string
+		existingData Dataset
+		offsets      []uint32
+		fragments    [][]byte
+		want         PixelDataInfo
+		wantWarnings []WarningCategory
+	}{
+		{
+			name:      "Basic Offset Table",
+			offsets:   []uint32{0, 24},
+			fragments: [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 10, 11, 12}},
+			want: PixelDataInfo{
+				IsEncapsulated: true,
+				Offsets:        []uint32{0, 24},
+				Frames: []frame.Frame{
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}}},
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{9, 10, 11, 12}}},
+				},
+			},
+		},
+		{
+			name: "Extended Offset Table",
+			existingData: Dataset{Elements: []*Element{
+				mustNewElement(tag.ExtendedOffsetTable, eot),
+			}},
+			fragments: [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}},
+			want: PixelDataInfo{
+				IsEncapsulated: true,
+				Frames: []frame.Frame{
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}}},
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{5, 6, 7, 8}}},
+				},
+			},
+		},
+		{
+			name:      "JPEG markers",
+			fragments: [][]byte{{0xFF, 0xD8, 1, 2}, {3, 0xFF, 0xD9, 0}, {0xFF, 0xD8, 4, 5}, {6, 7, 0xFF, 0xD9}},
+			want: PixelDataInfo{
+				IsEncapsulated: true,
+				Frames: []frame.Frame{
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{0xFF, 0xD8, 1, 2, 3, 0xFF, 0xD9, 0}}},
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{0xFF, 0xD8, 4, 5, 6, 7, 0xFF, 0xD9}}},
+				},
+			},
+		},
+		{
+			name:      "no offset table or JPEG markers",
+			fragments: [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}},
+			want: PixelDataInfo{
+				IsEncapsulated: true,
+				Frames: []frame.Frame{
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}}},
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{5, 6, 7, 8}}},
+				},
+			},
+		},
+		{
+			name: "single frame",
+			existingData: Dataset{Elements: []*Element{
+				mustNewElement(tag.NumberOfFrames, []string{"1"}),
+			}},
+			fragments: [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}},
+			want: PixelDataInfo{
+				IsEncapsulated: true,
+				Frames: []frame.Frame{
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}}},
+				},
+			},
+		},
+		{
+			name:      "invalid Basic Offset Table",
+			offsets:   []uint32{0, 4},
+			fragments: [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}},
+			want: PixelDataInfo{
+				IsEncapsulated: true,
+				Offsets:        []uint32{0, 4},
+				Frames: []frame.Frame{
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}}},
+					{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{5, 6, 7, 8}}},
+				},
+			},
+			wantWarnings: []WarningCategory{WarningInvalidOffsetTable},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := bytes.Buffer{}
+			bot := make([]byte, 4*len(tc.offsets))
+			for i, o := range tc.offsets {
+				binary.LittleEndian.PutUint32(bot[i*4:], o)
+			}
+			writeTestItem(&data, tag.Item, bot)
+			for _, f := range tc.fragments {
+				writeTestItem(&data, tag.Item, f)
+			}
+			writeTestItem(&data, tag.SequenceDelimitationItem, nil)
+
+			r, err := dicomio.NewReader(bufio.NewReader(&data), binary.LittleEndian, int64(data.Len()))
+			if err != nil {
+				t.Fatalf("unable to create new dicomio.Reader: %v", err)
+			}
+			opts := &parseState{Options: *DefaultOptions()}
+			got, err := readPixelData(r, tag.PixelData, "OB", tag.VLUndefinedLength, &tc.existingData, opts)
+			if err != nil {
+				t.Fatalf("readPixelData unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, MustGetPixelDataInfo(got), cmp.AllowUnexported(allValues...)); diff != "" {
+				t.Errorf("readPixelData unexpected diff: %v", diff)
+			}
+			var gotWarnings []WarningCategory
+			for _, w := range opts.warnings {
+				gotWarnings = append(gotWarnings, w.Category)
+			}
+			if diff := cmp.Diff(tc.wantWarnings, gotWarnings); diff != "" {
+				t.Errorf("readPixelData unexpected warnings diff: %v", diff)
+			}
+		})
+	}
+}
+
+func TestReadPixelData_encapsulatedTruncated(t *testing.T) {
+	valid := bytes.Buffer{}
+	writeTestItem(&valid, tag.Item, nil)
+	writeTestItem(&valid, tag.Item, []byte{1, 2, 3, 4})
+	// A fragment whose value is cut short.
+	truncatedValue := bytes.Buffer{}
+	truncatedValue.Write(valid.Bytes())
+	writeTestItem(&truncatedValue, tag.Item, []byte{5, 6, 7, 8})
+	truncatedValue.Truncate(truncatedValue.Len() - 2)
+	// A fragment whose Item header is cut short.
+	truncatedHeader := bytes.Buffer{}
+	truncatedHeader.Write(valid.Bytes())
+	truncatedHeader.Write([]byte{0xFE, 0xFF, 0x00})
+
+	wantFrames := []frame.Frame{{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}}}}
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated fragment value", data: truncatedValue.Bytes()},
+		{name: "truncated fragment header", data: truncatedHeader.Bytes()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := dicomio.NewReader(bufio.NewReader(bytes.NewReader(tc.data)), binary.LittleEndian, int64(len(tc.data)))
+			if err != nil {
+				t.Fatalf("unable to create new dicomio.Reader: %v", err)
+			}
+			_, err = readPixelData(r, tag.PixelData, "OB", tag.VLUndefinedLength, &Dataset{}, &parseState{Options: *DefaultOptions()})
+			if !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+				t.Errorf("readPixelData unexpected error. got: %v, want: unexpected EOF", err)
+			}
+		})
+		t.Run(tc.name+" (Lenient)", func(t *testing.T) {
+			r, err := dicomio.NewReader(bufio.NewReader(bytes.NewReader(tc.data)), binary.LittleEndian, int64(len(tc.data)))
+			if err != nil {
+				t.Fatalf("unable to create new dicomio.Reader: %v", err)
+			}
+			opts := &parseState{Options: *NewOptions(Lenient())}
+			got, err := readPixelData(r, tag.PixelData, "OB", tag.VLUndefinedLength, &Dataset{}, opts)
+			if err != nil {
+				t.Fatalf("readPixelData unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(wantFrames, MustGetPixelDataInfo(got).Frames); diff != "" {
+				t.Errorf("readPixelData unexpected frames diff: %v", diff)
+			}
+			if len(opts.elementErrors) != 1 || opts.elementErrors[0].Tag != tag.PixelData {
+				t.Errorf("readPixelData unexpected ElementErrors. got: %v, want a single error for PixelData", opts.elementErrors)
+			}
+		})
+	}
+}
+
+// writeTestItem writes an implicit VR little endian Item (or delimitation item)
+// with the provided value to buf.
+func writeTestItem(buf *bytes.Buffer, tg tag.Tag, value []byte) {
+	header := make([]byte, 8)
+	binary.LittleEndian.PutUint16(header, tg.Group)
+	binary.LittleEndian.PutUint16(header[2:], tg.Element)
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(value)))
+	buf.Write(header)
+	buf.Write(value)
+}
@@ -0,0 +1,282 @@
+package dicom
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/suyashkumar/dicom/pkg/codec"
+	"github.com/suyashkumar/dicom/pkg/frame"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+// ErrorLossyTranscode indicates that transcoding a Dataset would lossily
+// compress its PixelData, which requires the AllowLossy TranscodeOption.
+var ErrorLossyTranscode = errors.New("transcoding would lossily compress PixelData")
+
+// lossyCompressionMethods maps lossy transfer syntaxes to their
+// LossyImageCompressionMethod (see PS3.3 C.7.6.1.1.5.1).
+var lossyCompressionMethods = map[string]string{
+	uid.JPEGBaseline8Bit:   "ISO_10918_1",
+	uid.JPEGExtended12Bit:  "ISO_10918_1",
+	uid.JPEGLSNearLossless: "ISO_14495_1",
+	uid.JPEG2000:           "ISO_15444_1",
+}
+
+// TranscodeOption represents an option that can be passed to Transcode.
+type TranscodeOption func(*transcodeOptSet)
+
+// AllowLossy returns a TranscodeOption that allows transcoding PixelData to a
+// lossy transfer syntax (e.g. JPEG Baseline). By default, Transcode returns
+// ErrorLossyTranscode instead.
+func AllowLossy() TranscodeOption {
+	return func(set *transcodeOptSet) {
+		set.allowLossy = true
+	}
+}
+
+// NearLossless returns a TranscodeOption that sets the maximum sample error
+// (NEAR) of frames compressed with the JPEG-LS Near-Lossless transfer syntax,
+// which is 2 by default. It has no effect without AllowLossy.
+func NearLossless(near int) TranscodeOption {
+	return func(set *transcodeOptSet) {
+		set.near = near
+	}
+}
+
+// transcodeOptSet represents the flattened option set after all
+// TranscodeOptions have been applied.
+type transcodeOptSet struct {
+	allowLossy bool
+	near       int
+}
+
+// Transcode returns ds converted to the transfer syntax transferSyntaxUID, which
+// can be written with Write.
+//
+// The PixelData is converted using the codec.Codecs registered for the transfer
+// syntaxes: encapsulated frames are decoded to native frames, and native frames
+// are encoded when transferSyntaxUID is an encapsulated transfer syntax. The
+// pixel module attributes (e.g. PhotometricInterpretation) are updated to
+// describe the converted frames, and the lossy compression attributes are
+// updated if frames are lossily compressed. Other elements are converted to the
+// byte order and VR encoding of transferSyntaxUID when the Dataset is written.
+//
+// The file meta information is updated with the new TransferSyntaxUID, and
+// MediaStorageSOPClassUID and MediaStorageSOPInstanceUID are set from
+// SOPClassUID and SOPInstanceUID. The returned Dataset shares unchanged
+// Elements with ds, which is not modified.
+func Transcode(ds Dataset, transferSyntaxUID string, opts ...TranscodeOption) (Dataset, error) {
+	optSet := &transcodeOptSet{near: 2}
+	for _, opt := range opts {
+		opt(optSet)
+	}
+	if _, _, err := parseTransferSyntaxUID(transferSyntaxUID); err != nil {
+		return Dataset{}, err
+	}
+	from := ds.transferSyntaxUID()
+	if from == "" {
+		from = uid.ImplicitVRLittleEndian
+	}
+
+	out := Dataset{Elements: make([]*Element, 0, len(ds.Elements))}
+	for _, elem := range ds.Elements {
+		// The group length is computed when the Dataset is written.
+		if elem.Tag != tag.FileMetaInformationGroupLength {
+			out.Elements = append(out.Elements, elem)
+		}
+	}
+	if elem, err := out.FindElementByTag(tag.PixelData); err == nil && elem.Value != nil && elem.Value.ValueType() == PixelData && from != transferSyntaxUID {
+		if err := out.transcodePixelData(elem, from, transferSyntaxUID, *optSet); err != nil {
+			return Dataset{}, err
+		}
+	}
+
+	out.setElement(mustNewElement(tag.TransferSyntaxUID, []string{transferSyntaxUID}))
+	if _, err := out.FindElementByTag(tag.FileMetaInformationVersion); err != nil {
+		out.setElement(mustNewElement(tag.FileMetaInformationVersion, []byte{0x00, 0x01}))
+	}
+	for _, t := range []struct{ from, to tag.Tag }{
+		{tag.SOPClassUID, tag.MediaStorageSOPClassUID},
+		{tag.SOPInstanceUID, tag.MediaStorageSOPInstanceUID},
+	} {
+		if elem, err := out.FindElementByTag(t.from); err == nil {
+			if s, err := firstString(elem.Value); err == nil {
+				out.setElement(mustNewElement(t.to, []string{s}))
+			}
+		}
+	}
+	return out, nil
+}
+
+// transcodePixelData replaces the PixelData element elem of d, which holds
+// frames with the transfer syntax from, with one holding the frames converted
+// to the transfer syntax to, and updates the pixel module attributes of d to
+// match.
+func (d *Dataset) transcodePixelData(elem *Element, from, to string, opts transcodeOptSet) error {
+	info := MustGetPixelDataInfo(elem.Value)
+	toEncapsulated := !isNativeTransferSyntax(to)
+	if !info.IsEncapsulated && !toEncapsulated {
+		// Native PixelData is converted to the byte order of the transfer
+		// syntax when written.
+		return nil
+	}
+	frames, err := info.allFrames()
+	if err != nil {
+		return err
+	}
+
+	natives := make([]*frame.NativeFrame, len(frames))
+	for i := range frames {
+		if !info.IsEncapsulated {
+			natives[i] = &frames[i].NativeData
+			continue
+		}
+		e := frames[i].EncapsulatedData
+		if e.TransferSyntaxUID == "" {
+			// Frames built by hand may not describe themselves.
+			data := e.Data
+			e = encapsulatedFrameInfo(d, from)
+			e.Data = data
+		}
+		if natives[i], err = e.GetNativeFrame(); err != nil {
+			if errors.Is(err, frame.ErrorFrameTypeNotPresent) {
+				err = fmt.Errorf("%w: %s", codec.ErrorNotRegistered, from)
+			}
+			return fmt.Errorf("unable to decode frame %d: %w", i, err)
+		}
+	}
+	if method, ok := lossyCompressionMethods[from]; ok && info.IsEncapsulated {
+		// The decoded frames hold the losses of the original compression.
+		d.markLossy(method, 0)
+	}
+
+	converted := PixelDataInfo{IsEncapsulated: toEncapsulated}
+	method, lossy := lossyCompressionMethods[to]
+	if toEncapsulated && lossy && !opts.allowLossy {
+		return fmt.Errorf("%w: %s", ErrorLossyTranscode, to)
+	}
+	// Native frames do not record the signedness and significant bits of
+	// their samples, which are taken from d.
+	layout := encapsulatedFrameInfo(d, to)
+	var c codec.Codec
+	if toEncapsulated {
+		var ok bool
+		if c, ok = codec.Lookup(to); !ok {
+			return fmt.Errorf("%w: %s", codec.ErrorNotRegistered, to)
+		}
+		if to == uid.JPEGLSNearLossless {
+			// The registered Codec only decodes frames, so that they are
+			// not lossily compressed without AllowLossy.
+			c = codec.NewJPEGLS(opts.near)
+		}
+	}
+	var nativeSize, encodedSize int
+	for i, n := range natives {
+		if !toEncapsulated {
+			converted.Frames = append(converted.Frames, frame.Frame{NativeData: *n})
+			continue
+		}
+		e, err := frame.EncodeWith(n, c, to, layout.PixelRepresentation, layout.BitsStored)
+		if err != nil {
+			return fmt.Errorf("unable to encode frame %d: %w", i, err)
+		}
+		if e.PhotometricInterpretation == "YBR_FULL_422" {
+			// Codecs are given the full chrominance of each pixel.
+			e.PhotometricInterpretation = "YBR_FULL"
+		}
+		nativeSize += n.Rows * n.Cols * n.NumSamplesPerPixel() * n.BitsPerSample / 8
+		encodedSize += len(e.Data)
+		converted.Frames = append(converted.Frames, frame.Frame{Encapsulated: true, EncapsulatedData: *e})
+	}
+	if toEncapsulated && lossy && encodedSize > 0 {
+		d.markLossy(method, float64(nativeSize)/float64(encodedSize))
+	}
+
+	vl := uint32(0)
+	if toEncapsulated {
+		vl = tag.VLUndefinedLength
+	}
+	d.setElement(&Element{
+		Tag:                    elem.Tag,
+		ValueRepresentation:    elem.ValueRepresentation,
+		RawValueRepresentation: elem.RawValueRepresentation,
+		ValueLength:            vl,
+		Value:                  &pixelDataValue{PixelDataInfo: converted},
+	})
+
+	if len(converted.Frames) == 0 {
+		return nil
+	}
+	// All frames are assumed to share the layout of the first one.
+	f := converted.Frames[0]
+	samplesPerPixel, bitsAllocated, pi := f.NativeData.NumSamplesPerPixel(), f.NativeData.BitsPerSample, f.NativeData.PhotometricInterpretation
+	if f.Encapsulated {
+		samplesPerPixel, bitsAllocated, pi = f.EncapsulatedData.SamplesPerPixel, f.EncapsulatedData.BitsAllocated, f.EncapsulatedData.PhotometricInterpretation
+	}
+	d.setElement(mustNewElement(tag.SamplesPerPixel, []int{samplesPerPixel}))
+	d.setElement(mustNewElement(tag.BitsAllocated, []int{bitsAllocated}))
+	if pi != "" {
+		d.setElement(mustNewElement(tag.PhotometricInterpretation, []string{pi}))
+	}
+	if samplesPerPixel > 1 {
+		// Native frames hold the samples of each pixel together, as do the
+		// frames given to codecs.
+		d.setElement(mustNewElement(tag.PlanarConfiguration, []int{0}))
+	}
+	return nil
+}
+
+// markLossy records in d that its PixelData was lossily compressed using
+// method, with the compression ratio ratio (or an unknown ratio, if 0). The
+// method and ratio are added to those of any previous lossy compression.
+func (d *Dataset) markLossy(method string, ratio float64) {
+	if ratio == 0 {
+		if elem, err := d.FindElementByTag(tag.LossyImageCompression); err == nil {
+			if s, err := firstString(elem.Value); err == nil && s == "01" {
+				// The previous compression is already recorded.
+				return
+			}
+		}
+	}
+	d.setElement(mustNewElement(tag.LossyImageCompression, []string{"01"}))
+	appendString := func(t tag.Tag, s string) {
+		var values []string
+		if elem, err := d.FindElementByTag(t); err == nil {
+			values, _ = elem.Value.GetValue().([]string)
+		}
+		d.setElement(mustNewElement(t, append(append([]string(nil), values...), s)))
+	}
+	appendString(tag.LossyImageCompressionMethod, method)
+	if ratio != 0 {
+		appendString(tag.LossyImageCompressionRatio, strconv.FormatFloat(ratio, 'f', 2, 64))
+	}
+}
+
+// setElement replaces the element of d with the tag of elem with elem, or
+// inserts elem in tag order if d has no such element.
+func (d *Dataset) setElement(elem *Element) {
+	for i, e := range d.Elements {
+		switch e.Tag.Compare(elem.Tag) {
+		case 0:
+			d.Elements[i] = elem
+			return
+		case 1:
+			d.Elements = append(d.Elements[:i], append([]*Element{elem}, d.Elements[i:]...)...)
+			return
+		}
+	}
+	d.Elements = append(d.Elements, elem)
+}
+
+// isNativeTransferSyntax reports if PixelData is native (i.e. not
+// encapsulated) in the transfer syntax transferSyntaxUID.
+func isNativeTransferSyntax(transferSyntaxUID string) bool {
+	for _, ts := range uid.StandardTransferSyntaxes {
+		if ts == transferSyntaxUID {
+			return true
+		}
+	}
+	return false
+}
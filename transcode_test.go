@@ -0,0 +1,251 @@
+package dicom
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/suyashkumar/dicom/pkg/codec"
+	"github.com/suyashkumar/dicom/pkg/frame"
+	"github.com/suyashkumar/dicom/pkg/tag"
+	"github.com/suyashkumar/dicom/pkg/uid"
+)
+
+// transcodeDataset returns a Dataset with the transfer syntax
+// transferSyntaxUID, holding two native 2x3 frames of signed 16 bit samples.
+func transcodeDataset(transferSyntaxUID string) (Dataset, []frame.Frame) {
+	frames := []frame.Frame{
+		{NativeData: frame.NativeFrame{
+			BitsPerSample: 16, Rows: 2, Cols: 3, SamplesPerPixel: 1, PhotometricInterpretation: "MONOCHROME2",
+			Samples: frame.Int16Buffer{-1, -1, -1, 0, 1, 2},
+		}},
+		{NativeData: frame.NativeFrame{
+			BitsPerSample: 16, Rows: 2, Cols: 3, SamplesPerPixel: 1, PhotometricInterpretation: "MONOCHROME2",
+			Samples: frame.Int16Buffer{-1000, 1000, 7, 7, 7, 7},
+		}},
+	}
+	return Dataset{Elements: []*Element{
+		mustNewElement(tag.TransferSyntaxUID, []string{transferSyntaxUID}),
+		mustNewElement(tag.SOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.7"}),
+		mustNewElement(tag.SOPInstanceUID, []string{"1.2.3.4.5"}),
+		mustNewElement(tag.PatientName, []string{"Bob"}),
+		mustNewElement(tag.SamplesPerPixel, []int{1}),
+		mustNewElement(tag.PhotometricInterpretation, []string{"MONOCHROME2"}),
+		mustNewElement(tag.NumberOfFrames, []string{"2"}),
+		mustNewElement(tag.Rows, []int{2}),
+		mustNewElement(tag.Columns, []int{3}),
+		mustNewElement(tag.BitsAllocated, []int{16}),
+		mustNewElement(tag.PixelRepresentation, []int{1}),
+		mustNewElement(tag.RedPaletteColorLookupTableData, []byte{1, 2, 3, 4}),
+		mustNewElement(tag.PixelData, PixelDataInfo{Frames: frames}),
+	}}, frames
+}
+
+// writeAndParse writes ds and parses it back.
+func writeAndParse(t *testing.T, ds Dataset) Dataset {
+	t.Helper()
+	buf := bytes.Buffer{}
+	if err := Write(&buf, ds); err != nil {
+		t.Fatalf("Write unexpected error: %v", err)
+	}
+	got, err := Parse(&buf, Limit(int64(buf.Len())))
+	if err != nil {
+		t.Fatalf("Parse unexpected error: %v", err)
+	}
+	return got
+}
+
+// stringValue returns the first string value of the element of ds with tag t,
+// or "" if there is none.
+func stringValue(ds Dataset, t tag.Tag) string {
+	elem, err := ds.FindElementByTag(t)
+	if err != nil {
+		return ""
+	}
+	s, _ := firstString(elem.Value)
+	return s
+}
+
+func TestTranscode(t *testing.T) {
+	cases := []struct {
+		from, to string
+	}{
+		{from: uid.ImplicitVRLittleEndian, to: uid.ExplicitVRLittleEndian},
+		{from: uid.ExplicitVRBigEndian, to: uid.ExplicitVRLittleEndian},
+		{from: uid.ExplicitVRLittleEndian, to: uid.ExplicitVRBigEndian},
+		{from: uid.ExplicitVRLittleEndian, to: uid.DeflatedExplicitVRLittleEndian},
+		{from: uid.ExplicitVRLittleEndian, to: uid.RLELossless},
+		{from: uid.RLELossless, to: uid.ImplicitVRLittleEndian},
+		{from: uid.ExplicitVRLittleEndian, to: uid.JPEGLSLossless},
+		{from: uid.JPEGLSLossless, to: uid.RLELossless},
+	}
+	for _, tc := range cases {
+		t.Run(tc.from+" to "+tc.to, func(t *testing.T) {
+			ds, frames := transcodeDataset(tc.from)
+			// Start from a parsed Dataset, so encapsulated frames are read
+			// as such.
+			parsed := writeAndParse(t, ds)
+
+			transcoded, err := Transcode(parsed, tc.to)
+			if err != nil {
+				t.Fatalf("Transcode unexpected error: %v", err)
+			}
+			if got := stringValue(parsed, tag.TransferSyntaxUID); got != tc.from {
+				t.Errorf("Transcode modified its input TransferSyntaxUID. got: %q, want: %q", got, tc.from)
+			}
+			got := writeAndParse(t, transcoded)
+
+			for _, want := range []struct {
+				t     tag.Tag
+				value string
+			}{
+				{tag.TransferSyntaxUID, tc.to},
+				{tag.MediaStorageSOPClassUID, "1.2.840.10008.5.1.4.1.1.7"},
+				{tag.MediaStorageSOPInstanceUID, "1.2.3.4.5"},
+				{tag.PatientName, "Bob"},
+				{tag.LossyImageCompression, ""},
+			} {
+				if got := stringValue(got, want.t); got != want.value {
+					t.Errorf("unexpected %v after Transcode. got: %q, want: %q", want.t, got, want.value)
+				}
+			}
+			lut, err := got.FindElementByTag(tag.RedPaletteColorLookupTableData)
+			if err != nil {
+				t.Fatalf("unable to find RedPaletteColorLookupTableData: %v", err)
+			}
+			if diff := cmp.Diff([]byte{1, 2, 3, 4}, lut.Value.GetValue()); diff != "" {
+				t.Errorf("unexpected RedPaletteColorLookupTableData after Transcode (-want +got):\n%s", diff)
+			}
+
+			pixelData, err := got.FindElementByTag(tag.PixelData)
+			if err != nil {
+				t.Fatalf("unable to find PixelData: %v", err)
+			}
+			info := MustGetPixelDataInfo(pixelData.Value)
+			if info.IsEncapsulated == isNativeTransferSyntax(tc.to) || len(info.Frames) != len(frames) {
+				t.Fatalf("unexpected PixelData after Transcode: got %d frames, encapsulated: %v", len(info.Frames), info.IsEncapsulated)
+			}
+			for i := range frames {
+				native, err := info.Frames[i].GetNativeFrame()
+				if err != nil {
+					t.Fatalf("GetNativeFrame(%d) unexpected error: %v", i, err)
+				}
+				// Parsed frames hold their samples in Data, and frames decoded
+				// by a codec in Samples, so only the samples are compared.
+				if diff := cmp.Diff(&frames[i].NativeData, native, cmpopts.IgnoreFields(frame.NativeFrame{}, "Data", "Samples", "SamplesPerPixel")); diff != "" {
+					t.Errorf("GetNativeFrame(%d) unexpected diff (-want +got):\n%s", i, diff)
+				}
+				if diff := cmp.Diff(frames[i].NativeData.Ints(), native.Ints(), cmp.Transformer("int16", func(v int) int16 { return int16(v) })); diff != "" {
+					t.Errorf("GetNativeFrame(%d) unexpected samples (-want +got):\n%s", i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestTranscode_lossyJPEG(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	buf := bytes.Buffer{}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("jpeg.Encode unexpected error: %v", err)
+	}
+	jpegData := buf.Bytes()
+	if len(jpegData)%2 != 0 {
+		jpegData = append(jpegData, 0)
+	}
+	ds := Dataset{Elements: []*Element{
+		mustNewElement(tag.TransferSyntaxUID, []string{uid.JPEGBaseline8Bit}),
+		mustNewElement(tag.SamplesPerPixel, []int{1}),
+		mustNewElement(tag.PhotometricInterpretation, []string{"MONOCHROME2"}),
+		mustNewElement(tag.Rows, []int{8}),
+		mustNewElement(tag.Columns, []int{8}),
+		mustNewElement(tag.BitsAllocated, []int{8}),
+		setUndefinedLength(mustNewElement(tag.PixelData, PixelDataInfo{
+			IsEncapsulated: true,
+			Frames:         []frame.Frame{{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: jpegData}}},
+		})),
+	}}
+
+	native, err := Transcode(ds, uid.ExplicitVRLittleEndian)
+	if err != nil {
+		t.Fatalf("Transcode unexpected error: %v", err)
+	}
+	got := writeAndParse(t, native)
+	if v := stringValue(got, tag.LossyImageCompression); v != "01" {
+		t.Errorf("unexpected LossyImageCompression after Transcode. got: %q, want: %q", v, "01")
+	}
+	if v := stringValue(got, tag.LossyImageCompressionMethod); v != "ISO_10918_1" {
+		t.Errorf("unexpected LossyImageCompressionMethod after Transcode. got: %q, want: %q", v, "ISO_10918_1")
+	}
+	pixelData, err := got.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	f, err := MustGetPixelDataInfo(pixelData.Value).Frame(0)
+	if err != nil {
+		t.Fatalf("Frame(0) unexpected error: %v", err)
+	}
+	want := frame.NativeFrame{Samples: frame.Uint8Buffer(img.Pix), SamplesPerPixel: 1}
+	if diff := cmp.Diff(want.Ints(), f.NativeData.Ints()); diff != "" {
+		t.Errorf("unexpected samples after Transcode (-want +got):\n%s", diff)
+	}
+
+	if _, err := Transcode(native, uid.JPEGBaseline8Bit); !errors.Is(err, ErrorLossyTranscode) {
+		t.Errorf("Transcode to %s unexpected error. got: %v, want: %v", uid.JPEGBaseline8Bit, err, ErrorLossyTranscode)
+	}
+	if _, err := Transcode(native, uid.JPEGBaseline8Bit, AllowLossy()); !errors.Is(err, codec.ErrorEncodeUnsupported) {
+		t.Errorf("Transcode to %s unexpected error. got: %v, want: %v", uid.JPEGBaseline8Bit, err, codec.ErrorEncodeUnsupported)
+	}
+}
+
+func TestTranscode_nearLossless(t *testing.T) {
+	ds, frames := transcodeDataset(uid.ExplicitVRLittleEndian)
+	if _, err := Transcode(ds, uid.JPEGLSNearLossless); !errors.Is(err, ErrorLossyTranscode) {
+		t.Errorf("Transcode unexpected error. got: %v, want: %v", err, ErrorLossyTranscode)
+	}
+	transcoded, err := Transcode(ds, uid.JPEGLSNearLossless, AllowLossy(), NearLossless(1))
+	if err != nil {
+		t.Fatalf("Transcode unexpected error: %v", err)
+	}
+	got := writeAndParse(t, transcoded)
+	if v := stringValue(got, tag.LossyImageCompression); v != "01" {
+		t.Errorf("unexpected LossyImageCompression after Transcode. got: %q, want: %q", v, "01")
+	}
+	if v := stringValue(got, tag.LossyImageCompressionMethod); v != "ISO_14495_1" {
+		t.Errorf("unexpected LossyImageCompressionMethod after Transcode. got: %q, want: %q", v, "ISO_14495_1")
+	}
+	pixelData, err := got.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	info := MustGetPixelDataInfo(pixelData.Value)
+	for i := range frames {
+		native, err := info.Frames[i].GetNativeFrame()
+		if err != nil {
+			t.Fatalf("GetNativeFrame(%d) unexpected error: %v", i, err)
+		}
+		for j := 0; j < native.NumPixels(); j++ {
+			v, want := int16(native.At(j, 0)), int16(frames[i].NativeData.At(j, 0))
+			if d := int(v) - int(want); d > 1 || d < -1 {
+				t.Errorf("frame %d sample %d is %d after Transcode, want within 1 of %d", i, j, v, want)
+			}
+		}
+	}
+}
+
+func TestTranscode_errors(t *testing.T) {
+	ds, _ := transcodeDataset(uid.ExplicitVRLittleEndian)
+	if _, err := Transcode(ds, "1.2.3.4"); err == nil {
+		t.Errorf("Transcode to an unknown transfer syntax unexpectedly succeeded")
+	}
+	if _, err := Transcode(ds, "1.2.840.10008.1.2.4.90"); !errors.Is(err, codec.ErrorNotRegistered) {
+		t.Errorf("Transcode unexpected error. got: %v, want: %v", err, codec.ErrorNotRegistered)
+	}
+}
@@ -1,8 +1,8 @@
 package dicom
 
 import (
-	"bufio"
 	"bytes"
+	"compress/flate"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -12,7 +12,9 @@ import (
 
 	"github.com/suyashkumar/dicom/pkg/uid"
 
+	"github.com/suyashkumar/dicom/pkg/codec"
 	"github.com/suyashkumar/dicom/pkg/dicomio"
+	"github.com/suyashkumar/dicom/pkg/frame"
 	"github.com/suyashkumar/dicom/pkg/tag"
 )
 
@@ -58,15 +60,49 @@ func Write(out io.Writer, ds Dataset, opts ...WriteOption) error {
 		w.SetTransferSyntax(endian, implicit)
 	}
 
+	// Everything after the metadata is compressed with raw DEFLATE when using
+	// the Deflated Explicit VR Little Endian transfer syntax (see PS3.5 A.5).
+	var fw *flate.Writer
+	if ds.transferSyntaxUID() == uid.DeflatedExplicitVRLittleEndian {
+		fw, err = flate.NewWriter(out, optSet.deflateLevel)
+		if err != nil {
+			return err
+		}
+		bo, implicit := w.GetTransferSyntax()
+		w = dicomio.NewWriter(fw, bo, implicit)
+	}
+
+	err = writeDataElements(w, &ds, *optSet)
+	if fw != nil {
+		// Close the deflate stream even if writing failed, releasing its
+		// resources.
+		if closeErr := fw.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// writeDataElements writes the elements of ds that are not file meta elements.
+func writeDataElements(w dicomio.Writer, ds *Dataset, opts writeOptSet) error {
+	// Native PixelData is encoded with the codec registered for the transfer
+	// syntax, if any, as encapsulated transfer syntaxes require encapsulated
+	// PixelData.
+	tsUID := ds.transferSyntaxUID()
+	_, encode := codec.Lookup(tsUID)
 	for _, elem := range ds.Elements {
 		if elem.Tag.Group != tag.MetadataGroup {
-			err = writeElement(w, elem, *optSet)
-			if err != nil {
+			if encode && elem.Tag == tag.PixelData {
+				var err error
+				if elem, err = encodePixelData(ds, elem, tsUID); err != nil {
+					return err
+				}
+			}
+			if err := writeElement(w, elem, opts); err != nil {
 				return err
 			}
 		}
 	}
-
 	return nil
 }
 
@@ -99,15 +135,26 @@ func DefaultMissingTransferSyntax() WriteOption {
 	}
 }
 
+// DeflateLevel returns a WriteOption that sets the compression level used when
+// writing a Dataset with the Deflated Explicit VR Little Endian transfer
+// syntax. The level is one of the compress/flate levels, and defaults to
+// flate.DefaultCompression.
+func DeflateLevel(level int) WriteOption {
+	return func(set *writeOptSet) {
+		set.deflateLevel = level
+	}
+}
+
 // writeOptSet represents the flattened option set after all WriteOptions have been applied.
 type writeOptSet struct {
 	skipVRVerification           bool
 	skipValueTypeVerification    bool
 	defaultMissingTransferSyntax bool
+	deflateLevel                 int
 }
 
 func toOptSet(opts ...WriteOption) *writeOptSet {
-	optSet := &writeOptSet{}
+	optSet := &writeOptSet{deflateLevel: flate.DefaultCompression}
 	for _, opt := range opts {
 		opt(optSet)
 	}
@@ -278,6 +325,10 @@ func verifyVROrDefault(t tag.Tag, vr string, opts writeOptSet) (string, error) {
 func verifyValueType(t tag.Tag, value Value, vr string) error {
 	valueType := value.ValueType()
 	var ok bool
+	if valueType == BulkData {
+		// BulkDataReferences can refer to the value of any bulk data VR.
+		return nil
+	}
 	switch vr {
 	case vrraw.UnsignedShort, vrraw.UnsignedLong, vrraw.SignedLong, vrraw.SignedShort, vrraw.AttributeTag:
 		ok = valueType == Ints
@@ -285,14 +336,18 @@ func verifyValueType(t tag.Tag, value Value, vr string) error {
 		ok = valueType == Sequences
 	case "NA":
 		ok = valueType == SequenceItem
-	case vrraw.OtherWord, vrraw.OtherByte:
+	case vrraw.OtherWord, vrraw.OtherByte, vrraw.OtherLong, vrraw.OtherVeryLong:
 		if t == tag.PixelData {
 			ok = valueType == PixelData
 		} else {
 			ok = valueType == Bytes
 		}
-	case vrraw.FloatingPointSingle, vrraw.FloatingPointDouble:
+	case vrraw.FloatingPointSingle, vrraw.FloatingPointDouble, vrraw.OtherFloat, vrraw.OtherDouble:
 		ok = valueType == Floats
+	case vrraw.SignedVeryLong:
+		ok = valueType == Int64s
+	case vrraw.UnsignedVeryLong:
+		ok = valueType == Uint64s
 	default:
 		ok = valueType == Strings
 	}
@@ -342,9 +397,9 @@ func writeVRVL(w dicomio.Writer, t tag.Tag, vr string, vl uint32) error {
 		}
 		switch vr {
 		case "NA", vrraw.OtherByte, vrraw.OtherDouble, vrraw.OtherFloat,
-			vrraw.OtherLong, vrraw.OtherWord, vrraw.Sequence, vrraw.Unknown,
+			vrraw.OtherLong, vrraw.OtherVeryLong, vrraw.OtherWord, vrraw.Sequence, vrraw.Unknown,
 			vrraw.UnlimitedCharacters, vrraw.UniversalResourceIdentifier,
-			vrraw.UnlimitedText:
+			vrraw.UnlimitedText, vrraw.SignedVeryLong, vrraw.UnsignedVeryLong:
 			if err := w.WriteZeros(2); err != nil {
 				return err
 			}
@@ -423,6 +478,10 @@ func writeValue(w dicomio.Writer, t tag.Tag, value Value, valueType ValueType, v
 		return writeSequence(w, t, v.([]*SequenceItemValue), vr, vl, opts)
 	case Floats:
 		return writeFloats(w, value, vr)
+	case Int64s, Uint64s:
+		return writeVeryLongs(w, value, vr)
+	case BulkData:
+		return writeBulkData(w, MustGetBulkDataReference(value))
 	default:
 		return fmt.Errorf("ValueType not supported")
 	}
@@ -460,8 +519,8 @@ func writeStrings(w dicomio.Writer, values []string, vr string) error {
 func writeBytes(w dicomio.Writer, values []byte, vr string) error {
 	var err error
 	switch vr {
-	case vrraw.OtherWord:
-		err = writeOtherWordString(w, values)
+	case vrraw.OtherWord, vrraw.OtherLong, vrraw.OtherVeryLong:
+		err = writeOtherWordString(w, values, vr)
 	case vrraw.OtherByte:
 		err = writeOtherByteString(w, values)
 	default:
@@ -499,7 +558,7 @@ func writeFloats(w dicomio.Writer, v Value, vr string) error {
 	floats := MustGetFloats(v)
 	for _, fl := range floats {
 		switch vr {
-		case vrraw.FloatingPointSingle:
+		case vrraw.FloatingPointSingle, vrraw.OtherFloat:
 			// NOTE: this is a conversion from float64 -> float32 which may lead to a loss in precision. The assumption
 			// is that the value sitting in the float64 was originally at float32 precision if the VR is FL for this
 			// element. We will need to revisit this. Maybe we can detect if there will be a loss of precision and if so
@@ -508,7 +567,7 @@ func writeFloats(w dicomio.Writer, v Value, vr string) error {
 			if err != nil {
 				return err
 			}
-		case vrraw.FloatingPointDouble:
+		case vrraw.FloatingPointDouble, vrraw.OtherDouble:
 			err := w.WriteFloat64(fl)
 			if err != nil {
 				return err
@@ -518,13 +577,43 @@ func writeFloats(w dicomio.Writer, v Value, vr string) error {
 	return nil
 }
 
+func writeVeryLongs(w dicomio.Writer, v Value, vr string) error {
+	switch {
+	case v.ValueType() == Int64s && vr == vrraw.SignedVeryLong:
+		for _, value := range MustGetInt64s(v) {
+			if err := w.WriteInt64(value); err != nil {
+				return err
+			}
+		}
+	case v.ValueType() == Uint64s && vr == vrraw.UnsignedVeryLong:
+		for _, value := range MustGetUint64s(v) {
+			if err := w.WriteUInt64(value); err != nil {
+				return err
+			}
+		}
+	default:
+		return ErrorMismatchValueTypeAndVR
+	}
+	return nil
+}
+
 func writePixelData(w dicomio.Writer, t tag.Tag, value Value, vr string, vl uint32) error {
 	image := MustGetPixelDataInfo(value)
+	frames, err := image.allFrames()
+	if err != nil {
+		return err
+	}
 	if vl == tag.VLUndefinedLength {
-		if err := writeBasicOffsetTable(w, image.Offsets); err != nil {
+		offsets := image.Offsets
+		if len(offsets) > 0 {
+			// Each frame is written as a single fragment, which may not match
+			// the layout the offsets were originally read from.
+			offsets = basicOffsetTable(frames)
+		}
+		if err := writeBasicOffsetTable(w, offsets); err != nil {
 			return err
 		}
-		for _, frame := range image.Frames {
+		for _, frame := range frames {
 			if err := writeRawItem(w, frame.EncapsulatedData.Data); err != nil {
 				return err
 			}
@@ -534,44 +623,111 @@ func writePixelData(w dicomio.Writer, t tag.Tag, value Value, vr string, vl uint
 			return err
 		}
 	} else {
-		numFrames := len(image.Frames)
-		numPixels := len(image.Frames[0].NativeData.Data)
-		numValues := len(image.Frames[0].NativeData.Data[0])
-		// Total required buffer length in bytes:
-		length := numFrames * numPixels * numValues * image.Frames[0].NativeData.BitsPerSample / 8
-
-		buf := &bytes.Buffer{}
-		buf.Grow(length)
-		for frame := 0; frame < numFrames; frame++ {
-			for pixel := 0; pixel < numPixels; pixel++ {
-				for value := 0; value < numValues; value++ {
-					pixelValue := image.Frames[frame].NativeData.Data[pixel][value]
-					switch image.Frames[frame].NativeData.BitsPerSample {
-					case 8:
-						if err := binary.Write(buf, binary.LittleEndian, uint8(pixelValue)); err != nil {
-							return err
-						}
-					case 16:
-						if err := binary.Write(buf, binary.LittleEndian, uint16(pixelValue)); err != nil {
-							return err
-						}
-					case 32:
-						if err := binary.Write(buf, binary.LittleEndian, uint32(pixelValue)); err != nil {
-							return err
-						}
-					default:
-						return ErrorUnsupportedBitsPerSample
-					}
-				}
+		bo, _ := w.GetTransferSyntax()
+		var data []byte
+		for i := range frames {
+			samples, err := encodedSamples(&frames[i].NativeData)
+			if err != nil {
+				return err
 			}
+			if data == nil {
+				// Assume all frames have the same size.
+				data = make([]byte, 0, len(frames)*samples.Len()*samples.SampleSize())
+			}
+			data = samples.AppendBytes(data, bo)
 		}
-		if err := w.WriteBytes(buf.Bytes()); err != nil {
+		if err := w.WriteBytes(data); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// encodePixelData returns the PixelData element elem of ds with its native
+// frames (if any) encoded with the transfer syntax transferSyntaxUID, using the
+// codec.Codec registered for it. Encapsulated PixelData is returned unchanged.
+func encodePixelData(ds *Dataset, elem *Element, transferSyntaxUID string) (*Element, error) {
+	if elem.Value == nil || elem.Value.ValueType() != PixelData {
+		return elem, nil
+	}
+	image := MustGetPixelDataInfo(elem.Value)
+	if image.IsEncapsulated {
+		return elem, nil
+	}
+	frames, err := image.allFrames()
+	if err != nil {
+		return nil, err
+	}
+	// Native frames do not record the signedness and significant bits of
+	// their samples, which are taken from ds.
+	info := encapsulatedFrameInfo(ds, transferSyntaxUID)
+	encoded := PixelDataInfo{IsEncapsulated: true}
+	for i := range frames {
+		f, err := frame.Encode(&frames[i].NativeData, transferSyntaxUID, info.PixelRepresentation, info.BitsStored)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode frame %d: %w", i, err)
+		}
+		encoded.Frames = append(encoded.Frames, frame.Frame{Encapsulated: true, EncapsulatedData: *f})
+	}
+	return &Element{
+		Tag:                    elem.Tag,
+		ValueRepresentation:    elem.ValueRepresentation,
+		RawValueRepresentation: elem.RawValueRepresentation,
+		ValueLength:            tag.VLUndefinedLength,
+		Value:                  &pixelDataValue{PixelDataInfo: encoded},
+	}, nil
+}
+
+// encodedSamples returns the samples of f in the order they are encoded, based
+// on its PlanarConfiguration and PhotometricInterpretation, in a Buffer with
+// BitsPerSample bits per sample. It is the inverse of the layout done by
+// decodeNativeFrame.
+func encodedSamples(f *frame.NativeFrame) (frame.Buffer, error) {
+	numPixels := f.NumPixels()
+	numValues := f.NumSamplesPerPixel()
+	ybrFull422 := f.PhotometricInterpretation == "YBR_FULL_422" && numValues == 3 && numPixels%2 == 0
+	planar := f.PlanarConfiguration == 1 && numValues > 1
+	if f.Samples != nil && f.Samples.SampleSize()*8 == f.BitsPerSample && !ybrFull422 && !planar {
+		return f.Samples, nil
+	}
+
+	n := numPixels * numValues
+	if ybrFull422 {
+		n = numPixels * 2
+	}
+	samples, err := frame.NewBuffer(n, f.BitsPerSample)
+	if err != nil {
+		return nil, ErrorUnsupportedBitsPerSample
+	}
+	i := 0
+	switch {
+	case ybrFull422:
+		// Each pair of pixels is encoded as Y1 Y2 Cb Cr, with the
+		// chrominance of the first pixel.
+		for pixel := 0; pixel < numPixels; pixel += 2 {
+			for _, v := range []int{f.At(pixel, 0), f.At(pixel+1, 0), f.At(pixel, 1), f.At(pixel, 2)} {
+				samples.Set(i, v)
+				i++
+			}
+		}
+	case planar:
+		for value := 0; value < numValues; value++ {
+			for pixel := 0; pixel < numPixels; pixel++ {
+				samples.Set(i, f.At(pixel, value))
+				i++
+			}
+		}
+	default:
+		for pixel := 0; pixel < numPixels; pixel++ {
+			for value := 0; value < numValues; value++ {
+				samples.Set(i, f.At(pixel, value))
+				i++
+			}
+		}
+	}
+	return samples, nil
+}
+
 var sequenceDelimitationItem = &Element{
 	Tag:         tag.SequenceDelimitationItem,
 	ValueLength: 0, // This should be 00000000H in base32
@@ -629,25 +785,45 @@ func writeSequenceItem(w dicomio.Writer, t tag.Tag, values []*Element, vr string
 	return writeElement(w, sequenceItemDelimitationItem, opts)
 }
 
-func writeOtherWordString(w dicomio.Writer, data []byte) error {
-	if len(data)%2 != 0 {
-		return ErrorOWRequiresEvenVL
+// writeOtherWordString writes data, a stream of words held in little endian
+// byte order, in the byte order of w. The size of the words depends on vr (see
+// otherWordSize).
+func writeOtherWordString(w dicomio.Writer, data []byte, vr string) error {
+	size := otherWordSize(vr)
+	if len(data)%size != 0 {
+		if vr == vrraw.OtherWord {
+			return ErrorOWRequiresEvenVL
+		}
+		return fmt.Errorf("vr of %s requires a value length that is a multiple of %d, got: %d", vr, size, len(data))
 	}
 	bo, _ := w.GetTransferSyntax()
-	r, err := dicomio.NewReader(bufio.NewReader(bytes.NewBuffer(data)), bo, int64(len(data)))
+	if !isBigEndian(bo) {
+		return w.WriteBytes(data)
+	}
+	words := make([]byte, len(data))
+	copy(words, data)
+	convertWords(words, size, binary.LittleEndian, bo)
+	return w.WriteBytes(words)
+}
+
+// writeBulkData writes the value referenced by ref, converting any words it is
+// made up of (based on its VR) to the byte order of w.
+func writeBulkData(w dicomio.Writer, ref BulkDataReference) error {
+	data, err := ref.Resolve()
 	if err != nil {
 		return err
 	}
-	for i := 0; i < int(len(data)/2); i++ {
-		v, err := r.ReadUInt16()
-		if err != nil {
-			return err
-		}
-		if err := w.WriteUInt16(v); err != nil {
-			return err
-		}
+	if otherWordSize(ref.VR) < 2 {
+		return writeOtherByteString(w, data)
 	}
-	return nil
+	bo := ref.ByteOrder
+	if bo == nil {
+		bo = binary.LittleEndian
+	}
+	// writeOtherWordString takes words in little endian byte order, as held
+	// in Bytes values.
+	convertWords(data, otherWordSize(ref.VR), bo, binary.LittleEndian)
+	return writeOtherWordString(w, data, ref.VR)
 }
 
 func writeOtherByteString(w dicomio.Writer, data []byte) error {
@@ -2,7 +2,9 @@ package dicom
 
 import (
 	"bytes"
+	"compress/flate"
 	"encoding/binary"
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -15,7 +17,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/suyashkumar/dicom/pkg/codec"
 	"github.com/suyashkumar/dicom/pkg/dicomio"
+	"github.com/suyashkumar/dicom/pkg/jpegls"
 	"github.com/suyashkumar/dicom/pkg/tag"
 	"github.com/suyashkumar/dicom/pkg/uid"
 )
@@ -384,6 +388,97 @@ func TestWrite(t *testing.T) {
 			}},
 			expectedError: nil,
 		},
+		{
+			name: "native PixelData: [][]int Data",
+			dataset: Dataset{Elements: []*Element{
+				mustNewElement(tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+				mustNewElement(tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+				mustNewElement(tag.TransferSyntaxUID, []string{uid.ImplicitVRLittleEndian}),
+				mustNewElement(tag.Rows, []int{2}),
+				mustNewElement(tag.Columns, []int{2}),
+				mustNewElement(tag.BitsAllocated, []int{16}),
+				mustNewElement(tag.SamplesPerPixel, []int{2}),
+				mustNewElement(tag.PixelData, PixelDataInfo{
+					IsEncapsulated: false,
+					Frames: []frame.Frame{
+						{
+							Encapsulated: false,
+							NativeData: frame.NativeFrame{
+								BitsPerSample: 16,
+								Rows:          2,
+								Cols:          2,
+								Data:          [][]int{{1, 1}, {2, 2}, {3, 3}, {4, 0xFFFF}},
+							},
+						},
+					},
+				}),
+			}},
+			// Frames are read back into Samples, so only compare their Ints.
+			cmpOpts: []cmp.Option{
+				cmp.Transformer("Ints", func(f frame.NativeFrame) [][]int { return f.Ints() }),
+			},
+			expectedError: nil,
+		},
+		{
+			name: "native PixelData: planar RGB",
+			dataset: Dataset{Elements: []*Element{
+				mustNewElement(tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+				mustNewElement(tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+				mustNewElement(tag.TransferSyntaxUID, []string{uid.ImplicitVRLittleEndian}),
+				mustNewElement(tag.SamplesPerPixel, []int{3}),
+				mustNewElement(tag.PhotometricInterpretation, []string{"RGB"}),
+				mustNewElement(tag.PlanarConfiguration, []int{1}),
+				mustNewElement(tag.Rows, []int{1}),
+				mustNewElement(tag.Columns, []int{2}),
+				mustNewElement(tag.BitsAllocated, []int{8}),
+				mustNewElement(tag.PixelData, PixelDataInfo{
+					IsEncapsulated: false,
+					Frames: []frame.Frame{
+						{
+							Encapsulated: false,
+							NativeData: frame.NativeFrame{
+								BitsPerSample:             8,
+								Rows:                      1,
+								Cols:                      2,
+								Data:                      [][]int{{1, 2, 3}, {4, 5, 6}},
+								PhotometricInterpretation: "RGB",
+								PlanarConfiguration:       1,
+							},
+						},
+					},
+				}),
+			}},
+			expectedError: nil,
+		},
+		{
+			name: "native PixelData: YBR_FULL_422",
+			dataset: Dataset{Elements: []*Element{
+				mustNewElement(tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+				mustNewElement(tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+				mustNewElement(tag.TransferSyntaxUID, []string{uid.ImplicitVRLittleEndian}),
+				mustNewElement(tag.SamplesPerPixel, []int{3}),
+				mustNewElement(tag.PhotometricInterpretation, []string{"YBR_FULL_422"}),
+				mustNewElement(tag.Rows, []int{1}),
+				mustNewElement(tag.Columns, []int{4}),
+				mustNewElement(tag.BitsAllocated, []int{8}),
+				mustNewElement(tag.PixelData, PixelDataInfo{
+					IsEncapsulated: false,
+					Frames: []frame.Frame{
+						{
+							Encapsulated: false,
+							NativeData: frame.NativeFrame{
+								BitsPerSample:             8,
+								Rows:                      1,
+								Cols:                      4,
+								Data:                      [][]int{{10, 100, 200}, {20, 100, 200}, {30, 50, 60}, {40, 50, 60}},
+								PhotometricInterpretation: "YBR_FULL_422",
+							},
+						},
+					},
+				}),
+			}},
+			expectedError: nil,
+		},
 		{
 			name: "encapsulated PixelData",
 			dataset: Dataset{Elements: []*Element{
@@ -396,7 +491,7 @@ func TestWrite(t *testing.T) {
 					Frames: []frame.Frame{
 						{
 							Encapsulated:     true,
-							EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}},
+							EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}, TransferSyntaxUID: uid.ImplicitVRLittleEndian, BitsAllocated: 8},
 						},
 					},
 				})),
@@ -417,11 +512,11 @@ func TestWrite(t *testing.T) {
 					Frames: []frame.Frame{
 						{
 							Encapsulated:     true,
-							EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}},
+							EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4}, TransferSyntaxUID: uid.ImplicitVRLittleEndian, BitsAllocated: 8},
 						},
 						{
 							Encapsulated:     true,
-							EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 8}},
+							EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 8}, TransferSyntaxUID: uid.ImplicitVRLittleEndian, BitsAllocated: 8},
 						},
 					},
 				})),
@@ -430,6 +525,96 @@ func TestWrite(t *testing.T) {
 			}},
 			expectedError: nil,
 		},
+		{
+			name: "explicit VR big endian",
+			dataset: Dataset{Elements: []*Element{
+				mustNewElement(tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+				mustNewElement(tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+				mustNewElement(tag.TransferSyntaxUID, []string{uid.ExplicitVRBigEndian}),
+				mustNewElement(tag.PatientName, []string{"Bob", "Jones"}),
+				mustNewElement(tag.Rows, []int{2}),
+				mustNewElement(tag.Columns, []int{2}),
+				mustNewElement(tag.BitsAllocated, []int{16}),
+				mustNewElement(tag.NumberOfFrames, []string{"2"}),
+				mustNewElement(tag.SamplesPerPixel, []int{1}),
+				mustNewElement(tag.FloatingPointValue, []float64{128.10}),
+				mustNewElement(tag.DimensionIndexPointer, []int{32, 36950}),
+				mustNewElement(tag.RedPaletteColorLookupTableData, []byte{0x1, 0x2, 0x3, 0x4}),
+				mustNewElement(tag.VectorGridData, []float64{1.5, -2.25}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1001}, vrraw.OtherDouble, []float64{1.1, -2.2}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1002}, vrraw.OtherLong, []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1003}, vrraw.OtherVeryLong, []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1004}, vrraw.SignedVeryLong, []int64{-1, 1 << 40}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1005}, vrraw.UnsignedVeryLong, []uint64{1, 1<<64 - 1}),
+				mustNewElement(tag.PixelData, PixelDataInfo{
+					IsEncapsulated: false,
+					Frames: []frame.Frame{
+						{
+							Encapsulated: false,
+							NativeData: frame.NativeFrame{
+								BitsPerSample: 16,
+								Rows:          2,
+								Cols:          2,
+								Data:          [][]int{{1}, {2}, {3}, {0x1234}},
+							},
+						},
+						{
+							Encapsulated: false,
+							NativeData: frame.NativeFrame{
+								BitsPerSample: 16,
+								Rows:          2,
+								Cols:          2,
+								Data:          [][]int{{5}, {6}, {7}, {0xFFFE}},
+							},
+						},
+					},
+				}),
+			}},
+			expectedError: nil,
+		},
+		{
+			name: "newer VRs",
+			dataset: Dataset{Elements: []*Element{
+				mustNewElement(tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+				mustNewElement(tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+				mustNewElement(tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}),
+				mustNewElement(tag.VectorGridData, []float64{1.5, -2.25}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1001}, vrraw.OtherDouble, []float64{1.1, -2.2}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1002}, vrraw.OtherLong, []byte{0x1, 0x2, 0x3, 0x4}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1003}, vrraw.OtherVeryLong, []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1004}, vrraw.SignedVeryLong, []int64{-1, 1 << 40}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1005}, vrraw.UnsignedVeryLong, []uint64{1, 1<<64 - 1}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1006}, vrraw.UnlimitedCharacters, []string{"Bob", "Jones"}),
+				mustNewPrivateElement(tag.Tag{Group: 0x0009, Element: 0x1007}, vrraw.UniversalResourceIdentifier, []string{"https://example.com/a\\b"}),
+			}},
+			expectedError: nil,
+		},
+		{
+			name: "deflated explicit VR little endian",
+			dataset: Dataset{Elements: []*Element{
+				mustNewElement(tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+				mustNewElement(tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+				mustNewElement(tag.TransferSyntaxUID, []string{uid.DeflatedExplicitVRLittleEndian}),
+				mustNewElement(tag.PatientName, []string{"Bob", "Jones"}),
+				mustNewElement(tag.Rows, []int{128}),
+				mustNewElement(tag.FloatingPointValue, []float64{128.10}),
+				mustNewElement(tag.DimensionIndexPointer, []int{32, 36950}),
+				mustNewElement(tag.RedPaletteColorLookupTableData, []byte{0x1, 0x2, 0x3, 0x4}),
+			}},
+			expectedError: nil,
+		},
+		{
+			name: "deflated explicit VR little endian, best compression",
+			dataset: Dataset{Elements: []*Element{
+				mustNewElement(tag.MediaStorageSOPClassUID, []string{"1.2.840.10008.5.1.4.1.1.1.2"}),
+				mustNewElement(tag.MediaStorageSOPInstanceUID, []string{"1.2.3.4.5.6.7"}),
+				mustNewElement(tag.TransferSyntaxUID, []string{uid.DeflatedExplicitVRLittleEndian}),
+				mustNewElement(tag.PatientName, []string{"Bob", "Jones"}),
+				mustNewElement(tag.Rows, []int{128}),
+			}},
+			opts:          []WriteOption{DeflateLevel(flate.BestCompression)},
+			expectedError: nil,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -629,6 +814,7 @@ func TestWriteOtherWord(t *testing.T) {
 		name         string
 		value        []byte
 		vr           string
+		bo           binary.ByteOrder
 		expectedData []byte
 		expectedErr  error
 	}{
@@ -636,6 +822,7 @@ func TestWriteOtherWord(t *testing.T) {
 			name:         "OtherWord",
 			value:        []byte{0x1, 0x2, 0x3, 0x4},
 			vr:           "OW",
+			bo:           binary.LittleEndian,
 			expectedData: []byte{0x1, 0x2, 0x3, 0x4},
 			expectedErr:  nil,
 		},
@@ -643,27 +830,339 @@ func TestWriteOtherWord(t *testing.T) {
 			name:         "OtherBytes",
 			value:        []byte{0x1, 0x2, 0x3, 0x4},
 			vr:           "OB",
+			bo:           binary.LittleEndian,
+			expectedData: []byte{0x1, 0x2, 0x3, 0x4},
+			expectedErr:  nil,
+		},
+		{
+			name:         "OtherWord: big endian",
+			value:        []byte{0x1, 0x2, 0x3, 0x4},
+			vr:           "OW",
+			bo:           binary.BigEndian,
+			expectedData: []byte{0x2, 0x1, 0x4, 0x3},
+			expectedErr:  nil,
+		},
+		{
+			name:         "OtherBytes: big endian",
+			value:        []byte{0x1, 0x2, 0x3, 0x4},
+			vr:           "OB",
+			bo:           binary.BigEndian,
 			expectedData: []byte{0x1, 0x2, 0x3, 0x4},
 			expectedErr:  nil,
 		},
+		{
+			name:         "OtherLong: big endian",
+			value:        []byte{0x1, 0x2, 0x3, 0x4},
+			vr:           "OL",
+			bo:           binary.BigEndian,
+			expectedData: []byte{0x4, 0x3, 0x2, 0x1},
+			expectedErr:  nil,
+		},
+		{
+			name:         "OtherVeryLong: big endian",
+			value:        []byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0x8},
+			vr:           "OV",
+			bo:           binary.BigEndian,
+			expectedData: []byte{0x8, 0x7, 0x6, 0x5, 0x4, 0x3, 0x2, 0x1},
+			expectedErr:  nil,
+		},
+		{
+			name:        "OtherWord: odd length",
+			value:       []byte{0x1, 0x2, 0x3},
+			vr:          "OW",
+			bo:          binary.BigEndian,
+			expectedErr: ErrorOWRequiresEvenVL,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			buf := bytes.Buffer{}
-			w := dicomio.NewWriter(&buf, binary.LittleEndian, false)
+			w := dicomio.NewWriter(&buf, tc.bo, false)
 			err := writeBytes(w, tc.value, tc.vr)
 			if err != tc.expectedErr {
 				t.Errorf("writeBytes(%v, %s) returned unexpected err. got: %v, want: %v", tc.value, tc.vr, err, tc.expectedErr)
 			}
-			if diff := cmp.Diff(tc.expectedData, buf.Bytes()); diff != "" {
+			if diff := cmp.Diff(tc.expectedData, buf.Bytes(), cmpopts.EquateEmpty()); diff != "" {
 				t.Errorf("writeBytes(%v, %s) wrote unexpected data. diff: %s", tc.value, tc.vr, diff)
 				t.Errorf("% x", buf.Bytes())
 			}
+			if tc.expectedErr == nil {
+				// The original value must not be modified.
+				if diff := cmp.Diff(tc.value[:4], []byte{0x1, 0x2, 0x3, 0x4}); diff != "" {
+					t.Errorf("writeBytes(%v, %s) modified its input. diff: %s", tc.value, tc.vr, diff)
+				}
+			}
 		})
 	}
 
 }
 
+func TestWritePixelData_bigEndian(t *testing.T) {
+	value, err := NewValue(PixelDataInfo{
+		Frames: []frame.Frame{
+			{
+				NativeData: frame.NativeFrame{
+					BitsPerSample:   16,
+					Rows:            1,
+					Cols:            2,
+					Samples:         frame.Uint16Buffer{0x0102, 0x0304},
+					SamplesPerPixel: 1,
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewValue unexpected error: %v", err)
+	}
+	buf := bytes.Buffer{}
+	w := dicomio.NewWriter(&buf, binary.BigEndian, false)
+	if err := writePixelData(w, tag.PixelData, value, "OW", 4); err != nil {
+		t.Fatalf("writePixelData unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]byte{0x1, 0x2, 0x3, 0x4}, buf.Bytes()); diff != "" {
+		t.Errorf("writePixelData wrote unexpected data. diff: %s", diff)
+	}
+}
+
+func TestWrite_editedData(t *testing.T) {
+	ds, err := ParseFile("./testdata/1.dcm")
+	if err != nil {
+		t.Fatalf("ParseFile(1.dcm) unexpected error: %v", err)
+	}
+	pixelData, err := ds.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	native := &MustGetPixelDataInfo(pixelData.Value).Frames[0].NativeData
+	native.Data[0][0] = 1234
+
+	buf := bytes.Buffer{}
+	if err := Write(&buf, ds, SkipVRVerification()); err != nil {
+		t.Fatalf("Write unexpected error: %v", err)
+	}
+	got, err := Parse(&buf, Limit(int64(buf.Len())))
+	if err != nil {
+		t.Fatalf("Parse unexpected error: %v", err)
+	}
+	pixelData, err = got.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	if v := MustGetPixelDataInfo(pixelData.Value).Frames[0].NativeData.At(0, 0); v != 1234 {
+		t.Errorf("sample 0 after editing Data and writing. got: %d, want: %d", v, 1234)
+	}
+}
+
+func TestWrite_deflated(t *testing.T) {
+	ds := Dataset{Elements: []*Element{
+		mustNewElement(tag.TransferSyntaxUID, []string{uid.DeflatedExplicitVRLittleEndian}),
+		mustNewElement(tag.PatientName, []string{"Bob", "Jones"}),
+	}}
+	buf := bytes.Buffer{}
+	if err := Write(&buf, ds); err != nil {
+		t.Fatalf("Write(%v) unexpected error: %v", ds, err)
+	}
+
+	// Everything after the metadata should be raw DEFLATE compressed.
+	data := buf.Bytes()
+	metaEnd := 144 + int(binary.LittleEndian.Uint32(data[140:144]))
+	inflated, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(data[metaEnd:])))
+	if err != nil {
+		t.Fatalf("unable to inflate written dataset: %v", err)
+	}
+
+	want := bytes.Buffer{}
+	w := dicomio.NewWriter(&want, binary.LittleEndian, false)
+	if err := writeElement(w, ds.Elements[1], writeOptSet{}); err != nil {
+		t.Fatalf("writeElement(%v) unexpected error: %v", ds.Elements[1], err)
+	}
+	if diff := cmp.Diff(want.Bytes(), inflated); diff != "" {
+		t.Errorf("Write(%v) wrote unexpected inflated data. diff: %s", ds, diff)
+	}
+}
+
+func TestWrite_deflatedError(t *testing.T) {
+	ints, err := NewValue([]int{1})
+	if err != nil {
+		t.Fatalf("NewValue unexpected error: %v", err)
+	}
+	ds := Dataset{Elements: []*Element{
+		mustNewElement(tag.TransferSyntaxUID, []string{uid.DeflatedExplicitVRLittleEndian}),
+		mustNewElement(tag.PatientID, []string{"1234"}),
+		{Tag: tag.PatientName, RawValueRepresentation: "PN", Value: ints},
+	}}
+	buf := bytes.Buffer{}
+	if err := Write(&buf, ds); err == nil {
+		t.Fatalf("Write(%v) of a PN element holding ints unexpectedly succeeded", ds)
+	}
+
+	// The deflate stream is closed, so the elements written before the error
+	// can be inflated.
+	data := buf.Bytes()
+	metaEnd := 144 + int(binary.LittleEndian.Uint32(data[140:144]))
+	inflated, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(data[metaEnd:])))
+	if err != nil {
+		t.Fatalf("unable to inflate written dataset: %v", err)
+	}
+	want := bytes.Buffer{}
+	w := dicomio.NewWriter(&want, binary.LittleEndian, false)
+	if err := writeElement(w, ds.Elements[1], writeOptSet{}); err != nil {
+		t.Fatalf("writeElement(%v) unexpected error: %v", ds.Elements[1], err)
+	}
+	if diff := cmp.Diff(want.Bytes(), inflated); diff != "" {
+		t.Errorf("Write(%v) wrote unexpected inflated data. diff: %s", ds, diff)
+	}
+}
+
+func TestWrite_basicOffsetTable(t *testing.T) {
+	ds := Dataset{Elements: []*Element{
+		mustNewElement(tag.TransferSyntaxUID, []string{uid.ExplicitVRLittleEndian}),
+		setUndefinedLength(mustNewElement(tag.PixelData, PixelDataInfo{
+			IsEncapsulated: true,
+			// Offsets that do not match the written frames are recomputed.
+			Offsets: []uint32{0, 0},
+			Frames: []frame.Frame{
+				{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{1, 2, 3, 4, 5, 6}}},
+				{Encapsulated: true, EncapsulatedData: frame.EncapsulatedFrame{Data: []byte{7, 8}}},
+			},
+		})),
+	}}
+	buf := bytes.Buffer{}
+	if err := Write(&buf, ds); err != nil {
+		t.Fatalf("Write(%v) unexpected error: %v", ds, err)
+	}
+
+	got, err := Parse(&buf, Limit(int64(buf.Len())))
+	if err != nil {
+		t.Fatalf("Parse unexpected error: %v", err)
+	}
+	pixelData, err := got.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	if diff := cmp.Diff([]uint32{0, 14}, MustGetPixelDataInfo(pixelData.Value).Offsets); diff != "" {
+		t.Errorf("Write(%v) wrote unexpected Basic Offset Table. diff: %s", ds, diff)
+	}
+}
+
+func TestWrite_rleLossless(t *testing.T) {
+	frames := []frame.Frame{
+		{NativeData: frame.NativeFrame{
+			BitsPerSample: 16, Rows: 2, Cols: 3, SamplesPerPixel: 1,
+			Samples: frame.Int16Buffer{-1, -1, -1, 0, 1, 2},
+		}},
+		{NativeData: frame.NativeFrame{
+			BitsPerSample: 16, Rows: 2, Cols: 3, SamplesPerPixel: 1,
+			Samples: frame.Int16Buffer{-1000, 1000, 7, 7, 7, 7},
+		}},
+	}
+	ds := Dataset{Elements: []*Element{
+		mustNewElement(tag.TransferSyntaxUID, []string{uid.RLELossless}),
+		mustNewElement(tag.Rows, []int{2}),
+		mustNewElement(tag.Columns, []int{3}),
+		mustNewElement(tag.NumberOfFrames, []string{"2"}),
+		mustNewElement(tag.SamplesPerPixel, []int{1}),
+		mustNewElement(tag.BitsAllocated, []int{16}),
+		mustNewElement(tag.PixelRepresentation, []int{1}),
+		mustNewElement(tag.PixelData, PixelDataInfo{Frames: frames}),
+	}}
+	buf := bytes.Buffer{}
+	if err := Write(&buf, ds); err != nil {
+		t.Fatalf("Write(%v) unexpected error: %v", ds, err)
+	}
+
+	got, err := Parse(&buf, Limit(int64(buf.Len())))
+	if err != nil {
+		t.Fatalf("Parse unexpected error: %v", err)
+	}
+	pixelData, err := got.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	info := MustGetPixelDataInfo(pixelData.Value)
+	if !info.IsEncapsulated || len(info.Frames) != len(frames) {
+		t.Fatalf("Write(%v) wrote unexpected PixelData: got %d frames, encapsulated: %v", ds, len(info.Frames), info.IsEncapsulated)
+	}
+	for i := range frames {
+		native, err := info.Frames[i].GetNativeFrame()
+		if err != nil {
+			t.Fatalf("GetNativeFrame(%d) unexpected error: %v", i, err)
+		}
+		// Decoded samples are held unsigned, so they are compared as int16.
+		if diff := cmp.Diff(&frames[i].NativeData, native, cmpopts.IgnoreFields(frame.NativeFrame{}, "Samples")); diff != "" {
+			t.Errorf("GetNativeFrame(%d) unexpected diff (-want +got):\n%s", i, diff)
+		}
+		if diff := cmp.Diff(frames[i].NativeData.Ints(), native.Ints(), cmp.Transformer("int16", func(v int) int16 { return int16(v) })); diff != "" {
+			t.Errorf("GetNativeFrame(%d) unexpected samples (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestWrite_jpeglsBitsStored(t *testing.T) {
+	// The frame does not record that its samples are signed 12 bit samples,
+	// which is taken from the Dataset.
+	ds := Dataset{Elements: []*Element{
+		mustNewElement(tag.TransferSyntaxUID, []string{uid.JPEGLSLossless}),
+		mustNewElement(tag.Rows, []int{2}),
+		mustNewElement(tag.Columns, []int{2}),
+		mustNewElement(tag.SamplesPerPixel, []int{1}),
+		mustNewElement(tag.BitsAllocated, []int{16}),
+		mustNewElement(tag.BitsStored, []int{12}),
+		mustNewElement(tag.PixelRepresentation, []int{1}),
+		mustNewElement(tag.PixelData, PixelDataInfo{Frames: []frame.Frame{{NativeData: frame.NativeFrame{
+			BitsPerSample: 16, Rows: 2, Cols: 2,
+			Data: [][]int{{-2048}, {2047}, {-1}, {0}},
+		}}}}),
+	}}
+	buf := bytes.Buffer{}
+	if err := Write(&buf, ds); err != nil {
+		t.Fatalf("Write(%v) unexpected error: %v", ds, err)
+	}
+
+	got, err := Parse(&buf, Limit(int64(buf.Len())))
+	if err != nil {
+		t.Fatalf("Parse unexpected error: %v", err)
+	}
+	pixelData, err := got.FindElementByTag(tag.PixelData)
+	if err != nil {
+		t.Fatalf("unable to find PixelData: %v", err)
+	}
+	f := MustGetPixelDataInfo(pixelData.Value).Frames[0]
+	img, err := jpegls.Decode(f.EncapsulatedData.Data)
+	if err != nil {
+		t.Fatalf("jpegls.Decode unexpected error: %v", err)
+	}
+	if img.Precision != 12 {
+		t.Errorf("Write encoded samples with unexpected precision. got: %d, want: %d", img.Precision, 12)
+	}
+	native, err := f.GetNativeFrame()
+	if err != nil {
+		t.Fatalf("GetNativeFrame unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(frame.Uint16Buffer{0xF800, 0x07FF, 0xFFFF, 0}, native.Samples); diff != "" {
+		t.Errorf("GetNativeFrame unexpected samples (-want +got):\n%s", diff)
+	}
+}
+
+func TestWrite_nearLossless(t *testing.T) {
+	// Native PixelData is not lossily compressed by Write, which requires
+	// Transcode with AllowLossy.
+	ds := Dataset{Elements: []*Element{
+		mustNewElement(tag.TransferSyntaxUID, []string{uid.JPEGLSNearLossless}),
+		mustNewElement(tag.Rows, []int{1}),
+		mustNewElement(tag.Columns, []int{2}),
+		mustNewElement(tag.SamplesPerPixel, []int{1}),
+		mustNewElement(tag.BitsAllocated, []int{8}),
+		mustNewElement(tag.PixelData, PixelDataInfo{Frames: []frame.Frame{{NativeData: frame.NativeFrame{
+			BitsPerSample: 8, Rows: 1, Cols: 2, Samples: frame.Uint8Buffer{1, 2}, SamplesPerPixel: 1,
+		}}}}),
+	}}
+	buf := bytes.Buffer{}
+	if err := Write(&buf, ds); !errors.Is(err, codec.ErrorEncodeUnsupported) {
+		t.Errorf("Write(%v) unexpected error. got: %v, want: %v", ds, err, codec.ErrorEncodeUnsupported)
+	}
+}
+
 func setUndefinedLength(e *Element) *Element {
 	e.ValueLength = tag.VLUndefinedLength
 	return e